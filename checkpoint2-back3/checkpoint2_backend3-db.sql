@@ -1,11 +1,24 @@
 CREATE SCHEMA `checkpoint2`;
 
+CREATE TABLE `checkpoint2`.`clinic` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `name` VARCHAR(100) NOT NULL,
+    `address` VARCHAR(255) NOT NULL DEFAULT '',
+    `time_zone` VARCHAR(64) NOT NULL DEFAULT 'America/Sao_Paulo',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_clinic_name` (`name`)
+);
+
 CREATE TABLE `checkpoint2`.`dentist` (
 	`id` INT NOT NULL AUTO_INCREMENT,
     `surname` VARCHAR(100) NOT NULL,
     `name` VARCHAR(100)  NOT NULL,
     `registration` VARCHAR(100)  NOT NULL,
-    PRIMARY KEY (`id`)
+    `calendar_token` VARCHAR(64) NULL,
+    `time_zone` VARCHAR(64) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_dentist_registration` (`registration`),
+    UNIQUE INDEX `idx_dentist_calendar_token` (`calendar_token`)
 );
     
 CREATE TABLE `checkpoint2`.`patient` (
@@ -13,28 +26,348 @@ CREATE TABLE `checkpoint2`.`patient` (
     `surname` VARCHAR(100) NOT NULL,
     `name` VARCHAR(100)  NOT NULL,
     `rg` VARCHAR(100)  NOT NULL,
+    `cpf` CHAR(11) NULL,
     `registration_date` VARCHAR(100)  NOT NULL,
-    PRIMARY KEY (`id`)
+    `calendar_token` VARCHAR(64) NULL,
+    `late_cancellations` INT NOT NULL DEFAULT 0,
+    `no_shows` INT NOT NULL DEFAULT 0,
+    `restricted` BOOLEAN NOT NULL DEFAULT FALSE,
+    `birth_date` DATE NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_patient_rg` (`rg`),
+    UNIQUE INDEX `idx_patient_calendar_token` (`calendar_token`),
+    UNIQUE INDEX `idx_patient_cpf` (`cpf`)
+);
+
+CREATE TABLE `checkpoint2`.`patient_email` (
+    `patient_id` INT NOT NULL,
+    `email` VARCHAR(254) NOT NULL,
+    PRIMARY KEY (`patient_id`),
+		FOREIGN KEY (`patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`patient_phone` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `patient_id` INT NOT NULL,
+    `type` VARCHAR(10) NOT NULL,
+    `number` VARCHAR(11) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_patient_phone_patient` (`patient_id`),
+		FOREIGN KEY (`patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`patient_address` (
+    `patient_id` INT NOT NULL,
+    `street` VARCHAR(150) NOT NULL,
+    `number` VARCHAR(20) NOT NULL,
+    `complement` VARCHAR(100) NULL,
+    `district` VARCHAR(100) NOT NULL,
+    `city` VARCHAR(100) NOT NULL,
+    `state` CHAR(2) NOT NULL,
+    `cep` CHAR(9) NOT NULL,
+    PRIMARY KEY (`patient_id`),
+		FOREIGN KEY (`patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`patient_emergency_contact` (
+    `patient_id` INT NOT NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `relationship` VARCHAR(50) NULL,
+    `phone` VARCHAR(11) NOT NULL,
+    PRIMARY KEY (`patient_id`),
+		FOREIGN KEY (`patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`patient_guardian` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `patient_id` INT NOT NULL,
+    `guardian_patient_id` INT NULL,
+    `name` VARCHAR(100) NULL,
+    `relationship` VARCHAR(50) NOT NULL,
+    `phone` VARCHAR(11) NULL,
+    `email` VARCHAR(254) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_patient_guardian_patient` (`patient_id`),
+    INDEX `idx_patient_guardian_guardian` (`guardian_patient_id`),
+		FOREIGN KEY (`patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`)
+        ON DELETE CASCADE,
+        FOREIGN KEY (`guardian_patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`)
+        ON DELETE RESTRICT
+);
+
+CREATE TABLE `checkpoint2`.`dentist_clinic` (
+    `dentist_id` INT NOT NULL,
+    `clinic_id` INT NOT NULL,
+    PRIMARY KEY (`dentist_id`, `clinic_id`),
+    INDEX `idx_dentist_clinic_clinic` (`clinic_id`),
+		FOREIGN KEY (`dentist_id`)
+        REFERENCES `checkpoint2`.`dentist` (`id`)
+        ON DELETE CASCADE,
+        FOREIGN KEY (`clinic_id`)
+        REFERENCES `checkpoint2`.`clinic` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`room` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `name` VARCHAR(100) NOT NULL,
+    `equipment` VARCHAR(255) NOT NULL DEFAULT '',
+    `clinic_id` INT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_room_name` (`name`),
+		FOREIGN KEY (`clinic_id`)
+        REFERENCES `checkpoint2`.`clinic` (`id`)
+        ON DELETE SET NULL
+);
+
+CREATE TABLE `checkpoint2`.`appointment_type` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `name` VARCHAR(100) NOT NULL,
+    `duration_minutes` INT NOT NULL,
+    `color` VARCHAR(7) NOT NULL DEFAULT '',
+    `price` DECIMAL(10,2) NOT NULL DEFAULT 0,
+    `equipment` VARCHAR(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_appointment_type_name` (`name`)
+);
+
+CREATE TABLE `checkpoint2`.`closure` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `clinic_id` INT NULL,
+    `reason` VARCHAR(100) NOT NULL,
+    `from_date` DATE NOT NULL,
+    `to_date` DATE NOT NULL,
+    `recurrence` VARCHAR(20) NOT NULL DEFAULT '',
+    PRIMARY KEY (`id`),
+    INDEX `idx_closure_from` (`from_date`),
+		FOREIGN KEY (`clinic_id`)
+        REFERENCES `checkpoint2`.`clinic` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`appointment_series` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `patient_id` INT NOT NULL,
+    `dentist_id` INT NOT NULL,
+    `rule` VARCHAR(255) NOT NULL,
+    `description` VARCHAR(100) NOT NULL,
+    PRIMARY KEY (`id`),
+		FOREIGN KEY (`patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`),
+        FOREIGN KEY (`dentist_id`)
+        REFERENCES `checkpoint2`.`dentist` (`id`)
 );
 
 CREATE TABLE `checkpoint2`.`appointment` (
 	`id` INT NOT NULL AUTO_INCREMENT,
     `patient_id` INT NOT NULL,
     `dentist_id` INT  NOT NULL,
-    `date` VARCHAR(100)  NOT NULL,
+    `start_time` DATETIME NOT NULL,
+    `end_time` DATETIME NOT NULL,
     `description` VARCHAR(100)  NOT NULL,
+    `status` VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    `series_id` INT NULL,
+    `room_id` INT NULL,
+    `clinic_id` INT NULL,
+    `type_id` INT NULL,
+    `override_by` VARCHAR(100) NULL,
+    `cancellation_reason` VARCHAR(255) NULL,
+    `cancelled_by` VARCHAR(100) NULL,
+    `cancelled_at` DATETIME NULL,
+    `late_cancellation` BOOLEAN NOT NULL DEFAULT FALSE,
+    `sequence` INT NOT NULL DEFAULT 0,
+    `updated_at` DATETIME NOT NULL DEFAULT (UTC_TIMESTAMP()),
     PRIMARY KEY (`id`),
+    INDEX `idx_appointment_dentist_start` (`dentist_id`, `start_time`),
+    INDEX `idx_appointment_patient_start` (`patient_id`, `start_time`),
+    INDEX `idx_appointment_start` (`start_time`),
+    INDEX `idx_appointment_status` (`status`),
+    INDEX `idx_appointment_room_start` (`room_id`, `start_time`),
+    INDEX `idx_appointment_clinic_start` (`clinic_id`, `start_time`),
+    INDEX `idx_appointment_type_start` (`type_id`, `start_time`),
 		FOREIGN KEY (`patient_id`)
         REFERENCES `checkpoint2`.`patient` (`id`),
         FOREIGN KEY (`dentist_id`)
+        REFERENCES `checkpoint2`.`dentist` (`id`),
+        FOREIGN KEY (`series_id`)
+        REFERENCES `checkpoint2`.`appointment_series` (`id`),
+        FOREIGN KEY (`room_id`)
+        REFERENCES `checkpoint2`.`room` (`id`)
+        ON DELETE SET NULL,
+        FOREIGN KEY (`clinic_id`)
+        REFERENCES `checkpoint2`.`clinic` (`id`)
+        ON DELETE SET NULL,
+        FOREIGN KEY (`type_id`)
+        REFERENCES `checkpoint2`.`appointment_type` (`id`)
+        ON DELETE SET NULL
+);
+
+CREATE TABLE `checkpoint2`.`dentist_working_hours` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `dentist_id` INT NOT NULL,
+    `weekday` TINYINT NOT NULL,
+    `start_time` TIME NOT NULL,
+    `end_time` TIME NOT NULL,
+    PRIMARY KEY (`id`),
+		FOREIGN KEY (`dentist_id`)
+        REFERENCES `checkpoint2`.`dentist` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`dentist_time_off` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `dentist_id` INT NOT NULL,
+    `start_time` DATETIME NOT NULL,
+    `end_time` DATETIME NOT NULL,
+    `reason` VARCHAR(100) NOT NULL,
+    PRIMARY KEY (`id`),
+		FOREIGN KEY (`dentist_id`)
+        REFERENCES `checkpoint2`.`dentist` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`appointment_status_history` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `appointment_id` INT NOT NULL,
+    `from_status` VARCHAR(20) NOT NULL,
+    `to_status` VARCHAR(20) NOT NULL,
+    `changed_by` VARCHAR(100) NOT NULL,
+    `changed_at` DATETIME NOT NULL,
+    PRIMARY KEY (`id`),
+		FOREIGN KEY (`appointment_id`)
+        REFERENCES `checkpoint2`.`appointment` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`appointment_history` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `appointment_id` INT NOT NULL,
+    `kind` VARCHAR(20) NOT NULL,
+    `dentist_id` INT NOT NULL,
+    `start_time` DATETIME NOT NULL,
+    `end_time` DATETIME NOT NULL,
+    `description` VARCHAR(100) NOT NULL,
+    `changed_by` VARCHAR(100) NOT NULL,
+    `reason` VARCHAR(255) NOT NULL,
+    `changed_at` DATETIME NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX (`kind`, `changed_at`),
+		FOREIGN KEY (`appointment_id`)
+        REFERENCES `checkpoint2`.`appointment` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`waitlist_entry` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `patient_id` INT NOT NULL,
+    `dentist_id` INT NULL,
+    `clinic_id` INT NULL,
+    `from_date` DATE NOT NULL,
+    `to_date` DATE NOT NULL,
+    `earliest_time` TIME NULL,
+    `latest_time` TIME NULL,
+    `status` VARCHAR(20) NOT NULL,
+    `created_at` DATETIME NOT NULL,
+    PRIMARY KEY (`id`),
+		FOREIGN KEY (`patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`)
+        ON DELETE CASCADE,
+        FOREIGN KEY (`dentist_id`)
         REFERENCES `checkpoint2`.`dentist` (`id`)
+        ON DELETE CASCADE,
+        FOREIGN KEY (`clinic_id`)
+        REFERENCES `checkpoint2`.`clinic` (`id`)
+        ON DELETE SET NULL
+);
+
+CREATE TABLE `checkpoint2`.`waitlist_hold` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `entry_id` INT NOT NULL,
+    `dentist_id` INT NOT NULL,
+    `clinic_id` INT NULL,
+    `start_time` DATETIME NOT NULL,
+    `end_time` DATETIME NOT NULL,
+    `expires_at` DATETIME NOT NULL,
+    `status` VARCHAR(20) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX (`dentist_id`, `start_time`),
+		FOREIGN KEY (`entry_id`)
+        REFERENCES `checkpoint2`.`waitlist_entry` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`appointment_reminder` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `appointment_id` INT NOT NULL,
+    `appointment_start` DATETIME NOT NULL,
+    `offset_minutes` INT NOT NULL,
+    `channel` VARCHAR(20) NOT NULL,
+    `token` VARCHAR(64) NOT NULL,
+    `status` VARCHAR(20) NOT NULL,
+    `error` VARCHAR(255) NULL,
+    `created_at` DATETIME NOT NULL,
+    `claimed_at` DATETIME NOT NULL,
+    `sent_at` DATETIME NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX (`appointment_id`, `appointment_start`, `offset_minutes`),
+    UNIQUE INDEX (`token`),
+		FOREIGN KEY (`appointment_id`)
+        REFERENCES `checkpoint2`.`appointment` (`id`)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE `checkpoint2`.`odontogram_change` (
+	`id` INT NOT NULL AUTO_INCREMENT,
+    `patient_id` INT NOT NULL,
+    `appointment_id` INT NOT NULL,
+    `tooth` TINYINT NOT NULL,
+    `surface` VARCHAR(10) NULL,
+    `tooth_condition` VARCHAR(20) NOT NULL,
+    `notes` VARCHAR(255) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_odontogram_change_patient` (`patient_id`),
+		FOREIGN KEY (`patient_id`)
+        REFERENCES `checkpoint2`.`patient` (`id`)
+        ON DELETE CASCADE,
+        FOREIGN KEY (`appointment_id`)
+        REFERENCES `checkpoint2`.`appointment` (`id`)
+        ON DELETE CASCADE
 );
 
+INSERT INTO `checkpoint2`.`clinic` (`name`, `address`, `time_zone`)
+VALUES ('Centro', '', 'America/Sao_Paulo');
+
 INSERT INTO `checkpoint2`.`dentist` (`surname`, `name`, `registration`)
 VALUES ('Renata', 'da Silva Leal', '001');
 
+INSERT INTO `checkpoint2`.`dentist_clinic` (`dentist_id`, `clinic_id`)
+VALUES (1, 1);
+
+INSERT INTO `checkpoint2`.`dentist_working_hours` (`dentist_id`, `weekday`, `start_time`, `end_time`)
+VALUES (1, 1, '08:00', '12:00'), (1, 1, '14:00', '18:00'),
+       (1, 2, '08:00', '12:00'), (1, 2, '14:00', '18:00'),
+       (1, 3, '08:00', '12:00'), (1, 3, '14:00', '18:00'),
+       (1, 4, '08:00', '12:00'), (1, 4, '14:00', '18:00'),
+       (1, 5, '08:00', '12:00'), (1, 5, '14:00', '18:00');
+
 INSERT INTO `checkpoint2`.`patient` (`surname`, `name`, `rg`, `registration_date`)
 VALUES ('Carolina', 'Haka', '36070666', '15/12/2022');
 
-INSERT INTO `checkpoint2`.`appointment` (`patient_id`, `dentist_id`, `date`, `description`)
-VALUES (1, 1, '13/12/2022', 'Limpeza bucal');
\ No newline at end of file
+INSERT INTO `checkpoint2`.`appointment_type` (`name`, `duration_minutes`, `color`, `price`, `equipment`)
+VALUES ('Avaliação', 30, '#4caf50', 100.00, ''),
+       ('Limpeza', 30, '#2196f3', 150.00, ''),
+       ('Extração', 60, '#f44336', 300.00, 'surgery'),
+       ('Tratamento de canal', 90, '#9c27b0', 800.00, 'x-ray');
+
+INSERT INTO `checkpoint2`.`appointment` (`patient_id`, `dentist_id`, `start_time`, `end_time`, `description`, `clinic_id`, `type_id`)
+VALUES (1, 1, '2022-12-13 12:00:00', '2022-12-13 12:30:00', 'Limpeza bucal', 1, 2);
@@ -2,33 +2,123 @@ package handler
 
 import (
 	"checkpoint2/internal/appointment"
+	"checkpoint2/internal/appointmenttype"
 	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/timezone"
 	"checkpoint2/pkg/web"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type appointmentHandler struct {
-	s appointment.Service
+	s     appointment.Service
+	types appointmenttype.Service
 }
 
-func NewAppointmentHandler(s appointment.Service) *appointmentHandler {
+func NewAppointmentHandler(s appointment.Service, types appointmenttype.Service) *appointmentHandler {
 	return &appointmentHandler{
-		s: s,
+		s:     s,
+		types: types,
 	}
 }
 
+const (
+	defaultAppointmentDuration = 30 * time.Minute
+	maxAvailabilityRange       = 31 * 24 * time.Hour
+	defaultPageLimit           = 50
+	maxPageLimit               = 200
+)
+
+var appointmentTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+// legacyDateLayouts are the dd/mm/yyyy formats accepted in the old "date"
+// field while clients migrate to ISO 8601 "start" and "end".
+var legacyDateLayouts = []string{"02/01/2006 15:04", "02/01/2006"}
+
+func parseScope(ctx *gin.Context) (string, error) {
+	scope := ctx.DefaultQuery("scope", appointment.ScopeSingle)
+	switch scope {
+	case appointment.ScopeSingle, appointment.ScopeFollowing, appointment.ScopeAll:
+		return scope, nil
+	}
+	return "", errors.New("scope must be single, following or all")
+}
+
 func validateEmptysAppointment(appointment *domain.Appointment) (bool, error) {
 	switch {
-	case appointment.Date == "" || appointment.Description == "":
-		return false, errors.New("date and description can't be empty")
+	case appointment.Start.IsZero() || appointment.Description == "" && appointment.TypeId == 0:
+		return false, errors.New("start and description or type_id can't be empty")
 	}
 	return true, nil
 }
 
+func parseAppointmentTime(value string) (time.Time, error) {
+	for _, layout := range appointmentTimeLayouts {
+		if t, err := timezone.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range legacyDateLayouts {
+		if t, err := timezone.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date, expected ISO 8601 (2006-01-02T15:04:05Z07:00) or dd/mm/yyyy")
+}
+
+// parseAppointmentPeriod resolves the start and end of an appointment from
+// the request fields. start takes precedence over the legacy date, and end
+// takes precedence over duration (in minutes). When neither end nor duration
+// is given, end is left zero if required is false, otherwise the default
+// duration is applied.
+func parseAppointmentPeriod(start string, date string, end string, duration int, required bool) (time.Time, time.Time, error) {
+	if start == "" {
+		start = date
+	}
+	if start == "" {
+		if end != "" || duration != 0 {
+			return time.Time{}, time.Time{}, errors.New("end and duration require start")
+		}
+		if required {
+			return time.Time{}, time.Time{}, errors.New("start can't be empty")
+		}
+		return time.Time{}, time.Time{}, nil
+	}
+
+	startTime, err := parseAppointmentTime(start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	var endTime time.Time
+	switch {
+	case end != "":
+		endTime, err = parseAppointmentTime(end)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	case duration < 0:
+		return time.Time{}, time.Time{}, errors.New("duration must be positive")
+	case duration > 0:
+		endTime = startTime.Add(time.Duration(duration) * time.Minute)
+	case required:
+		endTime = startTime.Add(defaultAppointmentDuration)
+	default:
+		return startTime, time.Time{}, nil
+	}
+
+	if !endTime.After(startTime) {
+		return time.Time{}, time.Time{}, errors.New("end must be after start")
+	}
+	return startTime, endTime, nil
+}
+
 func (h *appointmentHandler) ReadById() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		id, err := strconv.Atoi(ctx.Param("id"))
@@ -48,7 +138,12 @@ func (h *appointmentHandler) ReadById() gin.HandlerFunc {
 func (h *appointmentHandler) ReadByRg() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		rg := ctx.Param("rg")
-		appointments, err := h.s.ReadByRg(rg)
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		appointments, err := h.s.ReadByRg(rg, idClinic)
 		if err != nil {
 			web.Failure(ctx, http.StatusNotFound, err)
 			return
@@ -57,10 +152,77 @@ func (h *appointmentHandler) ReadByRg() gin.HandlerFunc {
 	}
 }
 
+// Search lists appointments filtered by dentist, patient, clinic, type,
+// period, status and description text, one page at a time, with the total
+// number of matches in the meta of the response.
+func (h *appointmentHandler) Search() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter := domain.AppointmentFilter{
+			DentistRegistration: ctx.Query("dentist_registration"),
+			PatientRG:           ctx.Query("patient_rg"),
+			Text:                ctx.Query("q"),
+			Status:              ctx.Query("status"),
+			Sort:                ctx.DefaultQuery("sort", "start"),
+		}
+
+		var err error
+		if filter.DentistId, err = queryInt(ctx, "dentist_id", 0); err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if filter.PatientId, err = queryInt(ctx, "patient_id", 0); err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if filter.ClinicId, err = queryInt(ctx, "clinic_id", 0); err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if filter.TypeId, err = queryInt(ctx, "type_id", 0); err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if filter.Limit, err = queryInt(ctx, "limit", defaultPageLimit); err != nil || filter.Limit < 1 || filter.Limit > maxPageLimit {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("limit must be between 1 and 200"))
+			return
+		}
+		if filter.Offset, err = queryInt(ctx, "offset", 0); err != nil || filter.Offset < 0 {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid offset"))
+			return
+		}
+		if value := ctx.Query("from"); value != "" {
+			if filter.From, err = parseAppointmentTime(value); err != nil {
+				web.Failure(ctx, http.StatusBadRequest, errors.New("invalid from"))
+				return
+			}
+		}
+		if value := ctx.Query("to"); value != "" {
+			if filter.To, err = parseAppointmentTime(value); err != nil {
+				web.Failure(ctx, http.StatusBadRequest, errors.New("invalid to"))
+				return
+			}
+		}
+
+		appointments, total, err := h.s.Search(filter)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.SuccessWithMeta(ctx, http.StatusOK, appointments, web.Meta{Total: total, Limit: filter.Limit, Offset: filter.Offset})
+	}
+}
+
 func (h *appointmentHandler) CreateById() gin.HandlerFunc {
 	type Request struct {
-		Date        string `json:"date" binding:"required"`
-		Description string `json:"description" binding:"required"`
+		Start       string `json:"start"`
+		End         string `json:"end"`
+		Duration    int    `json:"duration"`
+		Date        string `json:"date"`
+		Description string `json:"description"`
+		RoomId      int    `json:"room_id"`
+		ClinicId    int    `json:"clinic_id"`
+		TypeId      int    `json:"type_id"`
+		OverrideBy  string `json:"override_by"`
 	}
 	return func(ctx *gin.Context) {
 		var request Request
@@ -80,9 +242,22 @@ func (h *appointmentHandler) CreateById() gin.HandlerFunc {
 			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
 			return
 		}
+		start, end, err := parseAppointmentPeriod(request.Start, request.Date, request.End, request.Duration, true)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if request.TypeId != 0 && request.End == "" && request.Duration == 0 {
+			end = time.Time{}
+		}
 		appointment := domain.Appointment{
-			Date:        request.Date,
+			Start:       start,
+			End:         end,
 			Description: request.Description,
+			RoomId:      request.RoomId,
+			ClinicId:    request.ClinicId,
+			TypeId:      request.TypeId,
+			OverrideBy:  request.OverrideBy,
 		}
 		valid, err := validateEmptysAppointment(&appointment)
 		if !valid {
@@ -92,7 +267,7 @@ func (h *appointmentHandler) CreateById() gin.HandlerFunc {
 
 		newAppointment, err := h.s.CreateById(appointment, idPatient, idDentist)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 		web.Success(ctx, http.StatusCreated, newAppointment)
@@ -101,8 +276,15 @@ func (h *appointmentHandler) CreateById() gin.HandlerFunc {
 
 func (h *appointmentHandler) CreateByRgAndRegistration() gin.HandlerFunc {
 	type Request struct {
-		Date        string `json:"date" binding:"required"`
-		Description string `json:"description" binding:"required"`
+		Start       string `json:"start"`
+		End         string `json:"end"`
+		Duration    int    `json:"duration"`
+		Date        string `json:"date"`
+		Description string `json:"description"`
+		RoomId      int    `json:"room_id"`
+		ClinicId    int    `json:"clinic_id"`
+		TypeId      int    `json:"type_id"`
+		OverrideBy  string `json:"override_by"`
 	}
 	return func(ctx *gin.Context) {
 		var req Request
@@ -114,9 +296,22 @@ func (h *appointmentHandler) CreateByRgAndRegistration() gin.HandlerFunc {
 			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
 			return
 		}
+		start, end, err := parseAppointmentPeriod(req.Start, req.Date, req.End, req.Duration, true)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if req.TypeId != 0 && req.End == "" && req.Duration == 0 {
+			end = time.Time{}
+		}
 		appointment := domain.Appointment{
-			Date:        req.Date,
+			Start:       start,
+			End:         end,
 			Description: req.Description,
+			RoomId:      req.RoomId,
+			ClinicId:    req.ClinicId,
+			TypeId:      req.TypeId,
+			OverrideBy:  req.OverrideBy,
 		}
 		valid, err := validateEmptysAppointment(&appointment)
 		if !valid {
@@ -126,7 +321,7 @@ func (h *appointmentHandler) CreateByRgAndRegistration() gin.HandlerFunc {
 
 		createdAppointment, err := h.s.CreateByRgAndRegistration(appointment, rgPatient, registrationDentist)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 		web.Success(ctx, http.StatusCreated, createdAppointment)
@@ -137,8 +332,14 @@ func (h *appointmentHandler) Update() gin.HandlerFunc {
 	type Request struct {
 		PatientId   int    `json:"patient_id" binding:"required"`
 		DentistId   int    `json:"dentist_id" binding:"required"`
-		Date        string `json:"date" binding:"required"`
-		Description string `json:"description" binding:"required"`
+		Start       string `json:"start"`
+		End         string `json:"end"`
+		Duration    int    `json:"duration"`
+		Date        string `json:"date"`
+		Description string `json:"description"`
+		RoomId      int    `json:"room_id"`
+		ClinicId    int    `json:"clinic_id"`
+		TypeId      int    `json:"type_id"`
 	}
 	return func(ctx *gin.Context) {
 		var req Request
@@ -152,10 +353,18 @@ func (h *appointmentHandler) Update() gin.HandlerFunc {
 			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
 			return
 		}
-		if req.PatientId == 0 || req.DentistId == 0 || req.Date == "" || req.Description == "" {
+		if req.PatientId == 0 || req.DentistId == 0 || req.Description == "" && req.TypeId == 0 {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("fields can't be empty"))
+			return
+		}
+		start, end, err := parseAppointmentPeriod(req.Start, req.Date, req.End, req.Duration, true)
+		if err != nil {
 			web.Failure(ctx, http.StatusUnprocessableEntity, err)
 			return
 		}
+		if req.TypeId != 0 && req.End == "" && req.Duration == 0 {
+			end = time.Time{}
+		}
 		updateRequestAppointment := domain.Appointment{
 			Patient: domain.Patient{
 				Id: req.PatientId,
@@ -163,12 +372,16 @@ func (h *appointmentHandler) Update() gin.HandlerFunc {
 			Dentist: domain.Dentist{
 				Id: req.DentistId,
 			},
-			Date:        req.Date,
+			Start:       start,
+			End:         end,
 			Description: req.Description,
+			RoomId:      req.RoomId,
+			ClinicId:    req.ClinicId,
+			TypeId:      req.TypeId,
 		}
 		updatedAppointment, err := h.s.Update(id, updateRequestAppointment)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 		web.Success(ctx, http.StatusOK, updatedAppointment)
@@ -179,8 +392,14 @@ func (h *appointmentHandler) Patch() gin.HandlerFunc {
 	type Request struct {
 		PatientId   int    `json:"patient_id"`
 		DentistId   int    `json:"dentist_id"`
+		Start       string `json:"start"`
+		End         string `json:"end"`
+		Duration    int    `json:"duration"`
 		Date        string `json:"date"`
 		Description string `json:"description"`
+		RoomId      int    `json:"room_id"`
+		ClinicId    int    `json:"clinic_id"`
+		TypeId      int    `json:"type_id"`
 	}
 	return func(ctx *gin.Context) {
 		var req Request
@@ -194,25 +413,52 @@ func (h *appointmentHandler) Patch() gin.HandlerFunc {
 			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
 			return
 		}
-		appointment := domain.Appointment{
+		start, end, err := parseAppointmentPeriod(req.Start, req.Date, req.End, req.Duration, false)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		patch := domain.Appointment{
 			Patient: domain.Patient{
 				Id: req.PatientId,
 			},
 			Dentist: domain.Dentist{
 				Id: req.DentistId,
 			},
-			Date:        req.Date,
+			Start:       start,
+			End:         end,
 			Description: req.Description,
+			RoomId:      req.RoomId,
+			ClinicId:    req.ClinicId,
+			TypeId:      req.TypeId,
+		}
+		scope, err := parseScope(ctx)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
 		}
-		updatedAppointment, err := h.s.Patch(id, appointment)
+		if scope != appointment.ScopeSingle {
+			updatedAppointments, err := h.s.PatchSeries(id, patch, scope)
+			if err != nil {
+				failure(ctx, http.StatusInternalServerError, err)
+				return
+			}
+			web.Success(ctx, http.StatusOK, updatedAppointments)
+			return
+		}
+
+		updatedAppointment, err := h.s.Patch(id, patch)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 		web.Success(ctx, http.StatusOK, updatedAppointment)
 	}
 }
 
+// Delete cancels the appointment, keeping the record. The reason and who
+// cancelled may be given in the reason and changed_by query parameters; only
+// a late cancellation with changed_by=patient counts against the patient.
 func (h *appointmentHandler) Delete() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		id, err := strconv.Atoi(ctx.Param("id"))
@@ -220,12 +466,426 @@ func (h *appointmentHandler) Delete() gin.HandlerFunc {
 			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
 			return
 		}
-		err = h.s.Delete(id)
+		cancellation := domain.Cancellation{
+			Reason:      ctx.Query("reason"),
+			CancelledBy: ctx.Query("changed_by"),
+		}
+		_, err = h.s.Cancel(id, cancellation)
 		if err != nil {
-			web.Failure(ctx, http.StatusNotFound, err)
+			failure(ctx, http.StatusNotFound, err)
 			return
 		}
 
 		web.Success(ctx, http.StatusNoContent, nil)
 	}
 }
+
+func (h *appointmentHandler) Availability() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		from, err := parseAppointmentTime(ctx.Query("from"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid from"))
+			return
+		}
+		to, err := parseAppointmentTime(ctx.Query("to"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid to"))
+			return
+		}
+		if !to.After(from) || to.Sub(from) > maxAvailabilityRange {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("to must be after from and within 31 days"))
+			return
+		}
+		duration := defaultAppointmentDuration
+		if value := ctx.Query("duration"); value != "" {
+			minutes, err := strconv.Atoi(value)
+			if err != nil || minutes <= 0 {
+				web.Failure(ctx, http.StatusBadRequest, errors.New("invalid duration"))
+				return
+			}
+			duration = time.Duration(minutes) * time.Minute
+		}
+
+		var equipment []string
+		for _, value := range ctx.QueryArray("equipment") {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+					equipment = append(equipment, item)
+				}
+			}
+		}
+
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		idType, err := queryInt(ctx, "type_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if idType != 0 {
+			appointmentType, err := h.types.ReadById(idType)
+			if err != nil {
+				web.Failure(ctx, http.StatusNotFound, err)
+				return
+			}
+			if ctx.Query("duration") == "" {
+				duration = time.Duration(appointmentType.Duration) * time.Minute
+			}
+			equipment = append(equipment, appointmentType.Equipment...)
+		}
+
+		slots, err := h.s.Availability(id, from, to, duration, equipment, idClinic)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, slots)
+	}
+}
+
+// Transition moves an appointment to status, recording who requested it.
+func (h *appointmentHandler) Transition(status string) gin.HandlerFunc {
+	type Request struct {
+		ChangedBy string `json:"changed_by" binding:"required"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("changed_by can't be empty"))
+			return
+		}
+
+		updatedAppointment, err := h.s.Transition(id, status, req.ChangedBy)
+		if err != nil {
+			failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedAppointment)
+	}
+}
+
+func (h *appointmentHandler) ReadStatusHistory() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		changes, err := h.s.ReadStatusHistory(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, changes)
+	}
+}
+
+// Cancel cancels an appointment, or with the scope query parameter the
+// following or all open occurrences of its series, keeping the reason given.
+func (h *appointmentHandler) Cancel() gin.HandlerFunc {
+	type Request struct {
+		ChangedBy string `json:"changed_by" binding:"required"`
+		Reason    string `json:"reason"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		scope, err := parseScope(ctx)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("changed_by can't be empty"))
+			return
+		}
+
+		cancellation := domain.Cancellation{
+			Reason:      req.Reason,
+			CancelledBy: req.ChangedBy,
+		}
+		if scope != appointment.ScopeSingle {
+			cancelledAppointments, err := h.s.CancelSeries(id, scope, cancellation)
+			if err != nil {
+				failure(ctx, http.StatusNotFound, err)
+				return
+			}
+			web.Success(ctx, http.StatusOK, cancelledAppointments)
+			return
+		}
+
+		cancelledAppointment, err := h.s.Cancel(id, cancellation)
+		if err != nil {
+			failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, cancelledAppointment)
+	}
+}
+
+func (h *appointmentHandler) ReadSeries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		series, err := h.s.ReadSeries(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, series)
+	}
+}
+
+// CreateSeries books a recurring series, described either by an RRULE such
+// as "FREQ=WEEKLY;INTERVAL=4;COUNT=10" or by frequency, interval and a count
+// or until date.
+func (h *appointmentHandler) CreateSeries() gin.HandlerFunc {
+	type Request struct {
+		Start       string `json:"start"`
+		End         string `json:"end"`
+		Duration    int    `json:"duration"`
+		Description string `json:"description"`
+		RoomId      int    `json:"room_id"`
+		ClinicId    int    `json:"clinic_id"`
+		TypeId      int    `json:"type_id"`
+		OverrideBy  string `json:"override_by"`
+		RRule       string `json:"rrule"`
+		Frequency   string `json:"frequency"`
+		Interval    int    `json:"interval"`
+		Count       int    `json:"count"`
+		Until       string `json:"until"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		idPatient, err := strconv.Atoi(ctx.Param("patient-id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid patient id"))
+			return
+		}
+		idDentist, err := strconv.Atoi(ctx.Param("dentist-id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid dentist id"))
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+		start, end, err := parseAppointmentPeriod(req.Start, "", req.End, req.Duration, true)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if req.TypeId != 0 && req.End == "" && req.Duration == 0 {
+			end = time.Time{}
+		}
+
+		var recurrence domain.Recurrence
+		if req.RRule != "" {
+			recurrence, err = appointment.ParseRRule(req.RRule)
+		} else {
+			var until time.Time
+			if req.Until != "" {
+				until, err = parseAppointmentTime(req.Until)
+				if err != nil {
+					web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid until"))
+					return
+				}
+			}
+			recurrence, err = appointment.NewRecurrence(req.Frequency, req.Interval, req.Count, until)
+		}
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		first := domain.Appointment{
+			Start:       start,
+			End:         end,
+			Description: req.Description,
+			RoomId:      req.RoomId,
+			ClinicId:    req.ClinicId,
+			TypeId:      req.TypeId,
+			OverrideBy:  req.OverrideBy,
+		}
+		series, err := h.s.CreateSeries(first, idPatient, idDentist, recurrence)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, series)
+	}
+}
+
+func (h *appointmentHandler) Reschedule() gin.HandlerFunc {
+	type Request struct {
+		Start     string `json:"start" binding:"required"`
+		End       string `json:"end"`
+		Duration  int    `json:"duration"`
+		DentistId int    `json:"dentist_id"`
+		RoomId    int    `json:"room_id"`
+		ClinicId  int    `json:"clinic_id"`
+		ChangedBy string `json:"changed_by" binding:"required"`
+		Reason    string `json:"reason"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("start and changed_by can't be empty"))
+			return
+		}
+		start, end, err := parseAppointmentPeriod(req.Start, "", req.End, req.Duration, false)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		rescheduled := domain.Appointment{
+			Dentist:  domain.Dentist{Id: req.DentistId},
+			Start:    start,
+			End:      end,
+			RoomId:   req.RoomId,
+			ClinicId: req.ClinicId,
+		}
+		updatedAppointment, err := h.s.Reschedule(id, rescheduled, req.ChangedBy, req.Reason)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedAppointment)
+	}
+}
+
+func (h *appointmentHandler) ReadHistory() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		changes, err := h.s.ReadHistory(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, changes)
+	}
+}
+
+// Agenda returns the dentist's agenda for ?date=2006-01-02, or for the week
+// given as ?week=2006-W01 or as any date within it. Without either it is
+// today's agenda in the dentist's zone.
+func (h *appointmentHandler) Agenda() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+
+		var from, to time.Time
+		switch {
+		case ctx.Query("week") != "":
+			from, err = parseWeek(ctx.Query("week"))
+			if err != nil {
+				web.Failure(ctx, http.StatusBadRequest, err)
+				return
+			}
+			to = from.AddDate(0, 0, 7)
+		default:
+			if value := ctx.Query("date"); value != "" {
+				from, err = timezone.Parse("2006-01-02", value)
+				if err != nil {
+					web.Failure(ctx, http.StatusBadRequest, errors.New("date must be in the yyyy-mm-dd format"))
+					return
+				}
+				to = from.AddDate(0, 0, 1)
+			}
+		}
+
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		agenda, err := h.s.Agenda(id, from, to, idClinic)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, agenda)
+	}
+}
+
+// Report counts the appointments starting between ?from and ?to by clinic
+// and status, optionally for a single ?clinic_id.
+func (h *appointmentHandler) Report() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		from, err := parseAppointmentTime(ctx.Query("from"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid from"))
+			return
+		}
+		to, err := parseAppointmentTime(ctx.Query("to"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid to"))
+			return
+		}
+		if !to.After(from) {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("to must be after from"))
+			return
+		}
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		reports, err := h.s.ReportByClinic(from, to, idClinic)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, reports)
+	}
+}
+
+// parseWeek returns the Monday starting an ISO week such as "2006-W01", or
+// the week containing a "2006-01-02" date.
+func parseWeek(value string) (time.Time, error) {
+	var day time.Time
+	var year, week int
+	if _, err := fmt.Sscanf(value, "%d-W%d", &year, &week); err == nil && week >= 1 && week <= 53 {
+		day = time.Date(year, time.January, 4, 0, 0, 0, 0, timezone.Floating).AddDate(0, 0, 7*(week-1))
+	} else if day, err = timezone.Parse("2006-01-02", value); err != nil {
+		return time.Time{}, errors.New("week must be in the yyyy-Www or yyyy-mm-dd format")
+	}
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)), nil
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"checkpoint2/internal/appointmenttype"
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type appointmentTypeHandler struct {
+	s appointmenttype.Service
+}
+
+func NewAppointmentTypeHandler(s appointmenttype.Service) *appointmentTypeHandler {
+	return &appointmentTypeHandler{
+		s: s,
+	}
+}
+
+func (h *appointmentTypeHandler) ReadAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		appointmentTypes, err := h.s.ReadAll()
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, appointmentTypes)
+	}
+}
+
+func (h *appointmentTypeHandler) ReadById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		appointmentType, err := h.s.ReadById(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, appointmentType)
+	}
+}
+
+func (h *appointmentTypeHandler) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var appointmentType domain.AppointmentType
+		if err := ctx.ShouldBindJSON(&appointmentType); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("name can't be empty"))
+			return
+		}
+		createdAppointmentType, err := h.s.Create(appointmentType)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, createdAppointmentType)
+	}
+}
+
+func (h *appointmentTypeHandler) Update() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var appointmentType domain.AppointmentType
+		if err := ctx.ShouldBindJSON(&appointmentType); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("name can't be empty"))
+			return
+		}
+		updatedAppointmentType, err := h.s.Update(id, appointmentType)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedAppointmentType)
+	}
+}
+
+func (h *appointmentTypeHandler) Patch() gin.HandlerFunc {
+	type Request struct {
+		Name      string   `json:"name"`
+		Duration  int      `json:"duration"`
+		Color     string   `json:"color"`
+		Price     float64  `json:"price"`
+		Equipment []string `json:"equipment"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+		updatedAppointmentType, err := h.s.Patch(id, domain.AppointmentType{
+			Name:      req.Name,
+			Duration:  req.Duration,
+			Color:     req.Color,
+			Price:     req.Price,
+			Equipment: req.Equipment,
+		})
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedAppointmentType)
+	}
+}
+
+func (h *appointmentTypeHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := h.s.Delete(id); err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
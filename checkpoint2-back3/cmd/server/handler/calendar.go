@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"checkpoint2/internal/appointment"
+	"checkpoint2/internal/dentist"
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/patient"
+	"checkpoint2/pkg/ical"
+	"checkpoint2/pkg/web"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	calendarHistory  = 90 * 24 * time.Hour
+	calendarPageSize = 200
+)
+
+type calendarHandler struct {
+	patients     patient.Service
+	dentists     dentist.Service
+	appointments appointment.Service
+}
+
+func NewCalendarHandler(patients patient.Service, dentists dentist.Service, appointments appointment.Service) *calendarHandler {
+	return &calendarHandler{
+		patients:     patients,
+		dentists:     dentists,
+		appointments: appointments,
+	}
+}
+
+// IssuePatientToken creates or rotates the token that lets calendar apps
+// subscribe to the patient's feed without logging in.
+func (h *calendarHandler) IssuePatientToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		calendarToken, err := h.patients.IssueCalendarToken(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, gin.H{
+			"token": calendarToken,
+			"url":   fmt.Sprintf("/patients/id/%d/calendar.ics?token=%s", id, calendarToken),
+		})
+	}
+}
+
+func (h *calendarHandler) IssueDentistToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		calendarToken, err := h.dentists.IssueCalendarToken(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, gin.H{
+			"token": calendarToken,
+			"url":   fmt.Sprintf("/dentists/id/%d/calendar.ics?token=%s", id, calendarToken),
+		})
+	}
+}
+
+func (h *calendarHandler) PatientFeed() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := h.patients.CheckCalendarToken(id, ctx.Query("token")); err != nil {
+			web.Failure(ctx, http.StatusNotFound, errors.New("calendar not found"))
+			return
+		}
+
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		appointments, err := h.feedAppointments(domain.AppointmentFilter{PatientId: id, ClinicId: idClinic})
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		var events []ical.Event
+		for _, a := range appointments {
+			event := appointmentEvent(a)
+			event.Summary = fmt.Sprintf("Appointment with %s %s", a.Dentist.Name, a.Dentist.Surname)
+			events = append(events, event)
+		}
+		writeCalendar(ctx, "Appointments", events)
+	}
+}
+
+func (h *calendarHandler) DentistFeed() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := h.dentists.CheckCalendarToken(id, ctx.Query("token")); err != nil {
+			web.Failure(ctx, http.StatusNotFound, errors.New("calendar not found"))
+			return
+		}
+
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		appointments, err := h.feedAppointments(domain.AppointmentFilter{DentistId: id, ClinicId: idClinic})
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		var events []ical.Event
+		for _, a := range appointments {
+			event := appointmentEvent(a)
+			event.Summary = fmt.Sprintf("%s %s - %s", a.Patient.Name, a.Patient.Surname, a.Description)
+			events = append(events, event)
+		}
+		writeCalendar(ctx, "Agenda", events)
+	}
+}
+
+// feedAppointments returns all the appointments of the last 90 days onwards,
+// reading them a page at a time, cancelled ones included so that calendar
+// apps drop them.
+func (h *calendarHandler) feedAppointments(filter domain.AppointmentFilter) ([]domain.Appointment, error) {
+	filter.From = time.Now().Add(-calendarHistory)
+	filter.Sort = "start"
+	filter.Limit = calendarPageSize
+	var appointments []domain.Appointment
+	for {
+		page, total, err := h.appointments.Search(filter)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, page...)
+		if len(page) < filter.Limit || len(appointments) >= total {
+			return appointments, nil
+		}
+		filter.Offset += filter.Limit
+	}
+}
+
+// appointmentEvent maps an appointment to a VEVENT. The UID only depends on
+// the appointment id so that changes replace the event in subscribed
+// calendars.
+func appointmentEvent(a domain.Appointment) ical.Event {
+	event := ical.Event{
+		UID:          fmt.Sprintf("appointment-%d@checkpoint2", a.Id),
+		Description:  a.Description,
+		Start:        a.Start,
+		End:          a.End,
+		Status:       "CONFIRMED",
+		Sequence:     a.Sequence,
+		LastModified: a.UpdatedAt,
+	}
+	switch a.Status {
+	case domain.StatusScheduled:
+		event.Status = "TENTATIVE"
+	case domain.StatusCancelled, domain.StatusNoShow:
+		event.Status = "CANCELLED"
+	}
+	return event
+}
+
+func writeCalendar(ctx *gin.Context, name string, events []ical.Event) {
+	ctx.Header("Content-Type", "text/calendar; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	if err := ical.Write(ctx.Writer, name, events); err != nil {
+		ctx.Error(err)
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"checkpoint2/internal/clinic"
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type clinicHandler struct {
+	s clinic.Service
+}
+
+func NewClinicHandler(s clinic.Service) *clinicHandler {
+	return &clinicHandler{
+		s: s,
+	}
+}
+
+func (h *clinicHandler) ReadAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		clinics, err := h.s.ReadAll()
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, clinics)
+	}
+}
+
+func (h *clinicHandler) ReadById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		clinic, err := h.s.ReadById(id)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, clinic)
+	}
+}
+
+func (h *clinicHandler) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var clinic domain.Clinic
+		if err := ctx.ShouldBindJSON(&clinic); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("name can't be empty"))
+			return
+		}
+		createdClinic, err := h.s.Create(clinic)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, createdClinic)
+	}
+}
+
+func (h *clinicHandler) Update() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var clinic domain.Clinic
+		if err := ctx.ShouldBindJSON(&clinic); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("name can't be empty"))
+			return
+		}
+		updatedClinic, err := h.s.Update(id, clinic)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedClinic)
+	}
+}
+
+func (h *clinicHandler) Patch() gin.HandlerFunc {
+	type Request struct {
+		Name     string `json:"name"`
+		Address  string `json:"address"`
+		TimeZone string `json:"time_zone"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+		updatedClinic, err := h.s.Patch(id, domain.Clinic{Name: req.Name, Address: req.Address, TimeZone: req.TimeZone})
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedClinic)
+	}
+}
+
+func (h *clinicHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := h.s.Delete(id); err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
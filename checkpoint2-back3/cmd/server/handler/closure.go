@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"checkpoint2/internal/closure"
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type closureHandler struct {
+	s closure.Service
+}
+
+func NewClosureHandler(s closure.Service) *closureHandler {
+	return &closureHandler{
+		s: s,
+	}
+}
+
+type closureRequest struct {
+	ClinicId   int    `json:"clinic_id"`
+	Reason     string `json:"reason" binding:"required"`
+	From       string `json:"from" binding:"required"`
+	To         string `json:"to"`
+	Recurrence string `json:"recurrence"`
+}
+
+// closure parses the request's yyyy-mm-dd dates into a closure.
+func (req closureRequest) closure() (domain.Closure, error) {
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		return domain.Closure{}, errors.New("from must be in the yyyy-mm-dd format")
+	}
+	var to time.Time
+	if req.To != "" {
+		to, err = time.Parse("2006-01-02", req.To)
+		if err != nil {
+			return domain.Closure{}, errors.New("to must be in the yyyy-mm-dd format")
+		}
+	}
+	return domain.Closure{
+		ClinicId:   req.ClinicId,
+		Reason:     req.Reason,
+		From:       from,
+		To:         to,
+		Recurrence: req.Recurrence,
+	}, nil
+}
+
+func (h *closureHandler) ReadAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		closures, err := h.s.ReadAll(idClinic)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, closures)
+	}
+}
+
+func (h *closureHandler) ReadById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		closure, err := h.s.ReadById(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, closure)
+	}
+}
+
+// Between lists the days closed between ?from and ?to, both yyyy-mm-dd and
+// inclusive, with yearly closures expanded to each year.
+func (h *closureHandler) Between() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		from, err := time.Parse("2006-01-02", ctx.Query("from"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("from must be in the yyyy-mm-dd format"))
+			return
+		}
+		to, err := time.Parse("2006-01-02", ctx.Query("to"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("to must be in the yyyy-mm-dd format"))
+			return
+		}
+		if to.Before(from) {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("to can't be before from"))
+			return
+		}
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		closures, err := h.s.Between(from, to, idClinic)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, closures)
+	}
+}
+
+func (h *closureHandler) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req closureRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("reason and from can't be empty"))
+			return
+		}
+		closure, err := req.closure()
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		createdClosure, err := h.s.Create(closure)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, createdClosure)
+	}
+}
+
+func (h *closureHandler) Update() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var req closureRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("reason and from can't be empty"))
+			return
+		}
+		closure, err := req.closure()
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		updatedClosure, err := h.s.Update(id, closure)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedClosure)
+	}
+}
+
+func (h *closureHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := h.s.Delete(id); err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+// Holidays previews the Brazilian national holidays of the year without
+// registering them.
+func (h *closureHandler) Holidays() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		year, err := strconv.Atoi(ctx.Param("year"))
+		if err != nil || year < 1583 || year > 9999 {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid year"))
+			return
+		}
+		web.Success(ctx, http.StatusOK, closure.NationalHolidays(year))
+	}
+}
+
+// CreateHolidays registers the national holidays of the year as closures
+// of every clinic, or of ?clinic_id.
+func (h *closureHandler) CreateHolidays() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		year, err := strconv.Atoi(ctx.Param("year"))
+		if err != nil || year < 1583 || year > 9999 {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid year"))
+			return
+		}
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		closures, err := h.s.CreateHolidays(year, idClinic)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, closures)
+	}
+}
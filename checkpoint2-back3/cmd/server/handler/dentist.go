@@ -7,6 +7,8 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -47,6 +49,89 @@ func (h *dentistHandler) ReadById() gin.HandlerFunc {
 	}
 }
 
+// ReadAll lists the dentists, filtered by name prefix and registration, one
+// page at a time. Pages follow either offset or the cursor of the previous
+// page.
+func (h *dentistHandler) ReadAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		p, err := parsePage(ctx, "id", "name", "surname", "registration")
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		filter := domain.DentistFilter{
+			Name:         ctx.Query("name"),
+			Registration: ctx.Query("registration"),
+			ClinicId:     idClinic,
+			Sort:         p.sort,
+			Limit:        p.limit,
+			Offset:       p.offset,
+			After:        p.after,
+		}
+
+		dentists, total, err := h.s.Search(filter)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		var last domain.Cursor
+		if len(dentists) > 0 {
+			last = dentistCursor(dentists[len(dentists)-1], p.sort)
+		}
+		web.SuccessWithMeta(ctx, http.StatusOK, dentists, p.meta(total, len(dentists), last))
+	}
+}
+
+// SearchByName ranks the dentists whose name and surname match q, ignoring
+// accents and small typos, for type-ahead. clinic_id keeps the dentists who
+// work at that clinic.
+func (h *dentistHandler) SearchByName() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		query := ctx.Query("q")
+		if strings.TrimSpace(query) == "" {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("q can't be empty"))
+			return
+		}
+		limit, err := queryInt(ctx, "limit", defaultSearchLimit)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("limit must be between 1 and 50"))
+			return
+		}
+
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		dentists, err := h.s.SearchByName(query, limit, idClinic)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, dentists)
+	}
+}
+
+func dentistCursor(dentist domain.Dentist, sort string) domain.Cursor {
+	cursor := domain.Cursor{Value: strconv.Itoa(dentist.Id), Id: dentist.Id}
+	switch strings.TrimPrefix(sort, "-") {
+	case "name":
+		cursor.Value = dentist.Name
+	case "surname":
+		cursor.Value = dentist.Surname
+	case "registration":
+		cursor.Value = dentist.Registration
+	}
+	return cursor
+}
+
 func (h *dentistHandler) ReadByRegistration() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		dentist, err := h.s.ReadByRegistration(ctx.Param("registration"))
@@ -74,7 +159,7 @@ func (h *dentistHandler) Create() gin.HandlerFunc {
 		}
 		createdDentist, err := h.s.Create(dentist)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 		web.Success(ctx, http.StatusCreated, createdDentist)
@@ -105,7 +190,7 @@ func (h *dentistHandler) Update() gin.HandlerFunc {
 
 		createdDentist, err := h.s.Update(id, dentist)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -118,6 +203,7 @@ func (h *dentistHandler) Patch() gin.HandlerFunc {
 		Surname      string `json:"surname,omitempty"`
 		Name         string `json:"name,omitempty"`
 		Registration string `json:"registration,omitempty"`
+		TimeZone     string `json:"time_zone,omitempty"`
 	}
 	return func(ctx *gin.Context) {
 		var req Request
@@ -135,11 +221,12 @@ func (h *dentistHandler) Patch() gin.HandlerFunc {
 			Surname:      req.Surname,
 			Name:         req.Name,
 			Registration: req.Registration,
+			TimeZone:     req.TimeZone,
 		}
 
 		updatedDentist, err := h.s.Patch(id, update)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -163,3 +250,163 @@ func (h *dentistHandler) Delete() gin.HandlerFunc {
 		web.Success(ctx, http.StatusNoContent, nil)
 	}
 }
+
+func (h *dentistHandler) UpdateSchedule() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var schedule []domain.WorkingHours
+		if err := ctx.ShouldBindJSON(&schedule); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+
+		updatedSchedule, err := h.s.UpdateSchedule(id, schedule)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, updatedSchedule)
+	}
+}
+
+func (h *dentistHandler) UpdateClinics() gin.HandlerFunc {
+	type Request struct {
+		ClinicIds []int `json:"clinic_ids"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+
+		clinicIds, err := h.s.UpdateClinics(id, req.ClinicIds)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, clinicIds)
+	}
+}
+
+func (h *dentistHandler) ReadByClinic() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		dentists, err := h.s.ReadByClinic(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, dentists)
+	}
+}
+
+func (h *dentistHandler) ReadTimeOff() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		from := time.Now()
+		if value := ctx.Query("from"); value != "" {
+			from, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				web.Failure(ctx, http.StatusBadRequest, errors.New("invalid from"))
+				return
+			}
+		}
+		to := from.AddDate(1, 0, 0)
+		if value := ctx.Query("to"); value != "" {
+			to, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				web.Failure(ctx, http.StatusBadRequest, errors.New("invalid to"))
+				return
+			}
+		}
+
+		timeOffs, err := h.s.ReadTimeOff(id, from, to)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusOK, timeOffs)
+	}
+}
+
+func (h *dentistHandler) CreateTimeOff() gin.HandlerFunc {
+	type Request struct {
+		Start  time.Time `json:"start" binding:"required"`
+		End    time.Time `json:"end" binding:"required"`
+		Reason string    `json:"reason"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+		if !req.End.After(req.Start) {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("end must be after start"))
+			return
+		}
+
+		timeOff := domain.TimeOff{
+			DentistId: id,
+			Start:     req.Start,
+			End:       req.End,
+			Reason:    req.Reason,
+		}
+
+		createdTimeOff, err := h.s.CreateTimeOff(timeOff)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusCreated, createdTimeOff)
+	}
+}
+
+func (h *dentistHandler) DeleteTimeOff() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		idTimeOff, err := strconv.Atoi(ctx.Param("time-off-id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid time off id"))
+			return
+		}
+		err = h.s.DeleteTimeOff(id, idTimeOff)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"checkpoint2/internal/appointment"
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/patient"
+	"checkpoint2/pkg/store"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// failure writes err with the status matching its type, falling back to
+// status for errors that carry no specific meaning.
+func failure(ctx *gin.Context, status int, err error) {
+	web.Failure(ctx, statusOf(status, err), err)
+}
+
+// statusOf returns the status failure writes err with.
+func statusOf(status int, err error) int {
+	var conflict *appointment.ConflictError
+	var unavailable *appointment.UnavailableError
+	var transition *appointment.TransitionError
+	var restricted *appointment.RestrictedError
+	var guardian *appointment.GuardianRequiredError
+	var notInSeries *appointment.NotInSeriesError
+	var duplicate *store.ConflictError
+	var guardianOf *patient.GuardianOfError
+	var notFound *store.NotFoundError
+	var invalid *domain.ValidationError
+	switch {
+	case errors.As(err, &conflict), errors.As(err, &transition), errors.As(err, &duplicate), errors.As(err, &guardianOf):
+		status = http.StatusConflict
+	case errors.As(err, &unavailable), errors.As(err, &guardian), errors.As(err, &notInSeries), errors.As(err, &invalid):
+		status = http.StatusUnprocessableEntity
+	case errors.As(err, &restricted):
+		status = http.StatusForbidden
+	case errors.As(err, &notFound):
+		status = http.StatusNotFound
+	}
+	return status
+}
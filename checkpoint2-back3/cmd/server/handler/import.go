@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"checkpoint2/internal/importer"
+	"checkpoint2/pkg/web"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type importHandler struct {
+	s importer.Service
+}
+
+func NewImportHandler(s importer.Service) *importHandler {
+	return &importHandler{
+		s: s,
+	}
+}
+
+// Import receives the multipart files "patients", "dentists" and
+// "appointments". With ?dry_run=true it only reports the rows that would
+// fail; otherwise it saves everything, or nothing when any row fails.
+func (h *importHandler) Import() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dry_run", "false"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid dry_run"))
+			return
+		}
+
+		var files importer.Files
+		var opened []io.Closer
+		defer func() {
+			for _, f := range opened {
+				f.Close()
+			}
+		}()
+		open := func(field string) (io.Reader, *multipart.FileHeader, error) {
+			header, err := ctx.FormFile(field)
+			if errors.Is(err, http.ErrMissingFile) {
+				return nil, nil, nil
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			f, err := header.Open()
+			if err != nil {
+				return nil, nil, err
+			}
+			opened = append(opened, f)
+			return f, header, nil
+		}
+
+		if files.Patients, _, err = open(importer.FilePatients); err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if files.Dentists, _, err = open(importer.FileDentists); err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		appointments, header, err := open(importer.FileAppointments)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if appointments != nil {
+			files.Appointments = appointments
+			files.AppointmentsFormat = importer.FormatCSV
+			if strings.EqualFold(filepath.Ext(header.Filename), ".ics") || strings.HasPrefix(header.Header.Get("Content-Type"), "text/calendar") {
+				files.AppointmentsFormat = importer.FormatICS
+			}
+		}
+		if files.Patients == nil && files.Dentists == nil && files.Appointments == nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("send at least one of the files patients, dentists or appointments"))
+			return
+		}
+
+		report, err := h.s.Import(files, dryRun)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		switch {
+		case report.Committed:
+			web.Success(ctx, http.StatusCreated, report)
+		case !dryRun:
+			web.Success(ctx, http.StatusUnprocessableEntity, report)
+		default:
+			web.Success(ctx, http.StatusOK, report)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/odontogram"
+	"checkpoint2/pkg/timezone"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type odontogramHandler struct {
+	s odontogram.Service
+}
+
+func NewOdontogramHandler(s odontogram.Service) *odontogramHandler {
+	return &odontogramHandler{
+		s: s,
+	}
+}
+
+// toothChangeRequest charts condition on each of surfaces, or on the whole
+// tooth when no surface is given.
+type toothChangeRequest struct {
+	Tooth     int      `json:"tooth" binding:"required"`
+	Surfaces  []string `json:"surfaces"`
+	Condition string   `json:"condition" binding:"required"`
+	Notes     string   `json:"notes"`
+}
+
+type odontogramRequest struct {
+	AppointmentId int                  `json:"appointment_id" binding:"required"`
+	Changes       []toothChangeRequest `json:"changes" binding:"required,dive"`
+}
+
+// parseChartTime reads the moment a chart is wanted at: an ISO 8601 time, or
+// a date standing for the end of that day in loc.
+func parseChartTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := timezone.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := timezone.Parse("2006-01-02", value); err == nil {
+		return timezone.Localize(t, loc).AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Time{}, errors.New("invalid at, expected ISO 8601 (2006-01-02T15:04:05Z07:00) or 2006-01-02")
+}
+
+// Read returns the patient's current chart or, with the at query parameter,
+// the chart as it stood then.
+func (h *odontogramHandler) Read() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var at time.Time
+		if value := ctx.Query("at"); value != "" {
+			loc, err := h.s.Location(id)
+			if err != nil {
+				failure(ctx, http.StatusInternalServerError, err)
+				return
+			}
+			if at, err = parseChartTime(value, loc); err != nil {
+				web.Failure(ctx, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		chart, err := h.s.Read(id, at)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, chart)
+	}
+}
+
+func (h *odontogramHandler) ReadHistory() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		tooth, err := queryInt(ctx, "tooth", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		changes, err := h.s.ReadHistory(id, tooth)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, changes)
+	}
+}
+
+// Apply records the changes made to the patient's teeth during an
+// appointment and returns the updated chart.
+func (h *odontogramHandler) Apply() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var req odontogramRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("appointment_id and changes with tooth and condition can't be empty"))
+			return
+		}
+
+		var changes []domain.ToothChange
+		for _, change := range req.Changes {
+			surfaces := change.Surfaces
+			if len(surfaces) == 0 {
+				surfaces = []string{""}
+			}
+			for _, surface := range surfaces {
+				changes = append(changes, domain.ToothChange{
+					Tooth:     change.Tooth,
+					Surface:   surface,
+					Condition: change.Condition,
+					Notes:     change.Notes,
+				})
+			}
+		}
+
+		chart, err := h.s.Apply(id, req.AppointmentId, changes)
+		if err != nil {
+			failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, chart)
+	}
+}
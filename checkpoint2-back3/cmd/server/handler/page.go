@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/web"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// page is the slice of a listing asked for by the limit, offset, sort and
+// cursor query parameters.
+type page struct {
+	limit  int
+	offset int
+	sort   string
+	after  domain.Cursor
+}
+
+type cursorToken struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    int    `json:"i"`
+}
+
+// parsePage reads the page of a listing sorted by one of sorts, id by
+// default. A cursor only continues the listing it came from, so it must come
+// with the same sort.
+func parsePage(ctx *gin.Context, sorts ...string) (page, error) {
+	p := page{sort: ctx.DefaultQuery("sort", "id")}
+
+	var err error
+	if p.limit, err = queryInt(ctx, "limit", defaultPageLimit); err != nil || p.limit < 1 || p.limit > maxPageLimit {
+		return page{}, errors.New("limit must be between 1 and 200")
+	}
+	if p.offset, err = queryInt(ctx, "offset", 0); err != nil || p.offset < 0 {
+		return page{}, errors.New("invalid offset")
+	}
+
+	valid := false
+	for _, sort := range sorts {
+		valid = valid || strings.TrimPrefix(p.sort, "-") == sort
+	}
+	if !valid {
+		return page{}, fmt.Errorf("sort must be one of %s", strings.Join(sorts, ", "))
+	}
+
+	if value := ctx.Query("cursor"); value != "" {
+		var token cursorToken
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || json.Unmarshal(data, &token) != nil || token.Id == 0 {
+			return page{}, errors.New("invalid cursor")
+		}
+		if token.Sort != p.sort {
+			return page{}, errors.New("cursor belongs to another sort")
+		}
+		p.after = domain.Cursor{Value: token.Value, Id: token.Id}
+	}
+	return p, nil
+}
+
+// meta describes the page holding count rows out of total. The cursor of the
+// next page is built from last, the page's last row, when there may be more.
+func (p page) meta(total int, count int, last domain.Cursor) web.Meta {
+	meta := web.Meta{Total: total, Limit: p.limit, Offset: p.offset}
+	if p.after.Id != 0 {
+		meta.Offset = 0
+	}
+
+	more := count == p.limit && (p.after.Id != 0 || p.offset+count < total)
+	if more {
+		data, _ := json.Marshal(cursorToken{Sort: p.sort, Value: last.Value, Id: last.Id})
+		meta.NextCursor = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return meta
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -45,6 +46,75 @@ func (h *patientHandler) ReadById() gin.HandlerFunc {
 	}
 }
 
+// ReadAll lists the patients, filtered by name prefix and RG, one page at a
+// time. Pages follow either offset or the cursor of the previous page.
+func (h *patientHandler) ReadAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		p, err := parsePage(ctx, "id", "name", "surname", "registration_date")
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		filter := domain.PatientFilter{
+			Name:   ctx.Query("name"),
+			RG:     ctx.Query("rg"),
+			Sort:   p.sort,
+			Limit:  p.limit,
+			Offset: p.offset,
+			After:  p.after,
+		}
+
+		patients, total, err := h.s.Search(filter)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		var last domain.Cursor
+		if len(patients) > 0 {
+			last = patientCursor(patients[len(patients)-1], p.sort)
+		}
+		web.SuccessWithMeta(ctx, http.StatusOK, patients, p.meta(total, len(patients), last))
+	}
+}
+
+// SearchByName ranks the patients whose name and surname match q, ignoring
+// accents and small typos, for type-ahead.
+func (h *patientHandler) SearchByName() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		query := ctx.Query("q")
+		if strings.TrimSpace(query) == "" {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("q can't be empty"))
+			return
+		}
+		limit, err := queryInt(ctx, "limit", defaultSearchLimit)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("limit must be between 1 and 50"))
+			return
+		}
+
+		patients, err := h.s.SearchByName(query, limit)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, patients)
+	}
+}
+
+func patientCursor(patient domain.Patient, sort string) domain.Cursor {
+	cursor := domain.Cursor{Value: strconv.Itoa(patient.Id), Id: patient.Id}
+	switch strings.TrimPrefix(sort, "-") {
+	case "name":
+		cursor.Value = patient.Name
+	case "surname":
+		cursor.Value = patient.Surname
+	case "registration_date":
+		cursor.Value = patient.RegistrationDate
+	}
+	return cursor
+}
+
 func (h *patientHandler) ReadByRg() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		patient, err := h.s.ReadByRg(ctx.Param("rg"))
@@ -56,6 +126,19 @@ func (h *patientHandler) ReadByRg() gin.HandlerFunc {
 	}
 }
 
+// ReadByCpf looks a patient up by CPF, which may come with or without its
+// punctuation.
+func (h *patientHandler) ReadByCpf() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		patient, err := h.s.ReadByCpf(ctx.Param("cpf"))
+		if err != nil {
+			failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, patient)
+	}
+}
+
 func (h *patientHandler) Create() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var patient domain.Patient
@@ -71,7 +154,7 @@ func (h *patientHandler) Create() gin.HandlerFunc {
 		}
 		createdPatient, err := h.s.Create(patient)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 		web.Success(ctx, http.StatusCreated, createdPatient)
@@ -98,7 +181,7 @@ func (h *patientHandler) Update() gin.HandlerFunc {
 		}
 		createdPatient, err := h.s.Update(id, patient)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 		web.Success(ctx, http.StatusCreated, createdPatient)
@@ -110,7 +193,9 @@ func (h *patientHandler) Patch() gin.HandlerFunc {
 		Surname          string `json:"surname,omitempty"`
 		Name             string `json:"name,omitempty"`
 		Rg               string `json:"rg,omitempty"`
+		Cpf              string `json:"cpf,omitempty"`
 		RegistrationDate string `json:"registration_date,omitempty"`
+		BirthDate        string `json:"birth_date,omitempty"`
 	}
 	return func(ctx *gin.Context) {
 		var request Request
@@ -128,12 +213,14 @@ func (h *patientHandler) Patch() gin.HandlerFunc {
 			Surname:          request.Surname,
 			Name:             request.Name,
 			RG:               request.Rg,
+			CPF:              request.Cpf,
 			RegistrationDate: request.RegistrationDate,
+			BirthDate:        request.BirthDate,
 		}
 
 		updatedPatient, err := h.s.Patch(id, update)
 		if err != nil {
-			web.Failure(ctx, http.StatusInternalServerError, err)
+			failure(ctx, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -150,10 +237,28 @@ func (h *patientHandler) Delete() gin.HandlerFunc {
 		}
 		err = h.s.Delete(id)
 		if err != nil {
-			web.Failure(ctx, http.StatusNotFound, err)
+			failure(ctx, http.StatusNotFound, err)
 			return
 		}
 
 		web.Success(ctx, http.StatusNoContent, nil)
 	}
 }
+
+// LiftRestriction lets a patient restricted for late cancellations or
+// no-shows be booked again without a staff override.
+func (h *patientHandler) LiftRestriction() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		patient, err := h.s.LiftRestriction(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, patient)
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *patientHandler) ReadContacts() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		contacts, err := h.s.ReadContacts(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, contacts)
+	}
+}
+
+// UpdateContacts replaces the patient's email, phones, address and emergency
+// contact with the ones sent.
+func (h *patientHandler) UpdateContacts() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var contacts domain.Contacts
+		if err := ctx.ShouldBindJSON(&contacts); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+
+		updatedContacts, err := h.s.UpdateContacts(id, contacts)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedContacts)
+	}
+}
+
+func (h *patientHandler) DeleteContacts() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		err = h.s.DeleteContacts(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+func (h *patientHandler) CreatePhone() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var phone domain.Phone
+		if err := ctx.ShouldBindJSON(&phone); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("type and number can't be empty"))
+			return
+		}
+
+		createdPhone, err := h.s.CreatePhone(id, phone)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, createdPhone)
+	}
+}
+
+func (h *patientHandler) UpdatePhone() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		idPhone, err := strconv.Atoi(ctx.Param("phone-id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid phone id"))
+			return
+		}
+		var phone domain.Phone
+		if err := ctx.ShouldBindJSON(&phone); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("type and number can't be empty"))
+			return
+		}
+		phone.Id = idPhone
+
+		updatedPhone, err := h.s.UpdatePhone(id, phone)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedPhone)
+	}
+}
+
+func (h *patientHandler) DeletePhone() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		idPhone, err := strconv.Atoi(ctx.Param("phone-id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid phone id"))
+			return
+		}
+		err = h.s.DeletePhone(id, idPhone)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *patientHandler) ReadGuardians() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		guardians, err := h.s.ReadGuardians(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, guardians)
+	}
+}
+
+// CreateGuardian links the patient to a guardian, either another patient
+// given by patient_id or someone given by name, phone and email.
+func (h *patientHandler) CreateGuardian() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var guardian domain.Guardian
+		if err := ctx.ShouldBindJSON(&guardian); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+
+		createdGuardian, err := h.s.CreateGuardian(id, guardian)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, createdGuardian)
+	}
+}
+
+func (h *patientHandler) DeleteGuardian() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		idGuardian, err := strconv.Atoi(ctx.Param("guardian-id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid guardian id"))
+			return
+		}
+		err = h.s.DeleteGuardian(id, idGuardian)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
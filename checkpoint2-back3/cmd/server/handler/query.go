@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queryInt reads an integer query parameter, returning fallback when absent.
+func queryInt(ctx *gin.Context, key string, fallback int) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s", key)
+	}
+	return n, nil
+}
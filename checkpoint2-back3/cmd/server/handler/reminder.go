@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/reminder"
+	"checkpoint2/pkg/web"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reminderPage is what the links of the reminder messages open. Asking for
+// the page changes nothing: the patient's answer is the form's POST.
+var reminderPage = template.Must(template.New("reminder").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Appointment reminder</title></head>
+<body>
+{{if .Error}}<p>{{.Error}}</p>
+{{else if .Done}}<p>Your appointment on {{.Appointment.Start.Format "02/01/2006 15:04 MST"}} is {{.Appointment.Status}}.</p>
+{{else}}<p>Appointment with Dr. {{.Appointment.Dentist.Name}} {{.Appointment.Dentist.Surname}} on {{.Appointment.Start.Format "02/01/2006 15:04 MST"}}.</p>
+<form method="post"><button type="submit">{{if eq .Action "confirm"}}Confirm{{else}}Cancel{{end}} appointment</button></form>
+{{end}}</body>
+</html>
+`))
+
+type reminderView struct {
+	Action      string
+	Appointment domain.Appointment
+	Done        bool
+	Error       string
+}
+
+type reminderHandler struct {
+	s reminder.Service
+}
+
+func NewReminderHandler(s reminder.Service) *reminderHandler {
+	return &reminderHandler{
+		s: s,
+	}
+}
+
+func (h *reminderHandler) ReadByAppointment() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		reminders, err := h.s.ReadByAppointment(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, reminders)
+	}
+}
+
+// Page serves the links of the reminder messages, authorized by the reminder
+// token alone. It asks the patient to confirm action, confirm or cancel.
+func (h *reminderHandler) Page(action string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		appointment, err := h.s.ReadAppointment(ctx.Param("token"))
+		writeReminderPage(ctx, reminderView{Action: action, Appointment: appointment}, err)
+	}
+}
+
+func (h *reminderHandler) Confirm() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		updatedAppointment, err := h.s.Confirm(ctx.Param("token"))
+		writeReminderPage(ctx, reminderView{Action: "confirm", Appointment: updatedAppointment, Done: true}, err)
+	}
+}
+
+func (h *reminderHandler) Cancel() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		updatedAppointment, err := h.s.Cancel(ctx.Param("token"))
+		writeReminderPage(ctx, reminderView{Action: "cancel", Appointment: updatedAppointment, Done: true}, err)
+	}
+}
+
+// writeReminderPage writes view, or err with the status failure would give
+// it, as a page for the patient's browser.
+func writeReminderPage(ctx *gin.Context, view reminderView, err error) {
+	status := http.StatusOK
+	if err != nil {
+		status = statusOf(http.StatusNotFound, err)
+		if errors.Is(err, reminder.ErrOutdated) {
+			status = http.StatusGone
+		}
+		view = reminderView{Error: err.Error()}
+	}
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(status)
+	if err := reminderPage.Execute(ctx.Writer, view); err != nil {
+		ctx.Error(err)
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/room"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type roomHandler struct {
+	s room.Service
+}
+
+func NewRoomHandler(s room.Service) *roomHandler {
+	return &roomHandler{
+		s: s,
+	}
+}
+
+func (h *roomHandler) ReadAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		rooms, err := h.s.ReadAll()
+		if idClinic != 0 {
+			rooms, err = h.s.ReadByClinic(idClinic)
+		}
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, rooms)
+	}
+}
+
+func (h *roomHandler) ReadById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		room, err := h.s.ReadById(id)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, room)
+	}
+}
+
+func (h *roomHandler) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var room domain.Room
+		if err := ctx.ShouldBindJSON(&room); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("name can't be empty"))
+			return
+		}
+		createdRoom, err := h.s.Create(room)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, createdRoom)
+	}
+}
+
+func (h *roomHandler) Update() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		var room domain.Room
+		if err := ctx.ShouldBindJSON(&room); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("name can't be empty"))
+			return
+		}
+		updatedRoom, err := h.s.Update(id, room)
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedRoom)
+	}
+}
+
+func (h *roomHandler) Patch() gin.HandlerFunc {
+	type Request struct {
+		Name      string   `json:"name"`
+		Equipment []string `json:"equipment"`
+		ClinicId  int      `json:"clinic_id"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+		updatedRoom, err := h.s.Patch(id, domain.Room{Name: req.Name, Equipment: req.Equipment, ClinicId: req.ClinicId})
+		if err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, updatedRoom)
+	}
+}
+
+func (h *roomHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		if err := h.s.Delete(id); err != nil {
+			failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
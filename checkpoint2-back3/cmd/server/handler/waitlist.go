@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/waitlist"
+	"checkpoint2/pkg/web"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type waitlistHandler struct {
+	s waitlist.Service
+}
+
+func NewWaitlistHandler(s waitlist.Service) *waitlistHandler {
+	return &waitlistHandler{
+		s: s,
+	}
+}
+
+func (h *waitlistHandler) ReadById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		entry, err := h.s.ReadById(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, entry)
+	}
+}
+
+func (h *waitlistHandler) ReadAll() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		idDentist := 0
+		if value := ctx.Query("dentist_id"); value != "" {
+			var err error
+			idDentist, err = strconv.Atoi(value)
+			if err != nil {
+				web.Failure(ctx, http.StatusBadRequest, errors.New("invalid dentist id"))
+				return
+			}
+		}
+		idClinic, err := queryInt(ctx, "clinic_id", 0)
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, err)
+			return
+		}
+		entries, err := h.s.ReadAll(idDentist, idClinic)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusOK, entries)
+	}
+}
+
+func (h *waitlistHandler) Join() gin.HandlerFunc {
+	type Request struct {
+		PatientId    int    `json:"patient_id" binding:"required"`
+		DentistId    int    `json:"dentist_id"`
+		ClinicId     int    `json:"clinic_id"`
+		From         string `json:"from" binding:"required"`
+		To           string `json:"to" binding:"required"`
+		EarliestTime string `json:"earliest_time"`
+		LatestTime   string `json:"latest_time"`
+	}
+	return func(ctx *gin.Context) {
+		var req Request
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("invalid json"))
+			return
+		}
+		from, err := time.Parse("2006-01-02", req.From)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("from must be in the yyyy-mm-dd format"))
+			return
+		}
+		to, err := time.Parse("2006-01-02", req.To)
+		if err != nil {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("to must be in the yyyy-mm-dd format"))
+			return
+		}
+		if to.Before(from) {
+			web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("to can't be before from"))
+			return
+		}
+		for _, clock := range []string{req.EarliestTime, req.LatestTime} {
+			if _, err := time.Parse("15:04", clock); clock != "" && err != nil {
+				web.Failure(ctx, http.StatusUnprocessableEntity, errors.New("earliest_time and latest_time must be in the hh:mm format"))
+				return
+			}
+		}
+
+		entry := domain.WaitlistEntry{
+			Patient:      domain.Patient{Id: req.PatientId},
+			DentistId:    req.DentistId,
+			ClinicId:     req.ClinicId,
+			From:         from,
+			To:           to,
+			EarliestTime: req.EarliestTime,
+			LatestTime:   req.LatestTime,
+		}
+		createdEntry, err := h.s.Join(entry)
+		if err != nil {
+			web.Failure(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, createdEntry)
+	}
+}
+
+func (h *waitlistHandler) Leave() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		err = h.s.Leave(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusNotFound, err)
+			return
+		}
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
+
+func (h *waitlistHandler) Claim() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		createdAppointment, err := h.s.Claim(id)
+		if err != nil {
+			failure(ctx, http.StatusConflict, err)
+			return
+		}
+		web.Success(ctx, http.StatusCreated, createdAppointment)
+	}
+}
+
+func (h *waitlistHandler) Decline() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			web.Failure(ctx, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		err = h.s.Decline(id)
+		if err != nil {
+			web.Failure(ctx, http.StatusConflict, err)
+			return
+		}
+		web.Success(ctx, http.StatusNoContent, nil)
+	}
+}
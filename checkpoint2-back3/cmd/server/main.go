@@ -3,11 +3,22 @@ package main
 import (
 	"checkpoint2/cmd/server/handler"
 	"checkpoint2/connections"
+	"log"
 	"net/http"
+	"time"
 
 	"checkpoint2/internal/appointment"
+	"checkpoint2/internal/appointmenttype"
+	"checkpoint2/internal/clinic"
+	"checkpoint2/internal/closure"
 	"checkpoint2/internal/dentist"
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/importer"
+	"checkpoint2/internal/odontogram"
 	"checkpoint2/internal/patient"
+	"checkpoint2/internal/reminder"
+	"checkpoint2/internal/room"
+	"checkpoint2/internal/waitlist"
 
 	"checkpoint2/pkg/store"
 
@@ -16,7 +27,7 @@ import (
 
 func main() {
 
-	sqlStore := connections.NewSQLStore();
+	sqlStore := connections.NewSQLStore()
 
 	r := gin.Default()
 
@@ -29,36 +40,128 @@ func main() {
 
 	patients := r.Group("/patients")
 	{
+		patients.GET("", patientHandler.ReadAll())
+		patients.GET("/search", patientHandler.SearchByName())
 		patients.GET("/id/:id", patientHandler.ReadById())
 		patients.GET("/rg/:rg", patientHandler.ReadByRg())
+		patients.GET("/cpf/:cpf", patientHandler.ReadByCpf())
 		patients.POST("", patientHandler.Create())
 		patients.PUT(":id", patientHandler.Update())
 		patients.PATCH(":id", patientHandler.Patch())
 		patients.DELETE(":id", patientHandler.Delete())
+		patients.DELETE("/id/:id/restriction", patientHandler.LiftRestriction())
+		patients.GET("/id/:id/contacts", patientHandler.ReadContacts())
+		patients.PUT("/id/:id/contacts", patientHandler.UpdateContacts())
+		patients.DELETE("/id/:id/contacts", patientHandler.DeleteContacts())
+		patients.POST("/id/:id/contacts/phones", patientHandler.CreatePhone())
+		patients.PUT("/id/:id/contacts/phones/:phone-id", patientHandler.UpdatePhone())
+		patients.DELETE("/id/:id/contacts/phones/:phone-id", patientHandler.DeletePhone())
+		patients.GET("/id/:id/guardians", patientHandler.ReadGuardians())
+		patients.POST("/id/:id/guardians", patientHandler.CreateGuardian())
+		patients.DELETE("/id/:id/guardians/:guardian-id", patientHandler.DeleteGuardian())
 	}
 
+	sqlStorageClinic := store.NewSQLStoreClinic(sqlStore)
+	repoClinic := clinic.NewRepository(sqlStorageClinic)
+	serviceClinic := clinic.NewService(repoClinic)
+	clinicHandler := handler.NewClinicHandler(serviceClinic)
+
 	sqlStorageDentist := store.NewSQLStoreDentist(sqlStore)
 	repoDentist := dentist.NewRepository(sqlStorageDentist)
-	serviceDentist := dentist.NewService(repoDentist)
+	serviceDentist := dentist.NewService(repoDentist, repoClinic)
 	dentistHandler := handler.NewDentistHandler(serviceDentist)
 
+	sqlStorageRoom := store.NewSQLStoreRoom(sqlStore)
+	repoRoom := room.NewRepository(sqlStorageRoom)
+	serviceRoom := room.NewService(repoRoom, repoClinic)
+	roomHandler := handler.NewRoomHandler(serviceRoom)
+
+	rooms := r.Group("/rooms")
+	{
+		rooms.GET("", roomHandler.ReadAll())
+		rooms.GET("/:id", roomHandler.ReadById())
+		rooms.POST("", roomHandler.Create())
+		rooms.PUT(":id", roomHandler.Update())
+		rooms.PATCH(":id", roomHandler.Patch())
+		rooms.DELETE(":id", roomHandler.Delete())
+	}
+
+	sqlStorageClosure := store.NewSQLStoreClosure(sqlStore)
+	repoClosure := closure.NewRepository(sqlStorageClosure)
+	serviceClosure := closure.NewService(repoClosure, repoClinic)
+	closureHandler := handler.NewClosureHandler(serviceClosure)
+
+	closures := r.Group("/closures")
+	{
+		closures.GET("", closureHandler.ReadAll())
+		closures.GET("/:id", closureHandler.ReadById())
+		closures.GET("/calendar", closureHandler.Between())
+		closures.GET("/holidays/:year", closureHandler.Holidays())
+		closures.POST("/holidays/:year", closureHandler.CreateHolidays())
+		closures.POST("", closureHandler.Create())
+		closures.PUT(":id", closureHandler.Update())
+		closures.DELETE(":id", closureHandler.Delete())
+	}
+
+	sqlStorageAppointmentType := store.NewSQLStoreAppointmentType(sqlStore)
+	repoAppointmentType := appointmenttype.NewRepository(sqlStorageAppointmentType)
+	serviceAppointmentType := appointmenttype.NewService(repoAppointmentType)
+	appointmentTypeHandler := handler.NewAppointmentTypeHandler(serviceAppointmentType)
+
+	appointmentTypes := r.Group("/appointment-types")
+	{
+		appointmentTypes.GET("", appointmentTypeHandler.ReadAll())
+		appointmentTypes.GET("/:id", appointmentTypeHandler.ReadById())
+		appointmentTypes.POST("", appointmentTypeHandler.Create())
+		appointmentTypes.PUT(":id", appointmentTypeHandler.Update())
+		appointmentTypes.PATCH(":id", appointmentTypeHandler.Patch())
+		appointmentTypes.DELETE(":id", appointmentTypeHandler.Delete())
+	}
+
+	sqlStorageAppointment := store.NewSQLStoreAppointment(sqlStore)
+	repoAppointment := appointment.NewRepository(sqlStorageAppointment)
+	configPolicy, err := policyConfig()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	serviceAppointment := appointment.NewService(repoAppointment, repoPatient, repoDentist, repoRoom, repoClinic, repoClosure, repoAppointmentType, configPolicy)
+	appointmentHandler := handler.NewAppointmentHandler(serviceAppointment, serviceAppointmentType)
+
 	dentists := r.Group("/dentists")
 	{
+		dentists.GET("", dentistHandler.ReadAll())
+		dentists.GET("/search", dentistHandler.SearchByName())
 		dentists.GET("/id/:id", dentistHandler.ReadById())
 		dentists.GET("/registration/:registration", dentistHandler.ReadByRegistration())
 		dentists.POST("", dentistHandler.Create())
 		dentists.PUT(":id", dentistHandler.Update())
 		dentists.PATCH(":id", dentistHandler.Patch())
 		dentists.DELETE(":id", dentistHandler.Delete())
+		dentists.PUT("/id/:id/schedule", dentistHandler.UpdateSchedule())
+		dentists.PUT("/id/:id/clinics", dentistHandler.UpdateClinics())
+		dentists.GET("/id/:id/time-off", dentistHandler.ReadTimeOff())
+		dentists.POST("/id/:id/time-off", dentistHandler.CreateTimeOff())
+		dentists.DELETE("/id/:id/time-off/:time-off-id", dentistHandler.DeleteTimeOff())
+		dentists.GET("/id/:id/availability", appointmentHandler.Availability())
+		dentists.GET("/id/:id/agenda", appointmentHandler.Agenda())
 	}
 
-	sqlStorageAppointment := store.NewSQLStoreAppointment(sqlStore)
-	repoAppointment := appointment.NewRepository(sqlStorageAppointment)
-	serviceAppointment := appointment.NewService(repoAppointment)
-	appointmentHandler := handler.NewAppointmentHandler(serviceAppointment)
+	clinics := r.Group("/clinics")
+	{
+		clinics.GET("", clinicHandler.ReadAll())
+		clinics.GET("/:id", clinicHandler.ReadById())
+		clinics.GET("/:id/dentists", dentistHandler.ReadByClinic())
+		clinics.POST("", clinicHandler.Create())
+		clinics.PUT(":id", clinicHandler.Update())
+		clinics.PATCH(":id", clinicHandler.Patch())
+		clinics.DELETE(":id", clinicHandler.Delete())
+	}
+
+	r.GET("/reports/appointments", appointmentHandler.Report())
 
 	appointments := r.Group("/appointments")
 	{
+		appointments.GET("", appointmentHandler.Search())
 		appointments.GET("/id/:id", appointmentHandler.ReadById())
 		appointments.GET("/rg/:rg", appointmentHandler.ReadByRg())
 		appointments.POST("/id/:patient-id/:dentist-id", appointmentHandler.CreateById())
@@ -66,7 +169,98 @@ func main() {
 		appointments.PUT(":id", appointmentHandler.Update())
 		appointments.PATCH(":id", appointmentHandler.Patch())
 		appointments.DELETE(":id", appointmentHandler.Delete())
+		appointments.POST("/:id/confirm", appointmentHandler.Transition(domain.StatusConfirmed))
+		appointments.POST("/:id/check-in", appointmentHandler.Transition(domain.StatusCheckedIn))
+		appointments.POST("/:id/start", appointmentHandler.Transition(domain.StatusInProgress))
+		appointments.POST("/:id/complete", appointmentHandler.Transition(domain.StatusCompleted))
+		appointments.POST("/:id/cancel", appointmentHandler.Cancel())
+		appointments.POST("/:id/no-show", appointmentHandler.Transition(domain.StatusNoShow))
+		appointments.GET("/:id/status-history", appointmentHandler.ReadStatusHistory())
+		appointments.POST("/:id/reschedule", appointmentHandler.Reschedule())
+		appointments.GET("/:id/history", appointmentHandler.ReadHistory())
+		appointments.GET("/series/:id", appointmentHandler.ReadSeries())
+		appointments.POST("/series/:patient-id/:dentist-id", appointmentHandler.CreateSeries())
+	}
+
+	sqlStorageOdontogram := store.NewSQLStoreOdontogram(sqlStore)
+	repoOdontogram := odontogram.NewRepository(sqlStorageOdontogram)
+	serviceOdontogram := odontogram.NewService(repoOdontogram, repoPatient, serviceAppointment)
+	odontogramHandler := handler.NewOdontogramHandler(serviceOdontogram)
+
+	odontograms := r.Group("/patients/id/:id/odontogram")
+	{
+		odontograms.GET("", odontogramHandler.Read())
+		odontograms.GET("/history", odontogramHandler.ReadHistory())
+		odontograms.POST("/changes", odontogramHandler.Apply())
+	}
+
+	sqlStorageWaitlist := store.NewSQLStoreWaitlist(sqlStore)
+	repoWaitlist := waitlist.NewRepository(sqlStorageWaitlist)
+	serviceWaitlist := waitlist.NewService(repoWaitlist, serviceAppointment, 2*time.Hour)
+	serviceAppointment.AddSlotListener(serviceWaitlist)
+	waitlistHandler := handler.NewWaitlistHandler(serviceWaitlist)
+	calendarHandler := handler.NewCalendarHandler(servicePatient, serviceDentist, serviceAppointment)
+
+	r.GET("/patients/id/:id/calendar.ics", calendarHandler.PatientFeed())
+	r.POST("/patients/id/:id/calendar-token", calendarHandler.IssuePatientToken())
+	r.GET("/dentists/id/:id/calendar.ics", calendarHandler.DentistFeed())
+	r.POST("/dentists/id/:id/calendar-token", calendarHandler.IssueDentistToken())
+
+	waitlists := r.Group("/waitlist")
+	{
+		waitlists.GET("", waitlistHandler.ReadAll())
+		waitlists.GET("/:id", waitlistHandler.ReadById())
+		waitlists.POST("", waitlistHandler.Join())
+		waitlists.DELETE("/:id", waitlistHandler.Leave())
+		waitlists.POST("/holds/:id/claim", waitlistHandler.Claim())
+		waitlists.POST("/holds/:id/decline", waitlistHandler.Decline())
+	}
+
+	sqlStorageImport := store.NewSQLStoreImport(sqlStore)
+	repoImport := importer.NewRepository(sqlStorageImport)
+	serviceImport := importer.NewService(repoImport, repoPatient, repoDentist, serviceAppointment)
+	serviceImport.AddListener(servicePatient)
+	serviceImport.AddListener(serviceDentist)
+	importHandler := handler.NewImportHandler(serviceImport)
+
+	r.POST("/import", importHandler.Import())
+
+	configReminder, err := reminderConfig()
+	if err != nil {
+		log.Fatalln(err)
 	}
+	notifier, err := newNotifier(configReminder.Channel)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	sqlStorageReminder := store.NewSQLStoreReminder(sqlStore)
+	repoReminder := reminder.NewRepository(sqlStorageReminder)
+	serviceReminder := reminder.NewService(repoReminder, serviceAppointment, repoPatient, notifier, configReminder)
+	reminderHandler := handler.NewReminderHandler(serviceReminder)
+
+	r.GET("/appointments/:id/reminders", reminderHandler.ReadByAppointment())
+	r.GET("/reminders/:token/confirm", reminderHandler.Page("confirm"))
+	r.POST("/reminders/:token/confirm", reminderHandler.Confirm())
+	r.GET("/reminders/:token/cancel", reminderHandler.Page("cancel"))
+	r.POST("/reminders/:token/cancel", reminderHandler.Cancel())
+
+	go runReminders(serviceReminder, time.Minute)
+
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := serviceWaitlist.ExpireHolds(); err != nil {
+				log.Println("waitlist:", err)
+			}
+		}
+	}()
+
+	go func() {
+		for now := range time.Tick(time.Minute) {
+			if _, err := serviceAppointment.FlagNoShows(now); err != nil {
+				log.Println("appointment:", err)
+			}
+		}
+	}()
 
 	r.Run(":8080")
-}
\ No newline at end of file
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"checkpoint2/internal/appointment"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// policyConfig reads the cancellation policy from the environment:
+//
+//	LATE_CANCELLATION_HOURS  notice a timely cancellation needs, default 24
+//	NO_SHOW_GRACE            time after the end before a no-show, default 30m
+//	NO_SHOW_WINDOW           how far back past the grace no-shows are flagged, default 24h
+//	RESTRICTION_THRESHOLD    strikes that restrict a patient, default 3
+//
+// Zero turns the matching rule off.
+func policyConfig() (appointment.Policy, error) {
+	hours, err := strconv.Atoi(getenv("LATE_CANCELLATION_HOURS", "24"))
+	if err != nil || hours < 0 {
+		return appointment.Policy{}, fmt.Errorf("invalid late cancellation hours %q", getenv("LATE_CANCELLATION_HOURS", ""))
+	}
+	grace, err := time.ParseDuration(getenv("NO_SHOW_GRACE", "30m"))
+	if err != nil || grace < 0 {
+		return appointment.Policy{}, fmt.Errorf("invalid no-show grace %q", getenv("NO_SHOW_GRACE", ""))
+	}
+	window, err := time.ParseDuration(getenv("NO_SHOW_WINDOW", "24h"))
+	if err != nil || window < 0 {
+		return appointment.Policy{}, fmt.Errorf("invalid no-show window %q", getenv("NO_SHOW_WINDOW", ""))
+	}
+	threshold, err := strconv.Atoi(getenv("RESTRICTION_THRESHOLD", "3"))
+	if err != nil || threshold < 0 {
+		return appointment.Policy{}, fmt.Errorf("invalid restriction threshold %q", getenv("RESTRICTION_THRESHOLD", ""))
+	}
+
+	return appointment.Policy{
+		LateCancellation: time.Duration(hours) * time.Hour,
+		NoShowAfter:      grace,
+		NoShowWindow:     window,
+		Threshold:        threshold,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"checkpoint2/internal/reminder"
+	"checkpoint2/pkg/notify"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// reminderConfig reads the reminder settings from the environment:
+//
+//	REMINDER_OFFSETS        durations before the appointment, default "48h,2h"
+//	REMINDER_CHANNEL        log, file, smtp, sms or webhook, default log
+//	REMINDER_CLAIM_TIMEOUT  how long a reminder may be left sending before
+//	                        another run sends it, default 10m
+//	PUBLIC_URL              base of the confirm and cancel links
+//
+// plus the settings of the chosen channel, see newNotifier.
+func reminderConfig() (reminder.Config, error) {
+	config := reminder.Config{
+		Channel: getenv("REMINDER_CHANNEL", "log"),
+		BaseURL: getenv("PUBLIC_URL", "http://localhost:8080"),
+	}
+	for _, value := range strings.Split(getenv("REMINDER_OFFSETS", "48h,2h"), ",") {
+		offset, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || offset <= 0 {
+			return reminder.Config{}, fmt.Errorf("invalid reminder offset %q", value)
+		}
+		config.Offsets = append(config.Offsets, offset)
+	}
+	claimTimeout, err := time.ParseDuration(getenv("REMINDER_CLAIM_TIMEOUT", "10m"))
+	if err != nil || claimTimeout <= 0 {
+		return reminder.Config{}, fmt.Errorf("invalid reminder claim timeout %q", os.Getenv("REMINDER_CLAIM_TIMEOUT"))
+	}
+	config.ClaimTimeout = claimTimeout
+	return config, nil
+}
+
+func newNotifier(channel string) (notify.Notifier, error) {
+	switch channel {
+	case "log":
+		return notify.NewLog(os.Stdout), nil
+	case "file":
+		f, err := os.OpenFile(getenv("REMINDER_FILE", "reminders.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		return notify.NewLog(f), nil
+	case "smtp":
+		return notify.NewSMTP(os.Getenv("SMTP_ADDR"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM")), nil
+	case "sms":
+		return notify.NewSMSGateway(os.Getenv("SMS_GATEWAY_URL"), os.Getenv("SMS_GATEWAY_KEY")), nil
+	case "webhook":
+		return notify.NewWebhook(os.Getenv("REMINDER_WEBHOOK_URL"), os.Getenv("REMINDER_WEBHOOK_SECRET")), nil
+	}
+	return nil, fmt.Errorf("unknown reminder channel %q", channel)
+}
+
+// runReminders sends the due reminders every interval until the process
+// exits.
+func runReminders(s reminder.Service, interval time.Duration) {
+	for now := range time.Tick(interval) {
+		if err := s.SendDue(now); err != nil {
+			log.Println("reminder:", err)
+		}
+	}
+}
+
+func getenv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func NewSQLStore() *sql.DB {
-	database, err := sql.Open("mysql", "root:root@tcp(localhost:3306)/checkpoint2")
+	database, err := sql.Open("mysql", "root:root@tcp(localhost:3306)/checkpoint2?parseTime=true")
 	if err != nil {
 		log.Fatalln(err)
 	}
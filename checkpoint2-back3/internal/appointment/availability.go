@@ -0,0 +1,170 @@
+package appointment
+
+import (
+	"checkpoint2/internal/domain"
+	"sort"
+	"time"
+)
+
+const clockLayout = "15:04"
+
+// clockOn returns the instant at which the "15:04" clock reads on day, in
+// day's location.
+func clockOn(day time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
+
+// workingPeriods expands a weekly schedule into the concrete working blocks
+// between from and to, clipped to that range and sorted by start.
+func workingPeriods(schedule []domain.WorkingHours, from time.Time, to time.Time) []domain.Slot {
+	var periods []domain.Slot
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, workingHours := range schedule {
+			if workingHours.Weekday != day.Weekday() {
+				continue
+			}
+			start, err := clockOn(day, workingHours.Start)
+			if err != nil {
+				continue
+			}
+			end, err := clockOn(day, workingHours.End)
+			if err != nil {
+				continue
+			}
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				periods = append(periods, domain.Slot{Start: start, End: end})
+			}
+		}
+	}
+
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Start.Before(periods[j].Start) })
+	return periods
+}
+
+// subtract removes every busy interval from periods.
+func subtract(periods []domain.Slot, busy []domain.Slot) []domain.Slot {
+	free := periods
+	for _, b := range busy {
+		var remaining []domain.Slot
+		for _, p := range free {
+			if !b.Start.Before(p.End) || !b.End.After(p.Start) {
+				remaining = append(remaining, p)
+				continue
+			}
+			if b.Start.After(p.Start) {
+				remaining = append(remaining, domain.Slot{Start: p.Start, End: b.Start})
+			}
+			if b.End.Before(p.End) {
+				remaining = append(remaining, domain.Slot{Start: b.End, End: p.End})
+			}
+		}
+		free = remaining
+	}
+	return free
+}
+
+// splitSlots cuts free periods into consecutive bookable slots of duration.
+func splitSlots(periods []domain.Slot, duration time.Duration) []domain.Slot {
+	slots := []domain.Slot{}
+	for _, p := range periods {
+		for start := p.Start; !start.Add(duration).After(p.End); start = start.Add(duration) {
+			slots = append(slots, domain.Slot{Start: start, End: start.Add(duration)})
+		}
+	}
+	return slots
+}
+
+// withinWorkingHours reports whether [start, end) fits entirely inside one
+// working block of the schedule.
+func withinWorkingHours(schedule []domain.WorkingHours, start time.Time, end time.Time) bool {
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for _, p := range workingPeriods(schedule, day, day.AddDate(0, 0, 1)) {
+		if !start.Before(p.Start) && !end.After(p.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// agendaDay builds the agenda of the day starting at dayStart.
+func agendaDay(schedule []domain.WorkingHours, timeOffs []domain.TimeOff, appointments []domain.Appointment, dayStart time.Time, dayEnd time.Time) domain.AgendaDay {
+	day := domain.AgendaDay{
+		Date:         dayStart.Format("2006-01-02"),
+		WorkingHours: workingPeriods(schedule, dayStart, dayEnd),
+		Entries:      []domain.AgendaEntry{},
+	}
+	if day.WorkingHours == nil {
+		day.WorkingHours = []domain.Slot{}
+	}
+
+	var busy []domain.Slot
+	for i := range appointments {
+		a := appointments[i]
+		if !a.Start.Before(dayEnd) || !a.End.After(dayStart) {
+			continue
+		}
+		busy = append(busy, domain.Slot{Start: a.Start, End: a.End})
+		day.Entries = append(day.Entries, domain.AgendaEntry{
+			Kind:        domain.AgendaAppointment,
+			Start:       a.Start.In(dayStart.Location()),
+			End:         a.End.In(dayStart.Location()),
+			Appointment: &a,
+		})
+	}
+	for _, timeOff := range timeOffs {
+		if !timeOff.Start.Before(dayEnd) || !timeOff.End.After(dayStart) {
+			continue
+		}
+		busy = append(busy, domain.Slot{Start: timeOff.Start, End: timeOff.End})
+		day.Entries = append(day.Entries, domain.AgendaEntry{
+			Kind:   domain.AgendaTimeOff,
+			Start:  timeOff.Start.In(dayStart.Location()),
+			End:    timeOff.End.In(dayStart.Location()),
+			Reason: timeOff.Reason,
+		})
+	}
+	for _, gap := range subtract(day.WorkingHours, busy) {
+		day.Entries = append(day.Entries, domain.AgendaEntry{
+			Kind:  domain.AgendaGap,
+			Start: gap.Start,
+			End:   gap.End,
+		})
+	}
+
+	sort.SliceStable(day.Entries, func(i, j int) bool { return day.Entries[i].Start.Before(day.Entries[j].Start) })
+	return day
+}
+
+// assignRooms keeps the slots in which one of rooms is free, setting the
+// slot's room to the first of them.
+func assignRooms(slots []domain.Slot, rooms []domain.Room, busy map[int][]domain.Slot) []domain.Slot {
+	var assigned []domain.Slot
+	for _, slot := range slots {
+		for _, room := range rooms {
+			free := true
+			for _, period := range busy[room.Id] {
+				if period.Start.Before(slot.End) && slot.Start.Before(period.End) {
+					free = false
+					break
+				}
+			}
+			if free {
+				slot.RoomId = room.Id
+				assigned = append(assigned, slot)
+				break
+			}
+		}
+	}
+	return assigned
+}
@@ -0,0 +1,106 @@
+package appointment
+
+import (
+	"checkpoint2/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+// mondays works 8 to 12 and 13 to 17 on Mondays only.
+var mondays = []domain.WorkingHours{
+	{Weekday: time.Monday, Start: "08:00", End: "12:00"},
+	{Weekday: time.Monday, Start: "13:00", End: "17:00"},
+}
+
+func TestAvailability(t *testing.T) {
+	dentists := fakeDentists{
+		dentists: map[int]domain.Dentist{1: {Id: 1, Schedule: mondays}},
+		timeOffs: []domain.TimeOff{{DentistId: 1, Start: at(t, "2024-03-04", "14:00"), End: at(t, "2024-03-04", "15:30"), Reason: "course"}},
+	}
+	booked := domain.Appointment{
+		Id:      1,
+		Patient: domain.Patient{Id: 1},
+		Dentist: domain.Dentist{Id: 1},
+		Start:   at(t, "2024-03-04", "09:00"),
+		End:     at(t, "2024-03-04", "10:00"),
+	}
+	s := newTestService(newFakeAppointments(booked), dentists, fakeRooms{})
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		duration time.Duration
+		want     []string
+	}{
+		{"whole monday", at(t, "2024-03-04", "00:00"), at(t, "2024-03-05", "00:00"), time.Hour,
+			[]string{"08:00", "10:00", "11:00", "13:00", "15:30"}},
+		{"half hours", at(t, "2024-03-04", "11:00"), at(t, "2024-03-04", "14:30"), 30 * time.Minute,
+			[]string{"11:00", "11:30", "13:00", "13:30"}},
+		{"from inside a block", at(t, "2024-03-04", "10:30"), at(t, "2024-03-04", "13:00"), time.Hour,
+			[]string{"10:30"}},
+		{"day off", at(t, "2024-03-05", "00:00"), at(t, "2024-03-06", "00:00"), time.Hour, nil},
+	}
+	for _, tt := range tests {
+		slots, err := s.Availability(1, tt.from, tt.to, tt.duration, nil, 0)
+		if err != nil {
+			t.Errorf("%s: Availability() error = %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, slot := range slots {
+			if slot.End.Sub(slot.Start) != tt.duration {
+				t.Errorf("%s: Availability() slot %v lasts %v, want %v", tt.name, slot.Start, slot.End.Sub(slot.Start), tt.duration)
+			}
+			got = append(got, slot.Start.Format("15:04"))
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Availability() = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: Availability() = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCheckSlotWorkingHours(t *testing.T) {
+	dentists := fakeDentists{
+		dentists: map[int]domain.Dentist{1: {Id: 1, Schedule: mondays}},
+		timeOffs: []domain.TimeOff{{DentistId: 1, Start: at(t, "2024-03-04", "14:00"), End: at(t, "2024-03-04", "15:30"), Reason: "course"}},
+	}
+	s := newTestService(newFakeAppointments(), dentists, fakeRooms{})
+
+	tests := []struct {
+		dentist    int
+		day        string
+		start, end string
+		wantErr    bool
+	}{
+		{1, "2024-03-04", "08:00", "09:00", false},
+		{1, "2024-03-04", "11:00", "12:00", false},
+		{1, "2024-03-04", "07:30", "08:30", true},
+		{1, "2024-03-04", "11:30", "12:30", true},
+		{1, "2024-03-04", "11:30", "13:30", true},
+		{1, "2024-03-04", "13:00", "14:00", false},
+		{1, "2024-03-04", "13:30", "14:30", true},
+		{1, "2024-03-04", "15:30", "16:30", false},
+		{1, "2024-03-05", "09:00", "10:00", true},
+		{2, "2024-03-05", "22:00", "23:00", false},
+	}
+	for _, tt := range tests {
+		_, err := s.CheckSlot(domain.Appointment{
+			Patient: domain.Patient{Id: 1},
+			Dentist: domain.Dentist{Id: tt.dentist},
+			Start:   at(t, tt.day, tt.start),
+			End:     at(t, tt.day, tt.end),
+		}, nil)
+		var unavailable *UnavailableError
+		if errors.As(err, &unavailable) != tt.wantErr || !tt.wantErr && err != nil {
+			t.Errorf("CheckSlot(dentist %d on %s %s-%s) error = %v, want unavailable %v", tt.dentist, tt.day, tt.start, tt.end, err, tt.wantErr)
+		}
+	}
+}
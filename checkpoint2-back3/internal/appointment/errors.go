@@ -0,0 +1,71 @@
+package appointment
+
+import (
+	"checkpoint2/internal/domain"
+	"fmt"
+	"time"
+)
+
+// ConflictError is returned when an appointment would overlap another one
+// booked for the same dentist or the same patient.
+type ConflictError struct {
+	Conflicting domain.Appointment
+	Reason      string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s already has appointment %d from %s to %s", e.Reason, e.Conflicting.Id,
+		e.Conflicting.Start.Format(time.RFC3339), e.Conflicting.End.Format(time.RFC3339))
+}
+
+// UnavailableError is returned when an appointment falls outside the
+// dentist's working hours, inside one of their time-off blocks or on a slot
+// held for someone on the waitlist.
+type UnavailableError struct {
+	Reason string
+}
+
+func (e *UnavailableError) Error() string {
+	return e.Reason
+}
+
+// TransitionError is returned when an appointment can't move from its
+// current status to the requested one.
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("appointment can't move from %s to %s", e.From, e.To)
+}
+
+// RestrictedError is returned when booking a patient restricted for late
+// cancellations or no-shows without a staff override.
+type RestrictedError struct {
+	PatientId int
+}
+
+func (e *RestrictedError) Error() string {
+	return fmt.Sprintf("patient %d is restricted and can only be booked with a staff override", e.PatientId)
+}
+
+// GuardianRequiredError is returned when booking a minor who has no guardian
+// on record.
+type GuardianRequiredError struct {
+	PatientId int
+}
+
+func (e *GuardianRequiredError) Error() string {
+	return fmt.Sprintf("patient %d is a minor and needs a guardian on record to be booked", e.PatientId)
+}
+
+// NotInSeriesError is returned when a change meant for the following or all
+// occurrences of a series targets an appointment that has none.
+type NotInSeriesError struct {
+	AppointmentId int
+}
+
+func (e *NotInSeriesError) Error() string {
+	return fmt.Sprintf("appointment %d is not part of a series", e.AppointmentId)
+}
@@ -0,0 +1,222 @@
+package appointment
+
+import (
+	"checkpoint2/internal/appointmenttype"
+	"checkpoint2/internal/clinic"
+	"checkpoint2/internal/closure"
+	"checkpoint2/internal/dentist"
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/patient"
+	"checkpoint2/internal/room"
+	"errors"
+	"sort"
+	"time"
+)
+
+// The fakes keep their records in memory and answer the lookups the service
+// makes the way the SQL stores do. Each embeds its interface, so a method
+// the service is not expected to call panics instead of passing silently.
+
+type fakeAppointments struct {
+	Repository
+	appointments map[int]domain.Appointment
+	series       map[int]domain.AppointmentSeries
+	holds        []fakeHold
+	history      []domain.AppointmentChange
+}
+
+// fakeHold is a pending waitlist hold for PatientId.
+type fakeHold struct {
+	domain.SlotHold
+	PatientId int
+}
+
+func newFakeAppointments(appointments ...domain.Appointment) *fakeAppointments {
+	r := &fakeAppointments{appointments: map[int]domain.Appointment{}, series: map[int]domain.AppointmentSeries{}}
+	for _, a := range appointments {
+		r.appointments[a.Id] = a
+	}
+	return r
+}
+
+func (r *fakeAppointments) ReadById(id int) (domain.Appointment, error) {
+	a, ok := r.appointments[id]
+	if !ok {
+		return domain.Appointment{}, errors.New("appointment not found")
+	}
+	return a, nil
+}
+
+// overlapping lists the open appointments matching keep that overlap the
+// period, by start.
+func (r *fakeAppointments) overlapping(start time.Time, end time.Time, keep func(domain.Appointment) bool) []domain.Appointment {
+	found := []domain.Appointment{}
+	for _, a := range r.appointments {
+		if keep(a) && a.Status != domain.StatusCancelled && a.Start.Before(end) && a.End.After(start) {
+			found = append(found, a)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Start.Before(found[j].Start) })
+	return found
+}
+
+func (r *fakeAppointments) ReadConflictsByDentist(idDentist int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	return r.overlapping(start, end, func(a domain.Appointment) bool { return a.Dentist.Id == idDentist }), nil
+}
+
+func (r *fakeAppointments) ReadConflictsByPatient(idPatient int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	return r.overlapping(start, end, func(a domain.Appointment) bool { return a.Patient.Id == idPatient }), nil
+}
+
+func (r *fakeAppointments) ReadConflictsByRoom(idRoom int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	return r.overlapping(start, end, func(a domain.Appointment) bool { return a.RoomId == idRoom }), nil
+}
+
+func (r *fakeAppointments) ReadByDentistAndPeriod(idDentist int, from time.Time, to time.Time) ([]domain.Appointment, error) {
+	return r.ReadConflictsByDentist(idDentist, from, to)
+}
+
+func (r *fakeAppointments) ReadConflictingHolds(idDentist int, idPatient int, start time.Time, end time.Time) ([]domain.SlotHold, error) {
+	holds := []domain.SlotHold{}
+	for _, hold := range r.holds {
+		if hold.DentistId == idDentist && hold.PatientId != idPatient && hold.Start.Before(end) && hold.End.After(start) {
+			holds = append(holds, hold.SlotHold)
+		}
+	}
+	return holds, nil
+}
+
+func (r *fakeAppointments) Update(id int, a domain.Appointment) (domain.Appointment, error) {
+	a.Id = id
+	r.appointments[id] = a
+	return a, nil
+}
+
+func (r *fakeAppointments) CreateHistory(change domain.AppointmentChange) error {
+	r.history = append(r.history, change)
+	return nil
+}
+
+func (r *fakeAppointments) ReadSeries(id int) (domain.AppointmentSeries, error) {
+	series, ok := r.series[id]
+	if !ok {
+		return domain.AppointmentSeries{}, errors.New("series not found")
+	}
+	series.Appointments = []domain.Appointment{}
+	for _, a := range r.appointments {
+		if a.SeriesId == id {
+			series.Appointments = append(series.Appointments, a)
+		}
+	}
+	sort.Slice(series.Appointments, func(i, j int) bool {
+		return series.Appointments[i].Start.Before(series.Appointments[j].Start)
+	})
+	return series, nil
+}
+
+func (r *fakeAppointments) PatchSeries(series domain.AppointmentSeries, history []domain.AppointmentChange) ([]domain.Appointment, error) {
+	if series.Id != 0 {
+		stored := series
+		stored.Appointments = nil
+		r.series[series.Id] = stored
+	}
+	for _, a := range series.Appointments {
+		r.appointments[a.Id] = a
+	}
+	r.history = append(r.history, history...)
+	return series.Appointments, nil
+}
+
+type fakePatients struct {
+	patient.Repository
+}
+
+func (fakePatients) ReadById(id int) (domain.Patient, error) {
+	return domain.Patient{Id: id}, nil
+}
+
+type fakeDentists struct {
+	dentist.Repository
+	dentists map[int]domain.Dentist
+	timeOffs []domain.TimeOff
+}
+
+func (r fakeDentists) ReadById(id int) (domain.Dentist, error) {
+	d, ok := r.dentists[id]
+	if !ok {
+		return domain.Dentist{Id: id}, nil
+	}
+	return d, nil
+}
+
+func (r fakeDentists) ReadTimeOff(id int, from time.Time, to time.Time) ([]domain.TimeOff, error) {
+	var timeOffs []domain.TimeOff
+	for _, timeOff := range r.timeOffs {
+		if timeOff.DentistId == id && timeOff.Start.Before(to) && timeOff.End.After(from) {
+			timeOffs = append(timeOffs, timeOff)
+		}
+	}
+	return timeOffs, nil
+}
+
+type fakeRooms struct {
+	room.Repository
+	rooms []domain.Room
+}
+
+func (r fakeRooms) ReadById(id int) (domain.Room, error) {
+	for _, room := range r.rooms {
+		if room.Id == id {
+			return room, nil
+		}
+	}
+	return domain.Room{}, errors.New("room not found")
+}
+
+func (r fakeRooms) ReadAll() ([]domain.Room, error) {
+	return r.rooms, nil
+}
+
+func (r fakeRooms) ReadByClinic(idClinic int) ([]domain.Room, error) {
+	rooms := []domain.Room{}
+	for _, room := range r.rooms {
+		if room.ClinicId == idClinic {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms, nil
+}
+
+type fakeClinics struct {
+	clinic.Repository
+}
+
+func (fakeClinics) ReadById(id int) (domain.Clinic, error) {
+	return domain.Clinic{Id: id}, nil
+}
+
+type fakeClosures struct {
+	closure.Repository
+}
+
+func (fakeClosures) ReadBetween(from time.Time, to time.Time, idClinic int) ([]domain.Closure, error) {
+	return []domain.Closure{}, nil
+}
+
+type fakeTypes struct {
+	appointmenttype.Repository
+}
+
+// newTestService builds a service over r, the dentists and the rooms, with
+// no clinics, closures or appointment types on record.
+func newTestService(r *fakeAppointments, dentists fakeDentists, rooms fakeRooms) *service {
+	return &service{
+		r:        r,
+		patients: fakePatients{},
+		dentists: dentists,
+		rooms:    rooms,
+		types:    fakeTypes{},
+		clinics:  fakeClinics{},
+		closures: fakeClosures{},
+	}
+}
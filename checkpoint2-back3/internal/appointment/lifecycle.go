@@ -0,0 +1,25 @@
+package appointment
+
+import "checkpoint2/internal/domain"
+
+// transitions lists, for each status, the statuses an appointment may move
+// to next. Completed, cancelled and no-show are terminal.
+var transitions = map[string][]string{
+	domain.StatusScheduled:  {domain.StatusConfirmed, domain.StatusCancelled, domain.StatusNoShow},
+	domain.StatusConfirmed:  {domain.StatusCheckedIn, domain.StatusCancelled, domain.StatusNoShow},
+	domain.StatusCheckedIn:  {domain.StatusInProgress, domain.StatusCancelled},
+	domain.StatusInProgress: {domain.StatusCompleted},
+}
+
+func canTransition(from string, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func isTerminal(status string) bool {
+	return len(transitions[status]) == 0
+}
@@ -0,0 +1,90 @@
+package appointment
+
+import (
+	"checkpoint2/internal/domain"
+	"time"
+)
+
+// Policy sets how cancellations and missed appointments count against a
+// patient.
+type Policy struct {
+	// LateCancellation is how long before the start a cancellation still
+	// counts as timely. Zero never counts a cancellation as late.
+	LateCancellation time.Duration
+	// NoShowAfter is how long after its end a scheduled or confirmed
+	// appointment is flagged as a no-show. Zero turns the flagging off.
+	NoShowAfter time.Duration
+	// NoShowWindow is how far back, past NoShowAfter, appointments are still
+	// flagged, so that old records left open aren't all turned into no-shows
+	// at once. Zero doesn't limit it.
+	NoShowWindow time.Duration
+	// Threshold is how many late cancellations and no-shows restrict a
+	// patient to bookings with a staff override. Zero never restricts.
+	Threshold int
+}
+
+// Cancel cancels the appointment and records why and by whom. A cancellation
+// by the patient inside the policy's late window counts against them.
+func (s *service) Cancel(id int, cancellation domain.Cancellation) (domain.Appointment, error) {
+	appointment, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if !canTransition(appointment.Status, domain.StatusCancelled) {
+		return domain.Appointment{}, &TransitionError{From: appointment.Status, To: domain.StatusCancelled}
+	}
+
+	now := time.Now()
+	cancellation.CancelledAt = now
+	cancellation.Late = cancellation.CancelledBy == domain.CancelledByPatient &&
+		s.policy.LateCancellation > 0 && now.After(appointment.Start.Add(-s.policy.LateCancellation))
+
+	change := domain.StatusChange{
+		AppointmentId: id,
+		From:          appointment.Status,
+		To:            domain.StatusCancelled,
+		ChangedBy:     cancellation.CancelledBy,
+		ChangedAt:     now,
+	}
+	var strike *domain.Strike
+	if cancellation.Late {
+		strike = &domain.Strike{PatientId: appointment.Patient.Id, LateCancellations: 1, Threshold: s.policy.Threshold}
+	}
+	if err := s.r.Cancel(change, cancellation, strike); err != nil {
+		return domain.Appointment{}, err
+	}
+
+	s.releaseSlot(appointment)
+	appointment.Status = domain.StatusCancelled
+	appointment.Cancellation = &cancellation
+	return appointment, nil
+}
+
+// FlagNoShows marks as no-shows the appointments still scheduled or confirmed
+// once the policy's grace period after their end has passed, within the
+// policy's window.
+func (s *service) FlagNoShows(now time.Time) ([]domain.Appointment, error) {
+	if s.policy.NoShowAfter == 0 {
+		return []domain.Appointment{}, nil
+	}
+
+	before := now.Add(-s.policy.NoShowAfter)
+	var after time.Time
+	if s.policy.NoShowWindow > 0 {
+		after = before.Add(-s.policy.NoShowWindow)
+	}
+	overdue, err := s.r.ReadOverdue(after, before)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+
+	var flagged []domain.Appointment
+	for _, appointment := range overdue {
+		appointment, err := s.Transition(appointment.Id, domain.StatusNoShow, "system")
+		if err != nil {
+			return flagged, err
+		}
+		flagged = append(flagged, appointment)
+	}
+	return flagged, nil
+}
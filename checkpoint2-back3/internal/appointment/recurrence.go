@@ -0,0 +1,124 @@
+package appointment
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/timezone"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ScopeSingle    = "single"
+	ScopeFollowing = "following"
+	ScopeAll       = "all"
+
+	maxOccurrences = 104
+	rruleUntil     = "20060102T150405Z"
+)
+
+// NewRecurrence validates the parts of a recurrence rule.
+func NewRecurrence(frequency string, interval int, count int, until time.Time) (domain.Recurrence, error) {
+	frequency = strings.ToLower(frequency)
+	if frequency != domain.FrequencyWeekly && frequency != domain.FrequencyMonthly {
+		return domain.Recurrence{}, errors.New("frequency must be weekly or monthly")
+	}
+	if interval == 0 {
+		interval = 1
+	}
+	if interval < 0 {
+		return domain.Recurrence{}, errors.New("interval must be positive")
+	}
+	if count < 0 || count > maxOccurrences {
+		return domain.Recurrence{}, fmt.Errorf("count must be between 1 and %d", maxOccurrences)
+	}
+	if count == 0 && until.IsZero() {
+		return domain.Recurrence{}, errors.New("either count or until is required")
+	}
+	return domain.Recurrence{Frequency: frequency, Interval: interval, Count: count, Until: until}, nil
+}
+
+// ParseRRule reads an RRULE such as "FREQ=WEEKLY;INTERVAL=4;COUNT=10".
+// Only FREQ, INTERVAL, COUNT and UNTIL are supported.
+func ParseRRule(rule string) (domain.Recurrence, error) {
+	var frequency string
+	var interval, count int
+	var until time.Time
+
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return domain.Recurrence{}, fmt.Errorf("invalid rrule part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			frequency = strings.ToLower(value)
+		case "INTERVAL":
+			interval, err = strconv.Atoi(value)
+		case "COUNT":
+			count, err = strconv.Atoi(value)
+		case "UNTIL":
+			until, err = time.Parse(rruleUntil, value)
+			if err != nil {
+				until, err = timezone.Parse("20060102", value)
+				until = until.Add(24*time.Hour - time.Second)
+			}
+		default:
+			return domain.Recurrence{}, fmt.Errorf("unsupported rrule part %s", key)
+		}
+		if err != nil {
+			return domain.Recurrence{}, fmt.Errorf("invalid rrule %s", key)
+		}
+	}
+
+	return NewRecurrence(frequency, interval, count, until)
+}
+
+// FormatRRule writes r back as an RRULE.
+func FormatRRule(r domain.Recurrence) string {
+	parts := []string{
+		"FREQ=" + strings.ToUpper(r.Frequency),
+		"INTERVAL=" + strconv.Itoa(r.Interval),
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(rruleUntil))
+	}
+	return strings.Join(parts, ";")
+}
+
+// occurrences expands r from the first slot. Monthly occurrences that would
+// fall on a day the month doesn't have (e.g. the 31st) are skipped, as in
+// RFC 5545.
+func occurrences(first domain.Slot, r domain.Recurrence) ([]domain.Slot, error) {
+	duration := first.End.Sub(first.Start)
+	var slots []domain.Slot
+	for i := 0; ; i++ {
+		var start time.Time
+		if r.Frequency == domain.FrequencyWeekly {
+			start = first.Start.AddDate(0, 0, 7*r.Interval*i)
+		} else {
+			start = first.Start.AddDate(0, r.Interval*i, 0)
+			if start.Day() != first.Start.Day() {
+				continue
+			}
+		}
+		if !r.Until.IsZero() && start.After(r.Until) {
+			break
+		}
+		if len(slots) == maxOccurrences {
+			return nil, fmt.Errorf("series can't have more than %d occurrences", maxOccurrences)
+		}
+		slots = append(slots, domain.Slot{Start: start, End: start.Add(duration)})
+		if r.Count > 0 && len(slots) == r.Count {
+			break
+		}
+	}
+	return slots, nil
+}
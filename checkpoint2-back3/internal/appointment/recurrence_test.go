@@ -0,0 +1,88 @@
+package appointment
+
+import (
+	"checkpoint2/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	day := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name    string
+		first   string
+		r       domain.Recurrence
+		want    []string
+		wantErr bool
+	}{
+		{"weekly count", "2024-01-01 10:00", domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 1, Count: 3},
+			[]string{"2024-01-01", "2024-01-08", "2024-01-15"}, false},
+		{"fortnightly until, inclusive", "2024-01-01 10:00", domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 2, Until: day("2024-01-29 10:00")},
+			[]string{"2024-01-01", "2024-01-15", "2024-01-29"}, false},
+		{"until before count", "2024-01-01 10:00", domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 1, Count: 10, Until: day("2024-01-15 23:59")},
+			[]string{"2024-01-01", "2024-01-08", "2024-01-15"}, false},
+		{"count before until", "2024-01-01 10:00", domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 1, Count: 2, Until: day("2024-12-31 23:59")},
+			[]string{"2024-01-01", "2024-01-08"}, false},
+		{"monthly skips short months", "2024-01-31 10:00", domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 1, Count: 3},
+			[]string{"2024-01-31", "2024-03-31", "2024-05-31"}, false},
+		{"monthly until", "2024-01-31 10:00", domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 1, Until: day("2024-04-30 23:59")},
+			[]string{"2024-01-31", "2024-03-31"}, false},
+		{"every other month", "2024-01-15 10:00", domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 2, Count: 3},
+			[]string{"2024-01-15", "2024-03-15", "2024-05-15"}, false},
+		{"leap day", "2024-02-29 10:00", domain.Recurrence{Frequency: domain.FrequencyMonthly, Interval: 12, Count: 2},
+			[]string{"2024-02-29", "2028-02-29"}, false},
+		{"too many", "2024-01-01 10:00", domain.Recurrence{Frequency: domain.FrequencyWeekly, Interval: 1, Until: day("2030-01-01 00:00")},
+			nil, true},
+	}
+	for _, tt := range tests {
+		first := domain.Slot{Start: day(tt.first), End: day(tt.first).Add(45 * time.Minute)}
+		slots, err := occurrences(first, tt.r)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: occurrences() error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if len(slots) != len(tt.want) {
+			t.Errorf("%s: occurrences() gave %d slots, want %v", tt.name, len(slots), tt.want)
+			continue
+		}
+		for i, slot := range slots {
+			if got := slot.Start.Format("2006-01-02"); got != tt.want[i] || slot.Start.Format("15:04") != "10:00" || slot.End.Sub(slot.Start) != 45*time.Minute {
+				t.Errorf("%s: occurrence %d = %v to %v, want %s 10:00 for 45m", tt.name, i, slot.Start, slot.End, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestRRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{"FREQ=WEEKLY;INTERVAL=2;COUNT=10", "FREQ=WEEKLY;INTERVAL=2;COUNT=10", false},
+		{"RRULE:FREQ=MONTHLY;UNTIL=20240630T235959Z", "FREQ=MONTHLY;INTERVAL=1;UNTIL=20240630T235959Z", false},
+		{"FREQ=weekly;COUNT=3", "FREQ=WEEKLY;INTERVAL=1;COUNT=3", false},
+		{"FREQ=DAILY;COUNT=3", "", true},
+		{"FREQ=WEEKLY", "", true},
+		{"FREQ=WEEKLY;COUNT=105", "", true},
+		{"FREQ=WEEKLY;BYDAY=MO;COUNT=3", "", true},
+		{"FREQ=WEEKLY;UNTIL=tomorrow", "", true},
+	}
+	for _, tt := range tests {
+		r, err := ParseRRule(tt.rule)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRRule(%q) error = %v, want error %v", tt.rule, err, tt.wantErr)
+			continue
+		}
+		if err == nil && FormatRRule(r) != tt.want {
+			t.Errorf("FormatRRule(ParseRRule(%q)) = %q, want %q", tt.rule, FormatRRule(r), tt.want)
+		}
+	}
+}
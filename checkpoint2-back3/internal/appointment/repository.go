@@ -3,16 +3,32 @@ package appointment
 import (
 	"checkpoint2/internal/domain"
 	"checkpoint2/pkg/store"
+	"time"
 )
 
 type Repository interface {
 	ReadById(id int) (domain.Appointment, error)
 	ReadByRg(rg string) ([]domain.Appointment, error)
+	ReadConflictsByDentist(idDentist int, start time.Time, end time.Time) ([]domain.Appointment, error)
+	ReadConflictsByPatient(idPatient int, start time.Time, end time.Time) ([]domain.Appointment, error)
+	ReadConflictsByRoom(idRoom int, start time.Time, end time.Time) ([]domain.Appointment, error)
+	ReadConflictingHolds(idDentist int, idPatient int, start time.Time, end time.Time) ([]domain.SlotHold, error)
 	CreateById(appointment domain.Appointment, idPatient int, idDentist int) (domain.Appointment, error)
 	CreateByRgAndRegistration(appointment domain.Appointment, rgPatient string, registrationDentist string) (domain.Appointment, error)
 	Update(id int, appointment domain.Appointment) (domain.Appointment, error)
 	Patch(id int, appointment domain.Appointment) (domain.Appointment, error)
-	Delete(id int) error
+	UpdateStatus(change domain.StatusChange, strike *domain.Strike) error
+	Cancel(change domain.StatusChange, cancellation domain.Cancellation, strike *domain.Strike) error
+	ReadOverdue(after time.Time, before time.Time) ([]domain.Appointment, error)
+	ReadStatusHistory(id int) ([]domain.StatusChange, error)
+	ReadSeries(id int) (domain.AppointmentSeries, error)
+	CreateSeries(series domain.AppointmentSeries) (domain.AppointmentSeries, error)
+	PatchSeries(series domain.AppointmentSeries, history []domain.AppointmentChange) ([]domain.Appointment, error)
+	CreateHistory(change domain.AppointmentChange) error
+	ReadHistory(id int) ([]domain.AppointmentChange, error)
+	Search(filter domain.AppointmentFilter) ([]domain.Appointment, int, error)
+	ReadByDentistAndPeriod(idDentist int, from time.Time, to time.Time) ([]domain.Appointment, error)
+	ReportByClinic(from time.Time, to time.Time, idClinic int) ([]domain.ClinicReport, error)
 }
 
 type repository struct {
@@ -39,6 +55,38 @@ func (r *repository) ReadByRg(rg string) ([]domain.Appointment, error) {
 	return appointments, nil
 }
 
+func (r *repository) ReadConflictsByDentist(idDentist int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	appointments, err := r.storage.ReadConflictsByDentist(idDentist, start, end)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	return appointments, nil
+}
+
+func (r *repository) ReadConflictsByPatient(idPatient int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	appointments, err := r.storage.ReadConflictsByPatient(idPatient, start, end)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	return appointments, nil
+}
+
+func (r *repository) ReadConflictsByRoom(idRoom int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	appointments, err := r.storage.ReadConflictsByRoom(idRoom, start, end)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	return appointments, nil
+}
+
+func (r *repository) ReadConflictingHolds(idDentist int, idPatient int, start time.Time, end time.Time) ([]domain.SlotHold, error) {
+	holds, err := r.storage.ReadConflictingHolds(idDentist, idPatient, start, end)
+	if err != nil {
+		return []domain.SlotHold{}, err
+	}
+	return holds, nil
+}
+
 func (r *repository) CreateById(a domain.Appointment, idPatient int, idDentist int) (domain.Appointment, error) {
 	appointment, err := r.storage.CreateById(a, idPatient, idDentist)
 	if err != nil {
@@ -71,10 +119,98 @@ func (r *repository) Patch(id int, a domain.Appointment) (domain.Appointment, er
 	return appointment, nil
 }
 
-func (r *repository) Delete(id int) error {
-	err := r.storage.Delete(id)
+func (r *repository) UpdateStatus(change domain.StatusChange, strike *domain.Strike) error {
+	err := r.storage.UpdateStatus(change, strike)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) Cancel(change domain.StatusChange, cancellation domain.Cancellation, strike *domain.Strike) error {
+	err := r.storage.Cancel(change, cancellation, strike)
 	if err != nil {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *repository) ReadOverdue(after time.Time, before time.Time) ([]domain.Appointment, error) {
+	appointments, err := r.storage.ReadOverdue(after, before)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	return appointments, nil
+}
+
+func (r *repository) ReadStatusHistory(id int) ([]domain.StatusChange, error) {
+	changes, err := r.storage.ReadStatusHistory(id)
+	if err != nil {
+		return []domain.StatusChange{}, err
+	}
+	return changes, nil
+}
+
+func (r *repository) ReadSeries(id int) (domain.AppointmentSeries, error) {
+	series, err := r.storage.ReadSeries(id)
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+	return series, nil
+}
+
+func (r *repository) CreateSeries(s domain.AppointmentSeries) (domain.AppointmentSeries, error) {
+	series, err := r.storage.CreateSeries(s)
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+	return series, nil
+}
+
+func (r *repository) PatchSeries(series domain.AppointmentSeries, history []domain.AppointmentChange) ([]domain.Appointment, error) {
+	appointments, err := r.storage.PatchSeries(series, history)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	return appointments, nil
+}
+
+func (r *repository) CreateHistory(change domain.AppointmentChange) error {
+	err := r.storage.CreateHistory(change)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ReadHistory(id int) ([]domain.AppointmentChange, error) {
+	changes, err := r.storage.ReadHistory(id)
+	if err != nil {
+		return []domain.AppointmentChange{}, err
+	}
+	return changes, nil
+}
+
+func (r *repository) Search(filter domain.AppointmentFilter) ([]domain.Appointment, int, error) {
+	appointments, total, err := r.storage.Search(filter)
+	if err != nil {
+		return []domain.Appointment{}, 0, err
+	}
+	return appointments, total, nil
+}
+
+func (r *repository) ReadByDentistAndPeriod(idDentist int, from time.Time, to time.Time) ([]domain.Appointment, error) {
+	appointments, err := r.storage.ReadByDentistAndPeriod(idDentist, from, to)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	return appointments, nil
+}
+
+func (r *repository) ReportByClinic(from time.Time, to time.Time, idClinic int) ([]domain.ClinicReport, error) {
+	reports, err := r.storage.ReportByClinic(from, to, idClinic)
+	if err != nil {
+		return []domain.ClinicReport{}, err
+	}
+	return reports, nil
+}
@@ -1,25 +1,71 @@
 package appointment
 
 import (
+	"checkpoint2/internal/appointmenttype"
+	"checkpoint2/internal/clinic"
+	"checkpoint2/internal/closure"
+	"checkpoint2/internal/dentist"
 	"checkpoint2/internal/domain"
+	"checkpoint2/internal/patient"
+	"checkpoint2/internal/room"
+	"checkpoint2/pkg/document"
+	"checkpoint2/pkg/timezone"
+	"errors"
+	"fmt"
+	"time"
 )
 
 type Service interface {
 	ReadById(id int) (domain.Appointment, error)
-	ReadByRg(rg string) ([]domain.Appointment, error)
+	ReadByRg(rg string, idClinic int) ([]domain.Appointment, error)
 	CreateById(appointment domain.Appointment, idPatient int, idDentist int) (domain.Appointment, error)
+	Prepare(appointment domain.Appointment, idPatient int, idDentist int) (domain.Appointment, error)
 	CreateByRgAndRegistration(appointment domain.Appointment, rgPatient string, registrationDentist string) (domain.Appointment, error)
 	Update(id int, appointment domain.Appointment) (domain.Appointment, error)
 	Patch(id int, appointment domain.Appointment) (domain.Appointment, error)
-	Delete(id int) error
+	Cancel(id int, cancellation domain.Cancellation) (domain.Appointment, error)
+	Availability(idDentist int, from time.Time, to time.Time, duration time.Duration, equipment []string, idClinic int) ([]domain.Slot, error)
+	Transition(id int, status string, changedBy string) (domain.Appointment, error)
+	ReadStatusHistory(id int) ([]domain.StatusChange, error)
+	ReadSeries(id int) (domain.AppointmentSeries, error)
+	CreateSeries(appointment domain.Appointment, idPatient int, idDentist int, recurrence domain.Recurrence) (domain.AppointmentSeries, error)
+	PatchSeries(id int, appointment domain.Appointment, scope string) ([]domain.Appointment, error)
+	CancelSeries(id int, scope string, cancellation domain.Cancellation) ([]domain.Appointment, error)
+	FlagNoShows(now time.Time) ([]domain.Appointment, error)
+	AddSlotListener(listener SlotListener)
+	Reschedule(id int, appointment domain.Appointment, changedBy string, reason string) (domain.Appointment, error)
+	ReadHistory(id int) ([]domain.AppointmentChange, error)
+	Search(filter domain.AppointmentFilter) ([]domain.Appointment, int, error)
+	Agenda(idDentist int, from time.Time, to time.Time, idClinic int) (domain.Agenda, error)
+	CheckSlot(appointment domain.Appointment, unsaved []domain.Appointment) (domain.Appointment, error)
+	ReportByClinic(from time.Time, to time.Time, idClinic int) ([]domain.ClinicReport, error)
+	Location(idDentist int, idClinic int) (*time.Location, error)
+}
+
+// SlotListener is notified when a booked slot becomes free again because its
+// appointment was cancelled or moved elsewhere.
+type SlotListener interface {
+	SlotReleased(released domain.Appointment)
 }
 
 type service struct {
-	r Repository
+	r         Repository
+	patients  patient.Repository
+	dentists  dentist.Repository
+	rooms     room.Repository
+	types     appointmenttype.Repository
+	clinics   clinic.Repository
+	closures  closure.Repository
+	policy    Policy
+	listeners []SlotListener
+}
+
+func NewService(r Repository, patients patient.Repository, dentists dentist.Repository, rooms room.Repository, clinics clinic.Repository, closures closure.Repository, types appointmenttype.Repository, policy Policy) Service {
+	return &service{r: r, patients: patients, dentists: dentists, rooms: rooms, clinics: clinics, closures: closures, types: types, policy: policy}
 }
 
-func NewService(r Repository) Service {
-	return &service{r}
+func (s *service) AddSlotListener(listener SlotListener) {
+	s.listeners = append(s.listeners, listener)
 }
 
 func (s *service) ReadById(id int) (domain.Appointment, error) {
@@ -30,15 +76,42 @@ func (s *service) ReadById(id int) (domain.Appointment, error) {
 	return appointment, nil
 }
 
-func (s *service) ReadByRg(rg string) ([]domain.Appointment, error) {
-	appointments, err := s.r.ReadByRg(rg)
+// ReadByRg lists the patient's appointments, only those at idClinic when it
+// is set.
+func (s *service) ReadByRg(rg string, idClinic int) ([]domain.Appointment, error) {
+	appointments, err := s.r.ReadByRg(document.RG(rg))
 	if err != nil {
 		return []domain.Appointment{}, err
 	}
-	return appointments, nil
+	return atClinic(appointments, idClinic), nil
+}
+
+// Search finds appointments matching filter. Dates given without an offset
+// are read in the zone of the filter's clinic or dentist.
+func (s *service) Search(filter domain.AppointmentFilter) ([]domain.Appointment, int, error) {
+	loc, err := s.Location(filter.DentistId, filter.ClinicId)
+	if err != nil {
+		return []domain.Appointment{}, 0, err
+	}
+	filter.From = timezone.Localize(filter.From, loc)
+	filter.To = timezone.Localize(filter.To, loc)
+	if filter.PatientRG != "" {
+		filter.PatientRG = document.RG(filter.PatientRG)
+	}
+
+	appointments, total, err := s.r.Search(filter)
+	if err != nil {
+		return []domain.Appointment{}, 0, err
+	}
+	return appointments, total, nil
 }
 
 func (s *service) CreateById(a domain.Appointment, idPatient int, idDentist int) (domain.Appointment, error) {
+	a, err := s.Prepare(a, idPatient, idDentist)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+
 	appointment, err := s.r.CreateById(a, idPatient, idDentist)
 	if err != nil {
 		return domain.Appointment{}, err
@@ -46,7 +119,37 @@ func (s *service) CreateById(a domain.Appointment, idPatient int, idDentist int)
 	return appointment, nil
 }
 
+// Prepare checks a booking the way CreateById does without saving it, and
+// returns it with the clinic, room and end it would be saved with.
+func (s *service) Prepare(a domain.Appointment, idPatient int, idDentist int) (domain.Appointment, error) {
+	a.Patient.Id, a.Dentist.Id = idPatient, idDentist
+	if err := s.checkPatient(a); err != nil {
+		return domain.Appointment{}, err
+	}
+	if err := s.checkSlot(nil, &a); err != nil {
+		return domain.Appointment{}, err
+	}
+	return a, nil
+}
+
 func (s *service) CreateByRgAndRegistration(a domain.Appointment, rgPatient string, registrationDentist string) (domain.Appointment, error) {
+	rgPatient = document.RG(rgPatient)
+	patient, err := s.patients.ReadByRg(rgPatient)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	dentist, err := s.dentists.ReadByRegistration(registrationDentist)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	a.Patient.Id, a.Dentist.Id = patient.Id, dentist.Id
+	if err := s.checkPatient(a); err != nil {
+		return domain.Appointment{}, err
+	}
+	if err := s.checkSlot(nil, &a); err != nil {
+		return domain.Appointment{}, err
+	}
+
 	appointment, err := s.r.CreateByRgAndRegistration(a, rgPatient, registrationDentist)
 	if err != nil {
 		return domain.Appointment{}, err
@@ -55,26 +158,974 @@ func (s *service) CreateByRgAndRegistration(a domain.Appointment, rgPatient stri
 }
 
 func (s *service) Update(id int, a domain.Appointment) (domain.Appointment, error) {
+	previous, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if a.Patient.Id != previous.Patient.Id {
+		if err := s.checkPatient(a); err != nil {
+			return domain.Appointment{}, err
+		}
+	}
+	if err := s.checkSlot([]int{id}, &a); err != nil {
+		return domain.Appointment{}, err
+	}
+
 	appointment, err := s.r.Update(id, a)
 	if err != nil {
 		return domain.Appointment{}, err
 	}
+	if err := s.recordHistory(previous, appointment, domain.ChangeUpdate, "", ""); err != nil {
+		return domain.Appointment{}, err
+	}
+	s.releaseIfMoved(previous, appointment)
 	return appointment, nil
 }
 
 func (s *service) Patch(id int, a domain.Appointment) (domain.Appointment, error) {
+	persisted, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	previous := persisted
+	if a.Patient.Id != 0 {
+		persisted.Patient.Id = a.Patient.Id
+	}
+	if a.Dentist.Id != 0 {
+		persisted.Dentist.Id = a.Dentist.Id
+	}
+	if !a.Start.IsZero() && a.End.IsZero() {
+		a.End = a.Start.Add(persisted.End.Sub(persisted.Start))
+	}
+	if !a.Start.IsZero() {
+		persisted.Start = a.Start
+	}
+	if !a.End.IsZero() {
+		persisted.End = a.End
+	}
+	if a.RoomId != 0 {
+		persisted.RoomId = a.RoomId
+	}
+	if a.ClinicId != 0 {
+		persisted.ClinicId = a.ClinicId
+	}
+	if a.TypeId != 0 {
+		persisted.TypeId = a.TypeId
+	}
+	if !persisted.End.After(persisted.Start) {
+		return domain.Appointment{}, errors.New("end must be after start")
+	}
+	if persisted.Patient.Id != previous.Patient.Id {
+		persisted.OverrideBy = a.OverrideBy
+		if err := s.checkPatient(persisted); err != nil {
+			return domain.Appointment{}, err
+		}
+	}
+	if err := s.checkSlot([]int{id}, &persisted); err != nil {
+		return domain.Appointment{}, err
+	}
+	a.ClinicId, a.RoomId = persisted.ClinicId, persisted.RoomId
+	if !a.Start.IsZero() {
+		a.Start = persisted.Start
+	}
+	if !a.End.IsZero() {
+		a.End = persisted.End
+	}
+
 	appointment, err := s.r.Patch(id, a)
 	if err != nil {
 		return domain.Appointment{}, err
 	}
+	if err := s.recordHistory(previous, appointment, domain.ChangeUpdate, "", ""); err != nil {
+		return domain.Appointment{}, err
+	}
+	s.releaseIfMoved(previous, appointment)
+	return appointment, nil
+}
+
+// Transition moves the appointment to status. Cancelling goes through Cancel,
+// and a no-show counts against the patient.
+func (s *service) Transition(id int, status string, changedBy string) (domain.Appointment, error) {
+	if status == domain.StatusCancelled {
+		return s.Cancel(id, domain.Cancellation{CancelledBy: changedBy})
+	}
+
+	appointment, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if !canTransition(appointment.Status, status) {
+		return domain.Appointment{}, &TransitionError{From: appointment.Status, To: status}
+	}
+
+	change := domain.StatusChange{
+		AppointmentId: id,
+		From:          appointment.Status,
+		To:            status,
+		ChangedBy:     changedBy,
+		ChangedAt:     time.Now(),
+	}
+	var strike *domain.Strike
+	if status == domain.StatusNoShow {
+		strike = &domain.Strike{PatientId: appointment.Patient.Id, NoShows: 1, Threshold: s.policy.Threshold}
+	}
+	if err := s.r.UpdateStatus(change, strike); err != nil {
+		return domain.Appointment{}, err
+	}
+	appointment.Status = status
+	return appointment, nil
+}
+
+func (s *service) ReadStatusHistory(id int) ([]domain.StatusChange, error) {
+	if _, err := s.r.ReadById(id); err != nil {
+		return []domain.StatusChange{}, err
+	}
+	changes, err := s.r.ReadStatusHistory(id)
+	if err != nil {
+		return []domain.StatusChange{}, err
+	}
+	return changes, nil
+}
+
+// Reschedule moves an appointment to a new start, and optionally a new end or
+// dentist, keeping its previous slot in the history. Without an end the
+// appointment keeps its duration.
+func (s *service) Reschedule(id int, a domain.Appointment, changedBy string, reason string) (domain.Appointment, error) {
+	previous, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if previous.Status != domain.StatusScheduled && previous.Status != domain.StatusConfirmed {
+		return domain.Appointment{}, &TransitionError{From: previous.Status, To: "rescheduled"}
+	}
+
+	patch := domain.Appointment{
+		Patient:  previous.Patient,
+		Dentist:  domain.Dentist{Id: previous.Dentist.Id},
+		Start:    a.Start,
+		End:      a.End,
+		RoomId:   previous.RoomId,
+		ClinicId: previous.ClinicId,
+		TypeId:   previous.TypeId,
+	}
+	if a.Dentist.Id != 0 {
+		patch.Dentist.Id = a.Dentist.Id
+	}
+	if a.RoomId != 0 {
+		patch.RoomId = a.RoomId
+	}
+	if a.ClinicId != 0 {
+		patch.ClinicId = a.ClinicId
+	}
+	if patch.End.IsZero() {
+		patch.End = patch.Start.Add(previous.End.Sub(previous.Start))
+	}
+	if err := s.checkSlot([]int{id}, &patch); err != nil {
+		return domain.Appointment{}, err
+	}
+
+	appointment, err := s.r.Patch(id, patch)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if err := s.recordHistory(previous, appointment, domain.ChangeReschedule, changedBy, reason); err != nil {
+		return domain.Appointment{}, err
+	}
+	s.releaseIfMoved(previous, appointment)
 	return appointment, nil
 }
 
-func (s *service) Delete(id int) error {
-	err := s.r.Delete(id)
+func (s *service) ReadHistory(id int) ([]domain.AppointmentChange, error) {
+	if _, err := s.r.ReadById(id); err != nil {
+		return []domain.AppointmentChange{}, err
+	}
+	changes, err := s.r.ReadHistory(id)
+	if err != nil {
+		return []domain.AppointmentChange{}, err
+	}
+	return changes, nil
+}
+
+func (s *service) ReadSeries(id int) (domain.AppointmentSeries, error) {
+	series, err := s.r.ReadSeries(id)
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+	return series, nil
+}
+
+// CreateSeries books every occurrence of recurrence starting with a. The
+// occurrences repeat the first one's wall-clock time in the clinic's zone,
+// even across daylight saving changes.
+func (s *service) CreateSeries(a domain.Appointment, idPatient int, idDentist int, recurrence domain.Recurrence) (domain.AppointmentSeries, error) {
+	a.Patient.Id, a.Dentist.Id = idPatient, idDentist
+	if err := s.checkPatient(a); err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+	if err := s.placeClinic(&a); err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+	loc, err := s.Location(idDentist, a.ClinicId)
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+	a.Start, a.End = timezone.Localize(a.Start, loc), timezone.Localize(a.End, loc)
+	recurrence.Until = timezone.Localize(recurrence.Until, loc)
+	if _, err := s.applyType(&a); err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+
+	slots, err := occurrences(domain.Slot{Start: a.Start, End: a.End}, recurrence)
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+
+	series := domain.AppointmentSeries{
+		Patient:     domain.Patient{Id: idPatient},
+		Dentist:     domain.Dentist{Id: idDentist},
+		Rule:        FormatRRule(recurrence),
+		Description: a.Description,
+	}
+	for _, slot := range slots {
+		occurrence := domain.Appointment{
+			Patient:     series.Patient,
+			Dentist:     series.Dentist,
+			Start:       slot.Start,
+			End:         slot.End,
+			Description: a.Description,
+			RoomId:      a.RoomId,
+			ClinicId:    a.ClinicId,
+			TypeId:      a.TypeId,
+			OverrideBy:  a.OverrideBy,
+		}
+		if err := s.checkSlot(nil, &occurrence); err != nil {
+			return domain.AppointmentSeries{}, err
+		}
+		series.Appointments = append(series.Appointments, occurrence)
+	}
+
+	createdSeries, err := s.r.CreateSeries(series)
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+	return createdSeries, nil
+}
+
+// PatchSeries applies a patch to the occurrences of id's series selected by
+// scope, all of them or none. A new start moves every selected occurrence by
+// the same wall-clock offset, so the series keeps its rhythm across daylight
+// saving changes, and the series' rule and details follow the patch unless
+// only id is patched. Occurrences that already ended their lifecycle are
+// left untouched.
+func (s *service) PatchSeries(id int, a domain.Appointment, scope string) ([]domain.Appointment, error) {
+	target, affected, err := s.seriesScope(id, scope)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	loc, err := s.Location(target.Dentist.Id, target.ClinicId)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	a.Start, a.End = timezone.Localize(a.Start, loc), timezone.Localize(a.End, loc)
+	var shift time.Duration
+	if !a.Start.IsZero() {
+		shift = floating(a.Start).Sub(floating(target.Start.In(loc)))
+	}
+
+	ignoreIds := make([]int, len(affected))
+	for i, occurrence := range affected {
+		ignoreIds[i] = occurrence.Id
+	}
+
+	var patched []domain.Appointment
+	var history []domain.AppointmentChange
+	for _, occurrence := range affected {
+		merged := occurrence
+		if a.Patient.Id != 0 {
+			merged.Patient.Id = a.Patient.Id
+		}
+		if a.Dentist.Id != 0 {
+			merged.Dentist.Id = a.Dentist.Id
+		}
+		if !a.Start.IsZero() {
+			merged.Start = timezone.Localize(floating(occurrence.Start.In(loc)).Add(shift), loc)
+			merged.End = merged.Start.Add(occurrence.End.Sub(occurrence.Start))
+			if !a.End.IsZero() {
+				merged.End = merged.Start.Add(a.End.Sub(a.Start))
+			}
+		}
+		if a.Description != "" {
+			merged.Description = a.Description
+		}
+		if a.RoomId != 0 {
+			merged.RoomId = a.RoomId
+		}
+		if a.ClinicId != 0 {
+			merged.ClinicId = a.ClinicId
+		}
+		if a.TypeId != 0 {
+			merged.TypeId = a.TypeId
+		}
+		if merged.Patient.Id != occurrence.Patient.Id {
+			merged.OverrideBy = a.OverrideBy
+			if err := s.checkPatient(merged); err != nil {
+				return []domain.Appointment{}, err
+			}
+		}
+		if err := s.checkSlot(ignoreIds, &merged); err != nil {
+			return []domain.Appointment{}, err
+		}
+		for _, other := range patched {
+			if other.Start.Before(merged.End) && merged.Start.Before(other.End) {
+				return []domain.Appointment{}, &ConflictError{Conflicting: other, Reason: "series"}
+			}
+		}
+		patched = append(patched, merged)
+		if change, changed := historyChange(occurrence, merged, domain.ChangeUpdate, "", ""); changed {
+			history = append(history, change)
+		}
+	}
+
+	series := domain.AppointmentSeries{Appointments: patched}
+	if scope != ScopeSingle {
+		if series, err = s.r.ReadSeries(target.SeriesId); err != nil {
+			return []domain.Appointment{}, err
+		}
+		series.Appointments = patched
+		if a.Patient.Id != 0 {
+			series.Patient.Id = a.Patient.Id
+		}
+		if a.Dentist.Id != 0 {
+			series.Dentist.Id = a.Dentist.Id
+		}
+		if a.Description != "" {
+			series.Description = a.Description
+		}
+		if shift != 0 {
+			recurrence, err := ParseRRule(series.Rule)
+			if err != nil {
+				return []domain.Appointment{}, err
+			}
+			if !recurrence.Until.IsZero() {
+				recurrence.Until = timezone.Localize(floating(recurrence.Until.In(loc)).Add(shift), loc)
+				series.Rule = FormatRRule(recurrence)
+			}
+		}
+	}
+
+	appointments, err := s.r.PatchSeries(series, history)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	for i := range affected {
+		s.releaseIfMoved(affected[i], appointments[i])
+	}
+	return appointments, nil
+}
+
+func (s *service) CancelSeries(id int, scope string, cancellation domain.Cancellation) ([]domain.Appointment, error) {
+	_, affected, err := s.seriesScope(id, scope)
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+
+	var appointments []domain.Appointment
+	for _, occurrence := range affected {
+		if !canTransition(occurrence.Status, domain.StatusCancelled) {
+			continue
+		}
+		appointment, err := s.Cancel(occurrence.Id, cancellation)
+		if err != nil {
+			return appointments, err
+		}
+		appointments = append(appointments, appointment)
+	}
+	return appointments, nil
+}
+
+// floating returns the wall clock t reads as a floating time, so that
+// differences between clocks ignore daylight saving changes.
+func floating(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), timezone.Floating)
+}
+
+// seriesScope returns the appointment id and the still-open occurrences of
+// its series selected by scope: just id, id and the ones after it, or all.
+func (s *service) seriesScope(id int, scope string) (domain.Appointment, []domain.Appointment, error) {
+	target, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Appointment{}, nil, err
+	}
+	if scope == ScopeSingle {
+		return target, []domain.Appointment{target}, nil
+	}
+	if target.SeriesId == 0 {
+		return domain.Appointment{}, nil, &NotInSeriesError{AppointmentId: id}
+	}
+
+	series, err := s.r.ReadSeries(target.SeriesId)
+	if err != nil {
+		return domain.Appointment{}, nil, err
+	}
+
+	var affected []domain.Appointment
+	for _, occurrence := range series.Appointments {
+		if isTerminal(occurrence.Status) {
+			continue
+		}
+		if scope == ScopeFollowing && occurrence.Start.Before(target.Start) {
+			continue
+		}
+		affected = append(affected, occurrence)
+	}
+	return target, affected, nil
+}
+
+// Availability lists the slots of duration in which the dentist is free and
+// the clinic is open and, when the clinic registered rooms, a room with the
+// equipment is free too. Each slot then carries the first such room. With
+// idClinic set only that clinic's rooms and closures are considered;
+// without it the dentist's only clinic is assumed, if they have one.
+func (s *service) Availability(idDentist int, from time.Time, to time.Time, duration time.Duration, equipment []string, idClinic int) ([]domain.Slot, error) {
+	dentist, err := s.dentists.ReadById(idDentist)
+	if err != nil {
+		return []domain.Slot{}, err
+	}
+	loc, err := s.Location(idDentist, idClinic)
+	if err != nil {
+		return []domain.Slot{}, err
+	}
+	from, to = timezone.Localize(from, loc), timezone.Localize(to, loc)
+	if idClinic != 0 {
+		if _, err := s.clinics.ReadById(idClinic); err != nil {
+			return []domain.Slot{}, err
+		}
+		if !worksAt(dentist, idClinic) {
+			return []domain.Slot{}, nil
+		}
+	}
+	timeOffs, err := s.dentists.ReadTimeOff(idDentist, from, to)
+	if err != nil {
+		return []domain.Slot{}, err
+	}
+	appointments, err := s.r.ReadByDentistAndPeriod(idDentist, from, to)
+	if err != nil {
+		return []domain.Slot{}, err
+	}
+
+	var busy []domain.Slot
+	for _, timeOff := range timeOffs {
+		busy = append(busy, domain.Slot{Start: timeOff.Start, End: timeOff.End})
+	}
+	for _, appointment := range appointments {
+		busy = append(busy, domain.Slot{Start: appointment.Start, End: appointment.End})
+	}
+
+	closureClinicId := idClinic
+	if closureClinicId == 0 && len(dentist.ClinicIds) == 1 {
+		closureClinicId = dentist.ClinicIds[0]
+	}
+	closures, err := s.closedOn(from, to, closureClinicId)
+	if err != nil {
+		return []domain.Slot{}, err
+	}
+	for _, c := range closures {
+		busy = append(busy, closure.Period(c, loc))
+	}
+
+	slots := splitSlots(subtract(workingPeriods(dentist.Schedule, from, to), busy), duration)
+
+	rooms, err := s.rooms.ReadAll()
+	if idClinic != 0 {
+		rooms, err = s.rooms.ReadByClinic(idClinic)
+	}
+	if err != nil {
+		return []domain.Slot{}, err
+	}
+	if len(rooms) == 0 {
+		return slots, nil
+	}
+
+	var suitable []domain.Room
+	roomBusy := map[int][]domain.Slot{}
+	for _, room := range rooms {
+		if !room.HasEquipment(equipment) {
+			continue
+		}
+		bookings, err := s.r.ReadConflictsByRoom(room.Id, from, to)
+		if err != nil {
+			return []domain.Slot{}, err
+		}
+		for _, booking := range bookings {
+			roomBusy[room.Id] = append(roomBusy[room.Id], domain.Slot{Start: booking.Start, End: booking.End})
+		}
+		suitable = append(suitable, room)
+	}
+	return assignRooms(slots, suitable, roomBusy), nil
+}
+
+// recordHistory keeps the previous time, dentist and description of an
+// appointment when a change touched any of them.
+func (s *service) recordHistory(previous domain.Appointment, current domain.Appointment, kind string, changedBy string, reason string) error {
+	change, changed := historyChange(previous, current, kind, changedBy, reason)
+	if !changed {
+		return nil
+	}
+	return s.r.CreateHistory(change)
+}
+
+// historyChange returns the record of previous that recordHistory keeps, and
+// whether current changed anything worth keeping.
+func historyChange(previous domain.Appointment, current domain.Appointment, kind string, changedBy string, reason string) (domain.AppointmentChange, bool) {
+	if previous.Dentist.Id == current.Dentist.Id && previous.Start.Equal(current.Start) &&
+		previous.End.Equal(current.End) && previous.Description == current.Description {
+		return domain.AppointmentChange{}, false
+	}
+
+	return domain.AppointmentChange{
+		AppointmentId: previous.Id,
+		Kind:          kind,
+		DentistId:     previous.Dentist.Id,
+		Start:         previous.Start,
+		End:           previous.End,
+		Description:   previous.Description,
+		ChangedBy:     changedBy,
+		Reason:        reason,
+		ChangedAt:     time.Now(),
+	}, true
+}
+
+// releaseIfMoved releases the previous slot when an update moved the
+// appointment to another time or dentist.
+func (s *service) releaseIfMoved(previous domain.Appointment, current domain.Appointment) {
+	if previous.Dentist.Id != current.Dentist.Id || !previous.Start.Equal(current.Start) || !previous.End.Equal(current.End) {
+		s.releaseSlot(previous)
+	}
+}
+
+// releaseSlot tells the listeners that the slot of released is free again.
+// Slots already in the past are of no use to anyone and are skipped.
+func (s *service) releaseSlot(released domain.Appointment) {
+	if !released.Start.After(time.Now()) {
+		return
+	}
+	for _, listener := range s.listeners {
+		listener.SlotReleased(released)
+	}
+}
+
+// Agenda lays out the dentist's days between from and to: appointments in
+// time order, time off, and the gaps left in the working hours. Days are
+// calendar days in the dentist's zone, so a daylight saving change makes one
+// shorter or longer. A zero from stands for today. With idClinic set only the
+// appointments at that clinic are listed.
+func (s *service) Agenda(idDentist int, from time.Time, to time.Time, idClinic int) (domain.Agenda, error) {
+	dentist, err := s.dentists.ReadById(idDentist)
+	if err != nil {
+		return domain.Agenda{}, err
+	}
+	loc, err := s.Location(idDentist, idClinic)
+	if err != nil {
+		return domain.Agenda{}, err
+	}
+	if from.IsZero() {
+		today := time.Now().In(loc)
+		from = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+		to = from.AddDate(0, 0, 1)
+	}
+	from, to = timezone.Localize(from, loc), timezone.Localize(to, loc)
+	timeOffs, err := s.dentists.ReadTimeOff(idDentist, from, to)
+	if err != nil {
+		return domain.Agenda{}, err
+	}
+	appointments, err := s.r.ReadByDentistAndPeriod(idDentist, from, to)
+	if err != nil {
+		return domain.Agenda{}, err
+	}
+
+	appointments = atClinic(appointments, idClinic)
+
+	agenda := domain.Agenda{Dentist: dentist, From: from, To: to}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		agenda.Days = append(agenda.Days, agendaDay(dentist.Schedule, timeOffs, appointments, day, day.AddDate(0, 0, 1)))
+	}
+	return agenda, nil
+}
+
+// ReportByClinic counts the appointments starting between from and to at
+// each clinic, by status.
+func (s *service) ReportByClinic(from time.Time, to time.Time, idClinic int) ([]domain.ClinicReport, error) {
+	loc, err := s.Location(0, idClinic)
+	if err != nil {
+		return []domain.ClinicReport{}, err
+	}
+	from, to = timezone.Localize(from, loc), timezone.Localize(to, loc)
+	reports, err := s.r.ReportByClinic(from, to, idClinic)
+	if err != nil {
+		return []domain.ClinicReport{}, err
+	}
+	return reports, nil
+}
+
+// CheckSlot validates a new booking without saving it, and returns it with
+// the clinic and room it would be saved with. A zero id stands for a patient
+// or dentist that is not created yet, which can't have bookings, working
+// hours, restrictions or guardians. The rooms of unsaved, bookings checked
+// before and to be saved along with this one, are taken as occupied.
+func (s *service) CheckSlot(a domain.Appointment, unsaved []domain.Appointment) (domain.Appointment, error) {
+	if a.Patient.Id != 0 {
+		if err := s.checkPatient(a); err != nil {
+			return domain.Appointment{}, err
+		}
+	}
+	if a.Dentist.Id != 0 {
+		if err := s.placeClinic(&a); err != nil {
+			return domain.Appointment{}, err
+		}
+	}
+	loc, err := s.Location(a.Dentist.Id, a.ClinicId)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	a.Start, a.End = timezone.Localize(a.Start, loc), timezone.Localize(a.End, loc)
+	if err := s.assignRoom(nil, unsaved, &a, nil); err != nil {
+		return domain.Appointment{}, err
+	}
+	if a.Dentist.Id != 0 {
+		if err := s.checkWorkingHours(a.Dentist.Id, a.Start, a.End); err != nil {
+			return domain.Appointment{}, err
+		}
+	}
+	if err := s.checkClosures(a); err != nil {
+		return domain.Appointment{}, err
+	}
+	if err := s.checkConflicts(nil, a); err != nil {
+		return domain.Appointment{}, err
+	}
+	return a, nil
+}
+
+// checkSlot validates that the appointment's period is bookable for its
+// dentist, patient and room: at a clinic the dentist works at, inside the
+// dentist's working hours, outside closures and free of conflicts. It fills
+// in the clinic when it can be told from the dentist or the room, and reads
+// times given without an offset in the clinic's zone. For typed appointments
+// it also fills in the end and a room with the equipment the type requires.
+func (s *service) checkSlot(ignoreIds []int, a *domain.Appointment) error {
+	if err := s.placeClinic(a); err != nil {
+		return err
+	}
+	loc, err := s.Location(a.Dentist.Id, a.ClinicId)
+	if err != nil {
+		return err
+	}
+	a.Start, a.End = timezone.Localize(a.Start, loc), timezone.Localize(a.End, loc)
+	equipment, err := s.applyType(a)
 	if err != nil {
 		return err
 	}
+	if err := s.assignRoom(ignoreIds, nil, a, equipment); err != nil {
+		return err
+	}
+	if err := s.checkWorkingHours(a.Dentist.Id, a.Start, a.End); err != nil {
+		return err
+	}
+	if err := s.checkClosures(*a); err != nil {
+		return err
+	}
+	return s.checkConflicts(ignoreIds, *a)
+}
+
+// applyType checks the appointment's type and gives an appointment without
+// an end the type's duration. It returns the equipment the type requires.
+func (s *service) applyType(a *domain.Appointment) ([]string, error) {
+	if a.TypeId == 0 {
+		return nil, nil
+	}
+	appointmentType, err := s.types.ReadById(a.TypeId)
+	if err != nil {
+		return nil, err
+	}
+	if a.End.IsZero() {
+		a.End = a.Start.Add(time.Duration(appointmentType.Duration) * time.Minute)
+	}
+	return appointmentType.Equipment, nil
+}
+
+// assignRoom makes sure an appointment takes place in a room, fitted with
+// the equipment it needs. Without a room it takes the first suitable one free
+// at the time among the rooms of the appointment's clinic, or the rooms of
+// no clinic for appointments not placed at one, so that checkConflicts can
+// keep two bookings out of the same chair. The rooms of the unsaved bookings
+// are occupied as if they were saved. As in Availability, clinics that
+// registered no rooms are not restricted.
+func (s *service) assignRoom(ignoreIds []int, unsaved []domain.Appointment, a *domain.Appointment, equipment []string) error {
+	if a.RoomId != 0 {
+		room, err := s.rooms.ReadById(a.RoomId)
+		if err != nil {
+			return err
+		}
+		if !room.HasEquipment(equipment) {
+			return &UnavailableError{Reason: "room " + room.Name + " lacks the equipment the appointment type requires"}
+		}
+		return nil
+	}
+
+	rooms, err := s.rooms.ReadAll()
+	if err != nil {
+		return err
+	}
+	registered := false
+	for _, room := range rooms {
+		if room.ClinicId != a.ClinicId {
+			continue
+		}
+		registered = true
+		if !room.HasEquipment(equipment) {
+			continue
+		}
+		bookings, err := s.r.ReadConflictsByRoom(room.Id, a.Start, a.End)
+		if err != nil {
+			return err
+		}
+		free := true
+		for i := range bookings {
+			if !containsId(ignoreIds, bookings[i].Id) {
+				free = false
+			}
+		}
+		for _, other := range unsaved {
+			if other.RoomId == room.Id && other.Start.Before(a.End) && a.Start.Before(other.End) {
+				free = false
+			}
+		}
+		if free {
+			a.RoomId = room.Id
+			return nil
+		}
+	}
+	if !registered {
+		return nil
+	}
+	if len(equipment) == 0 {
+		return &UnavailableError{Reason: "no room is free at the time"}
+	}
+	return &UnavailableError{Reason: "no room with the equipment the appointment type requires is free"}
+}
+
+// checkClosures rejects appointments touching a closure of their clinic or
+// of every clinic.
+func (s *service) checkClosures(a domain.Appointment) error {
+	closures, err := s.closedOn(a.Start, a.End, a.ClinicId)
+	if err != nil {
+		return err
+	}
+	for _, c := range closures {
+		period := closure.Period(c, a.Start.Location())
+		if period.Start.Before(a.End) && a.Start.Before(period.End) {
+			return &UnavailableError{Reason: "clinic is closed: " + c.Reason}
+		}
+	}
+	return nil
+}
+
+// closedOn returns the closure occurrences applying to idClinic between
+// from and to.
+func (s *service) closedOn(from time.Time, to time.Time, idClinic int) ([]domain.Closure, error) {
+	closures, err := s.closures.ReadBetween(from, to, idClinic)
+	if err != nil {
+		return []domain.Closure{}, err
+	}
+	return closure.Occurrences(closures, from, to, idClinic), nil
+}
+
+// placeClinic checks the appointment's clinic against its dentist and room.
+// Without a clinic it takes the room's, or the dentist's when they work at a
+// single one. Dentists not linked to any clinic may be booked anywhere.
+func (s *service) placeClinic(a *domain.Appointment) error {
+	dentist, err := s.dentists.ReadById(a.Dentist.Id)
+	if err != nil {
+		return err
+	}
+
+	roomClinicId := 0
+	if a.RoomId != 0 {
+		room, err := s.rooms.ReadById(a.RoomId)
+		if err != nil {
+			return err
+		}
+		roomClinicId = room.ClinicId
+	}
+
+	if a.ClinicId == 0 {
+		a.ClinicId = roomClinicId
+	}
+	if a.ClinicId == 0 && len(dentist.ClinicIds) == 1 {
+		a.ClinicId = dentist.ClinicIds[0]
+	}
+	if a.ClinicId == 0 {
+		return nil
+	}
+
+	if _, err := s.clinics.ReadById(a.ClinicId); err != nil {
+		return err
+	}
+	if !worksAt(dentist, a.ClinicId) {
+		return &UnavailableError{Reason: "dentist doesn't work at the appointment's clinic"}
+	}
+	if roomClinicId != 0 && roomClinicId != a.ClinicId {
+		return &UnavailableError{Reason: "room belongs to another clinic"}
+	}
+	return nil
+}
+
+// Location returns the zone appointments are read in: the clinic's when
+// idClinic is set, else the dentist's own, else that of the dentist's only
+// clinic, else timezone.Default. Zero ids are skipped.
+func (s *service) Location(idDentist int, idClinic int) (*time.Location, error) {
+	if idClinic != 0 {
+		clinic, err := s.clinics.ReadById(idClinic)
+		if err != nil {
+			return nil, err
+		}
+		return timezone.Load(clinic.TimeZone)
+	}
+	if idDentist == 0 {
+		return timezone.Load("")
+	}
+	dentist, err := s.dentists.ReadById(idDentist)
+	if err != nil {
+		return nil, err
+	}
+	if dentist.TimeZone == "" && len(dentist.ClinicIds) == 1 {
+		return s.Location(0, dentist.ClinicIds[0])
+	}
+	return timezone.Load(dentist.TimeZone)
+}
+
+func containsId(ids []int, id int) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+// worksAt reports whether the dentist can be booked at the clinic.
+func worksAt(dentist domain.Dentist, idClinic int) bool {
+	if len(dentist.ClinicIds) == 0 {
+		return true
+	}
+	for _, clinicId := range dentist.ClinicIds {
+		if clinicId == idClinic {
+			return true
+		}
+	}
+	return false
+}
+
+// atClinic keeps the appointments placed at idClinic, or all of them when it
+// is zero.
+func atClinic(appointments []domain.Appointment, idClinic int) []domain.Appointment {
+	if idClinic == 0 {
+		return appointments
+	}
+	placed := []domain.Appointment{}
+	for _, appointment := range appointments {
+		if appointment.ClinicId == idClinic {
+			placed = append(placed, appointment)
+		}
+	}
+	return placed
+}
+
+// checkWorkingHours rejects times outside the dentist's weekly schedule or
+// during their time off. Dentists without a schedule are not restricted, so
+// that existing dentists stay bookable until their hours are registered. The
+// schedule is read in the zone start and end are given in.
+func (s *service) checkWorkingHours(idDentist int, start time.Time, end time.Time) error {
+	dentist, err := s.dentists.ReadById(idDentist)
+	if err != nil {
+		return err
+	}
+	if len(dentist.Schedule) > 0 && !withinWorkingHours(dentist.Schedule, start, end) {
+		return &UnavailableError{Reason: "appointment is outside the dentist's working hours"}
+	}
+
+	timeOffs, err := s.dentists.ReadTimeOff(idDentist, start, end)
+	if err != nil {
+		return err
+	}
+	if len(timeOffs) > 0 {
+		return &UnavailableError{Reason: "dentist is on time off: " + timeOffs[0].Reason}
+	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// checkConflicts rejects a booking when the dentist, the patient or the room
+// already has another appointment overlapping the period, or the dentist's
+// period is held for another patient on the waitlist. ignoreIds are the
+// appointments being changed, so that they do not conflict with themselves.
+func (s *service) checkConflicts(ignoreIds []int, a domain.Appointment) error {
+	appointments, err := s.r.ReadConflictsByDentist(a.Dentist.Id, a.Start, a.End)
+	if err != nil {
+		return err
+	}
+	for i := range appointments {
+		if !containsId(ignoreIds, appointments[i].Id) {
+			return &ConflictError{Conflicting: appointments[i], Reason: "dentist"}
+		}
+	}
+
+	holds, err := s.r.ReadConflictingHolds(a.Dentist.Id, a.Patient.Id, a.Start, a.End)
+	if err != nil {
+		return err
+	}
+	if len(holds) > 0 {
+		return &UnavailableError{Reason: fmt.Sprintf("slot is held for the waitlist until %s", holds[0].ExpiresAt.Format(time.RFC3339))}
+	}
+
+	appointments, err = s.r.ReadConflictsByPatient(a.Patient.Id, a.Start, a.End)
+	if err != nil {
+		return err
+	}
+	for i := range appointments {
+		if !containsId(ignoreIds, appointments[i].Id) {
+			return &ConflictError{Conflicting: appointments[i], Reason: "patient"}
+		}
+	}
+
+	if a.RoomId == 0 {
+		return nil
+	}
+	appointments, err = s.r.ReadConflictsByRoom(a.RoomId, a.Start, a.End)
+	if err != nil {
+		return err
+	}
+	for i := range appointments {
+		if !containsId(ignoreIds, appointments[i].Id) {
+			return &ConflictError{Conflicting: appointments[i], Reason: "room"}
+		}
+	}
+
+	return nil
+}
+
+// checkPatient refuses to book a restricted patient unless a member of the
+// staff overrode the restriction, and a patient who is a minor on the day of
+// the appointment without a guardian on record.
+func (s *service) checkPatient(a domain.Appointment) error {
+	patient, err := s.patients.ReadById(a.Patient.Id)
+	if err != nil {
+		return err
+	}
+	if patient.Restricted && a.OverrideBy == "" {
+		return &RestrictedError{PatientId: patient.Id}
+	}
+	if patient.MinorOn(a.Start) && len(patient.Guardians) == 0 {
+		return &GuardianRequiredError{PatientId: patient.Id}
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package appointment
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/timezone"
+	"errors"
+	"testing"
+	"time"
+)
+
+// at returns the wall clock on the day in the default zone.
+func at(t *testing.T, day string, clock string) time.Time {
+	t.Helper()
+	loc, err := timezone.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", day+" "+clock, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestCheckSlotConflicts(t *testing.T) {
+	booked := domain.Appointment{
+		Id:      1,
+		Patient: domain.Patient{Id: 1},
+		Dentist: domain.Dentist{Id: 1},
+		Start:   at(t, "2024-03-04", "10:00"),
+		End:     at(t, "2024-03-04", "11:00"),
+		Status:  domain.StatusScheduled,
+		RoomId:  1,
+	}
+	cancelled := domain.Appointment{
+		Id:      2,
+		Patient: domain.Patient{Id: 3},
+		Dentist: domain.Dentist{Id: 3},
+		Start:   at(t, "2024-03-04", "14:00"),
+		End:     at(t, "2024-03-04", "15:00"),
+		Status:  domain.StatusCancelled,
+		RoomId:  1,
+	}
+
+	tests := []struct {
+		name       string
+		patient    int
+		dentist    int
+		room       int
+		start, end string
+		wantReason string
+	}{
+		{"ends as the booking starts", 1, 1, 0, "09:00", "10:00", ""},
+		{"starts as the booking ends", 1, 1, 0, "11:00", "12:00", ""},
+		{"same dentist overlapping", 2, 1, 0, "10:30", "11:30", "dentist"},
+		{"same dentist inside", 2, 1, 0, "10:15", "10:45", "dentist"},
+		{"same dentist around", 2, 1, 0, "09:30", "11:30", "dentist"},
+		{"same patient overlapping", 1, 2, 0, "09:30", "10:30", "patient"},
+		{"same room overlapping", 2, 2, 1, "10:59", "11:30", "room"},
+		{"same room touching", 2, 2, 1, "11:00", "11:30", ""},
+		{"other people and room", 2, 2, 0, "10:00", "11:00", ""},
+		{"cancelled booking", 3, 3, 1, "14:00", "15:00", ""},
+	}
+	for _, tt := range tests {
+		s := newTestService(newFakeAppointments(booked, cancelled), fakeDentists{}, fakeRooms{rooms: []domain.Room{{Id: 1}, {Id: 2}}})
+		_, err := s.CheckSlot(domain.Appointment{
+			Patient: domain.Patient{Id: tt.patient},
+			Dentist: domain.Dentist{Id: tt.dentist},
+			Start:   at(t, "2024-03-04", tt.start),
+			End:     at(t, "2024-03-04", tt.end),
+			RoomId:  tt.room,
+		}, nil)
+
+		var conflict *ConflictError
+		switch {
+		case tt.wantReason == "" && err != nil:
+			t.Errorf("%s: CheckSlot() error = %v, want none", tt.name, err)
+		case tt.wantReason != "" && !errors.As(err, &conflict):
+			t.Errorf("%s: CheckSlot() error = %v, want a %s conflict", tt.name, err, tt.wantReason)
+		case tt.wantReason != "" && (conflict.Reason != tt.wantReason || conflict.Conflicting.Id != booked.Id):
+			t.Errorf("%s: CheckSlot() conflict = %s with %d, want %s with %d", tt.name, conflict.Reason, conflict.Conflicting.Id, tt.wantReason, booked.Id)
+		}
+	}
+}
+
+func TestCheckSlotAssignsFreeRoom(t *testing.T) {
+	booked := domain.Appointment{
+		Id:      1,
+		Patient: domain.Patient{Id: 1},
+		Dentist: domain.Dentist{Id: 1},
+		Start:   at(t, "2024-03-04", "10:00"),
+		End:     at(t, "2024-03-04", "11:00"),
+		RoomId:  1,
+	}
+	// unsaved are bookings checked before in the same batch, not saved yet.
+	unsaved := func(room int, start string, end string) []domain.Appointment {
+		return []domain.Appointment{{Start: at(t, "2024-03-04", start), End: at(t, "2024-03-04", end), RoomId: room}}
+	}
+	tests := []struct {
+		rooms    []domain.Room
+		unsaved  []domain.Appointment
+		wantRoom int
+		wantErr  bool
+	}{
+		{nil, nil, 0, false},
+		{[]domain.Room{{Id: 1}, {Id: 2}}, nil, 2, false},
+		{[]domain.Room{{Id: 1}}, nil, 0, true},
+		{[]domain.Room{{Id: 1}, {Id: 2}, {Id: 3}}, unsaved(2, "11:00", "12:00"), 3, false},
+		{[]domain.Room{{Id: 1}, {Id: 2}, {Id: 3}}, unsaved(2, "11:30", "12:30"), 2, false},
+		{[]domain.Room{{Id: 1}, {Id: 2}}, unsaved(2, "10:00", "11:00"), 0, true},
+	}
+	for _, tt := range tests {
+		s := newTestService(newFakeAppointments(booked), fakeDentists{}, fakeRooms{rooms: tt.rooms})
+		placed, err := s.CheckSlot(domain.Appointment{
+			Patient: domain.Patient{Id: 2},
+			Dentist: domain.Dentist{Id: 2},
+			Start:   at(t, "2024-03-04", "10:30"),
+			End:     at(t, "2024-03-04", "11:30"),
+		}, tt.unsaved)
+		var unavailable *UnavailableError
+		if tt.wantErr != errors.As(err, &unavailable) || !tt.wantErr && err != nil {
+			t.Errorf("CheckSlot() with rooms %v and unsaved %v error = %v, want unavailable %v", tt.rooms, tt.unsaved, err, tt.wantErr)
+			continue
+		}
+		if placed.RoomId != tt.wantRoom {
+			t.Errorf("CheckSlot() with rooms %v and unsaved %v room = %d, want %d", tt.rooms, tt.unsaved, placed.RoomId, tt.wantRoom)
+		}
+	}
+}
+
+func TestCheckSlotHolds(t *testing.T) {
+	r := newFakeAppointments()
+	r.holds = []fakeHold{{
+		SlotHold: domain.SlotHold{
+			DentistId: 1,
+			Start:     at(t, "2024-03-04", "10:00"),
+			End:       at(t, "2024-03-04", "11:00"),
+			ExpiresAt: at(t, "2024-03-04", "09:00"),
+		},
+		PatientId: 1,
+	}}
+	s := newTestService(r, fakeDentists{}, fakeRooms{})
+
+	tests := []struct {
+		patient int
+		start   string
+		wantErr bool
+	}{
+		{1, "10:00", false},
+		{2, "10:00", true},
+		{2, "10:30", true},
+		{2, "11:00", false},
+	}
+	for _, tt := range tests {
+		start := at(t, "2024-03-04", tt.start)
+		_, err := s.CheckSlot(domain.Appointment{
+			Patient: domain.Patient{Id: tt.patient},
+			Dentist: domain.Dentist{Id: 1},
+			Start:   start,
+			End:     start.Add(time.Hour),
+		}, nil)
+		var unavailable *UnavailableError
+		if errors.As(err, &unavailable) != tt.wantErr || !tt.wantErr && err != nil {
+			t.Errorf("CheckSlot(patient %d at %s) error = %v, want held %v", tt.patient, tt.start, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUpdateIgnoresItself(t *testing.T) {
+	booked := domain.Appointment{
+		Id:      1,
+		Patient: domain.Patient{Id: 1},
+		Dentist: domain.Dentist{Id: 1},
+		Start:   at(t, "2024-03-04", "10:00"),
+		End:     at(t, "2024-03-04", "11:00"),
+	}
+	other := domain.Appointment{
+		Id:      2,
+		Patient: domain.Patient{Id: 2},
+		Dentist: domain.Dentist{Id: 1},
+		Start:   at(t, "2024-03-04", "11:30"),
+		End:     at(t, "2024-03-04", "12:30"),
+	}
+	s := newTestService(newFakeAppointments(booked, other), fakeDentists{}, fakeRooms{})
+
+	moved := booked
+	moved.Start, moved.End = at(t, "2024-03-04", "10:30"), at(t, "2024-03-04", "11:30")
+	if _, err := s.Update(booked.Id, moved); err != nil {
+		t.Errorf("Update() into its own slot error = %v, want none", err)
+	}
+
+	moved.Start, moved.End = at(t, "2024-03-04", "11:00"), at(t, "2024-03-04", "12:00")
+	var conflict *ConflictError
+	if _, err := s.Update(booked.Id, moved); !errors.As(err, &conflict) || conflict.Conflicting.Id != other.Id {
+		t.Errorf("Update() onto appointment %d error = %v, want a conflict with it", other.Id, err)
+	}
+}
+
+// weeklySeries books four Mondays at 10:00 in series 1, with the first one
+// already completed.
+func weeklySeries(t *testing.T) *fakeAppointments {
+	r := newFakeAppointments()
+	for i, day := range []string{"2024-03-04", "2024-03-11", "2024-03-18", "2024-03-25"} {
+		status := domain.StatusScheduled
+		if i == 0 {
+			status = domain.StatusCompleted
+		}
+		r.appointments[i+1] = domain.Appointment{
+			Id:          i + 1,
+			Patient:     domain.Patient{Id: 1},
+			Dentist:     domain.Dentist{Id: 1},
+			Start:       at(t, day, "10:00"),
+			End:         at(t, day, "11:00"),
+			Description: "cleaning",
+			Status:      status,
+			SeriesId:    1,
+		}
+	}
+	r.series[1] = domain.AppointmentSeries{
+		Id:          1,
+		Patient:     domain.Patient{Id: 1},
+		Dentist:     domain.Dentist{Id: 1},
+		Rule:        "FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T025959Z",
+		Description: "cleaning",
+	}
+	return r
+}
+
+func TestPatchSeries(t *testing.T) {
+	tests := []struct {
+		name        string
+		scope       string
+		patch       domain.Appointment
+		wantStarts  map[int]string
+		wantDesc    map[int]string
+		wantRule    string
+		wantSeries  string
+		wantPatched int
+	}{
+		{"single moved", ScopeSingle, domain.Appointment{Start: at(t, "2024-03-18", "11:00")},
+			map[int]string{1: "10:00", 2: "10:00", 3: "11:00", 4: "10:00"}, nil,
+			"FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T025959Z", "cleaning", 1},
+		{"following moved", ScopeFollowing, domain.Appointment{Start: at(t, "2024-03-18", "11:00")},
+			map[int]string{1: "10:00", 2: "10:00", 3: "11:00", 4: "11:00"}, nil,
+			"FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T035959Z", "cleaning", 2},
+		{"all moved", ScopeAll, domain.Appointment{Start: at(t, "2024-03-18", "09:30"), End: at(t, "2024-03-18", "10:00")},
+			map[int]string{1: "10:00", 2: "09:30", 3: "09:30", 4: "09:30"}, nil,
+			"FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T022959Z", "cleaning", 3},
+		{"single described", ScopeSingle, domain.Appointment{Description: "filling"},
+			map[int]string{1: "10:00", 2: "10:00", 3: "10:00", 4: "10:00"},
+			map[int]string{1: "cleaning", 2: "cleaning", 3: "filling", 4: "cleaning"},
+			"FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T025959Z", "cleaning", 1},
+		{"following described", ScopeFollowing, domain.Appointment{Description: "filling"},
+			map[int]string{1: "10:00", 2: "10:00", 3: "10:00", 4: "10:00"},
+			map[int]string{1: "cleaning", 2: "cleaning", 3: "filling", 4: "filling"},
+			"FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T025959Z", "filling", 2},
+		{"all described", ScopeAll, domain.Appointment{Description: "filling"},
+			map[int]string{1: "10:00", 2: "10:00", 3: "10:00", 4: "10:00"},
+			map[int]string{1: "cleaning", 2: "filling", 3: "filling", 4: "filling"},
+			"FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T025959Z", "filling", 3},
+		{"all given another dentist", ScopeAll, domain.Appointment{Dentist: domain.Dentist{Id: 2}},
+			map[int]string{1: "10:00", 2: "10:00", 3: "10:00", 4: "10:00"}, nil,
+			"FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T025959Z", "cleaning", 3},
+	}
+	for _, tt := range tests {
+		r := weeklySeries(t)
+		s := newTestService(r, fakeDentists{}, fakeRooms{})
+
+		patched, err := s.PatchSeries(3, tt.patch, tt.scope)
+		if err != nil {
+			t.Errorf("%s: PatchSeries() error = %v", tt.name, err)
+			continue
+		}
+		if len(patched) != tt.wantPatched {
+			t.Errorf("%s: PatchSeries() patched %d occurrences, want %d", tt.name, len(patched), tt.wantPatched)
+		}
+		for id, want := range tt.wantStarts {
+			if got := r.appointments[id].Start.Format("15:04"); got != want {
+				t.Errorf("%s: occurrence %d starts at %s, want %s", tt.name, id, got, want)
+			}
+		}
+		for id, want := range tt.wantDesc {
+			if got := r.appointments[id].Description; got != want {
+				t.Errorf("%s: occurrence %d is described %q, want %q", tt.name, id, got, want)
+			}
+		}
+		if series := r.series[1]; series.Rule != tt.wantRule || series.Description != tt.wantSeries {
+			t.Errorf("%s: series = %q %q, want %q %q", tt.name, series.Rule, series.Description, tt.wantRule, tt.wantSeries)
+		}
+	}
+}
+
+func TestPatchSeriesRefused(t *testing.T) {
+	r := weeklySeries(t)
+	r.appointments[5] = domain.Appointment{
+		Id:      5,
+		Patient: domain.Patient{Id: 2},
+		Dentist: domain.Dentist{Id: 1},
+		Start:   at(t, "2024-03-25", "11:00"),
+		End:     at(t, "2024-03-25", "12:00"),
+	}
+	s := newTestService(r, fakeDentists{}, fakeRooms{})
+
+	var conflict *ConflictError
+	if _, err := s.PatchSeries(3, domain.Appointment{Start: at(t, "2024-03-18", "11:00")}, ScopeFollowing); !errors.As(err, &conflict) || conflict.Conflicting.Id != 5 {
+		t.Errorf("PatchSeries() onto appointment 5 error = %v, want a conflict with it", err)
+	}
+	if r.appointments[3].Start.Format("15:04") != "10:00" || r.series[1].Rule != "FREQ=WEEKLY;INTERVAL=1;UNTIL=20240326T025959Z" {
+		t.Errorf("PatchSeries() refused still changed the series")
+	}
+
+	if _, err := s.PatchSeries(3, domain.Appointment{Start: at(t, "2024-03-25", "10:00")}, ScopeSingle); !errors.As(err, &conflict) || conflict.Conflicting.Id != 4 {
+		t.Errorf("PatchSeries() onto occurrence 4 error = %v, want a conflict with it", err)
+	}
+
+	var notInSeries *NotInSeriesError
+	if _, err := s.PatchSeries(5, domain.Appointment{Description: "filling"}, ScopeAll); !errors.As(err, &notInSeries) {
+		t.Errorf("PatchSeries() outside a series error = %v, want NotInSeriesError", err)
+	}
+}
@@ -0,0 +1,71 @@
+package appointmenttype
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/store"
+)
+
+type Repository interface {
+	ReadById(id int) (domain.AppointmentType, error)
+	ReadAll() ([]domain.AppointmentType, error)
+	Create(appointmentType domain.AppointmentType) (domain.AppointmentType, error)
+	Update(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error)
+	Patch(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error)
+	Delete(id int) error
+}
+
+type repository struct {
+	storage store.AppointmentTypeStoreInterface
+}
+
+func NewRepository(storage store.AppointmentTypeStoreInterface) Repository {
+	return &repository{storage}
+}
+
+func (r *repository) ReadById(id int) (domain.AppointmentType, error) {
+	appointmentType, err := r.storage.ReadById(id)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	return appointmentType, nil
+}
+
+func (r *repository) ReadAll() ([]domain.AppointmentType, error) {
+	appointmentTypes, err := r.storage.ReadAll()
+	if err != nil {
+		return []domain.AppointmentType{}, err
+	}
+	return appointmentTypes, nil
+}
+
+func (r *repository) Create(t domain.AppointmentType) (domain.AppointmentType, error) {
+	appointmentType, err := r.storage.Create(t)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	return appointmentType, nil
+}
+
+func (r *repository) Update(id int, t domain.AppointmentType) (domain.AppointmentType, error) {
+	appointmentType, err := r.storage.Update(id, t)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	return appointmentType, nil
+}
+
+func (r *repository) Patch(id int, t domain.AppointmentType) (domain.AppointmentType, error) {
+	appointmentType, err := r.storage.Patch(id, t)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	return appointmentType, nil
+}
+
+func (r *repository) Delete(id int) error {
+	err := r.storage.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package appointmenttype
+
+import (
+	"checkpoint2/internal/domain"
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+type Service interface {
+	ReadById(id int) (domain.AppointmentType, error)
+	ReadAll() ([]domain.AppointmentType, error)
+	Create(appointmentType domain.AppointmentType) (domain.AppointmentType, error)
+	Update(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error)
+	Patch(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error)
+	Delete(id int) error
+}
+
+type service struct {
+	r Repository
+}
+
+func NewService(r Repository) Service {
+	return &service{r}
+}
+
+func (s *service) ReadById(id int) (domain.AppointmentType, error) {
+	appointmentType, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	return appointmentType, nil
+}
+
+func (s *service) ReadAll() ([]domain.AppointmentType, error) {
+	appointmentTypes, err := s.r.ReadAll()
+	if err != nil {
+		return []domain.AppointmentType{}, err
+	}
+	return appointmentTypes, nil
+}
+
+func (s *service) Create(appointmentType domain.AppointmentType) (domain.AppointmentType, error) {
+	if appointmentType.Duration <= 0 {
+		return domain.AppointmentType{}, errors.New("duration must be positive")
+	}
+	if appointmentType.Equipment == nil {
+		appointmentType.Equipment = []string{}
+	}
+	if err := s.normalize(0, &appointmentType); err != nil {
+		return domain.AppointmentType{}, err
+	}
+	createdAppointmentType, err := s.r.Create(appointmentType)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	return createdAppointmentType, nil
+}
+
+func (s *service) Update(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error) {
+	if appointmentType.Duration <= 0 {
+		return domain.AppointmentType{}, errors.New("duration must be positive")
+	}
+	if appointmentType.Equipment == nil {
+		appointmentType.Equipment = []string{}
+	}
+	if err := s.normalize(id, &appointmentType); err != nil {
+		return domain.AppointmentType{}, err
+	}
+	updatedAppointmentType, err := s.r.Update(id, appointmentType)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	return updatedAppointmentType, nil
+}
+
+func (s *service) Patch(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error) {
+	if appointmentType.Duration < 0 {
+		return domain.AppointmentType{}, errors.New("duration must be positive")
+	}
+	if err := s.normalize(id, &appointmentType); err != nil {
+		return domain.AppointmentType{}, err
+	}
+	updatedAppointmentType, err := s.r.Patch(id, appointmentType)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	return updatedAppointmentType, nil
+}
+
+func (s *service) Delete(id int) error {
+	err := s.r.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalize trims the name, lower-cases the colour and the equipment and
+// rounds the price to cents. It rejects a negative price, a colour other
+// than "#rrggbb" and a name already used by another type.
+func (s *service) normalize(id int, appointmentType *domain.AppointmentType) error {
+	appointmentType.Name = strings.TrimSpace(appointmentType.Name)
+	appointmentType.Color = strings.ToLower(strings.TrimSpace(appointmentType.Color))
+	if appointmentType.Color != "" && !colorPattern.MatchString(appointmentType.Color) {
+		return errors.New("color must be in the #rrggbb format")
+	}
+	if appointmentType.Price < 0 {
+		return errors.New("price can't be negative")
+	}
+	appointmentType.Price = math.Round(appointmentType.Price*100) / 100
+	if appointmentType.Equipment != nil {
+		equipment, err := domain.NormalizeEquipment(appointmentType.Equipment)
+		if err != nil {
+			return err
+		}
+		appointmentType.Equipment = equipment
+	}
+
+	if appointmentType.Name == "" {
+		return nil
+	}
+	appointmentTypes, err := s.r.ReadAll()
+	if err != nil {
+		return err
+	}
+	for i := range appointmentTypes {
+		if appointmentTypes[i].Id != id && strings.EqualFold(appointmentTypes[i].Name, appointmentType.Name) {
+			return errors.New("appointment type name already exists")
+		}
+	}
+	return nil
+}
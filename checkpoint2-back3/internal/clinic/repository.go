@@ -0,0 +1,71 @@
+package clinic
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/store"
+)
+
+type Repository interface {
+	ReadById(id int) (domain.Clinic, error)
+	ReadAll() ([]domain.Clinic, error)
+	Create(clinic domain.Clinic) (domain.Clinic, error)
+	Update(id int, clinic domain.Clinic) (domain.Clinic, error)
+	Patch(id int, clinic domain.Clinic) (domain.Clinic, error)
+	Delete(id int) error
+}
+
+type repository struct {
+	storage store.ClinicStoreInterface
+}
+
+func NewRepository(storage store.ClinicStoreInterface) Repository {
+	return &repository{storage}
+}
+
+func (r *repository) ReadById(id int) (domain.Clinic, error) {
+	clinic, err := r.storage.ReadById(id)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	return clinic, nil
+}
+
+func (r *repository) ReadAll() ([]domain.Clinic, error) {
+	clinics, err := r.storage.ReadAll()
+	if err != nil {
+		return []domain.Clinic{}, err
+	}
+	return clinics, nil
+}
+
+func (r *repository) Create(c domain.Clinic) (domain.Clinic, error) {
+	clinic, err := r.storage.Create(c)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	return clinic, nil
+}
+
+func (r *repository) Update(id int, c domain.Clinic) (domain.Clinic, error) {
+	clinic, err := r.storage.Update(id, c)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	return clinic, nil
+}
+
+func (r *repository) Patch(id int, c domain.Clinic) (domain.Clinic, error) {
+	clinic, err := r.storage.Patch(id, c)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	return clinic, nil
+}
+
+func (r *repository) Delete(id int) error {
+	err := r.storage.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package clinic
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/timezone"
+)
+
+type Service interface {
+	ReadById(id int) (domain.Clinic, error)
+	ReadAll() ([]domain.Clinic, error)
+	Create(clinic domain.Clinic) (domain.Clinic, error)
+	Update(id int, clinic domain.Clinic) (domain.Clinic, error)
+	Patch(id int, clinic domain.Clinic) (domain.Clinic, error)
+	Delete(id int) error
+}
+
+type service struct {
+	r Repository
+}
+
+func NewService(r Repository) Service {
+	return &service{r}
+}
+
+func (s *service) ReadById(id int) (domain.Clinic, error) {
+	clinic, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	return clinic, nil
+}
+
+func (s *service) ReadAll() ([]domain.Clinic, error) {
+	clinics, err := s.r.ReadAll()
+	if err != nil {
+		return []domain.Clinic{}, err
+	}
+	return clinics, nil
+}
+
+func (s *service) Create(clinic domain.Clinic) (domain.Clinic, error) {
+	if clinic.TimeZone == "" {
+		clinic.TimeZone = timezone.Default
+	}
+	if err := s.check(clinic); err != nil {
+		return domain.Clinic{}, err
+	}
+	createdClinic, err := s.r.Create(clinic)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	return createdClinic, nil
+}
+
+func (s *service) Update(id int, clinic domain.Clinic) (domain.Clinic, error) {
+	if clinic.TimeZone == "" {
+		clinic.TimeZone = timezone.Default
+	}
+	if err := s.check(clinic); err != nil {
+		return domain.Clinic{}, err
+	}
+	updatedClinic, err := s.r.Update(id, clinic)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	return updatedClinic, nil
+}
+
+func (s *service) Patch(id int, clinic domain.Clinic) (domain.Clinic, error) {
+	if err := s.check(clinic); err != nil {
+		return domain.Clinic{}, err
+	}
+	updatedClinic, err := s.r.Patch(id, clinic)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	return updatedClinic, nil
+}
+
+func (s *service) Delete(id int) error {
+	err := s.r.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// check rejects an unknown time zone. The database rejects a name already
+// used by another clinic.
+func (s *service) check(clinic domain.Clinic) error {
+	if err := timezone.Validate(clinic.TimeZone); err != nil {
+		return &domain.ValidationError{Reason: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package closure
+
+import (
+	"checkpoint2/internal/domain"
+	"sort"
+	"time"
+)
+
+// Occurrences expands closures into the concrete ones touching the dates
+// from to to, both inclusive, that apply to idClinic. Closures of every
+// clinic always apply; with idClinic 0 only those do. Yearly closures yield
+// one occurrence per year, on the last day of February for a closure on the
+// 29th in the years that have none.
+func Occurrences(closures []domain.Closure, from time.Time, to time.Time, idClinic int) []domain.Closure {
+	fromDate, toDate := dateOf(from), dateOf(to)
+
+	occurrences := []domain.Closure{}
+	for _, closure := range closures {
+		if closure.ClinicId != 0 && closure.ClinicId != idClinic {
+			continue
+		}
+		if closure.Recurrence != domain.ClosureYearly {
+			if !closure.From.After(toDate) && !closure.To.Before(fromDate) {
+				occurrences = append(occurrences, closure)
+			}
+			continue
+		}
+
+		// A closure crossing the new year may start in the year before from.
+		first := fromDate.Year() - 1
+		if first < closure.From.Year() {
+			first = closure.From.Year()
+		}
+		for year := first; year <= toDate.Year(); year++ {
+			occurrence := closure
+			occurrence.From = anniversary(closure.From, year-closure.From.Year())
+			occurrence.To = anniversary(closure.To, year-closure.From.Year())
+			if !occurrence.From.After(toDate) && !occurrence.To.Before(fromDate) {
+				occurrences = append(occurrences, occurrence)
+			}
+		}
+	}
+	sortByDate(occurrences)
+	return occurrences
+}
+
+// Period returns the instants a closure occurrence covers in loc, from the
+// start of its first day to the end of its last.
+func Period(closure domain.Closure, loc *time.Location) domain.Slot {
+	return domain.Slot{
+		Start: time.Date(closure.From.Year(), closure.From.Month(), closure.From.Day(), 0, 0, 0, 0, loc),
+		End:   time.Date(closure.To.Year(), closure.To.Month(), closure.To.Day()+1, 0, 0, 0, 0, loc),
+	}
+}
+
+// anniversary returns date moved by years, keeping its month: a day the
+// month lacks in the new year, such as the 29th of February, becomes the
+// month's last day.
+func anniversary(date time.Time, years int) time.Time {
+	year, month, day := date.Year()+years, date.Month(), date.Day()
+	if last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, date.Location())
+}
+
+// dateOf drops the clock of t, keeping its calendar date in its own
+// location, as a UTC midnight like the dates read from the database.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func sortByDate(closures []domain.Closure) {
+	sort.SliceStable(closures, func(i, j int) bool { return closures[i].From.Before(closures[j].From) })
+}
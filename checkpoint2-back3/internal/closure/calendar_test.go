@@ -0,0 +1,50 @@
+package closure
+
+import (
+	"checkpoint2/internal/domain"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOccurrences(t *testing.T) {
+	closures := []domain.Closure{
+		{Id: 1, Reason: "leap day", From: date(2024, time.February, 29), To: date(2024, time.February, 29), Recurrence: domain.ClosureYearly},
+		{Id: 2, Reason: "new year", From: date(2023, time.December, 31), To: date(2024, time.January, 1), Recurrence: domain.ClosureYearly},
+		{Id: 3, ClinicId: 7, Reason: "works", From: date(2025, time.March, 10), To: date(2025, time.March, 12)},
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		idClinic int
+		want     []string
+	}{
+		{"leap day in a leap year", date(2028, time.February, 1), date(2028, time.March, 31), 0, []string{"2028-02-29/2028-02-29"}},
+		{"leap day in a common year", date(2025, time.February, 1), date(2025, time.March, 31), 0, []string{"2025-02-28/2025-02-28"}},
+		{"not on the first of march", date(2025, time.March, 1), date(2025, time.March, 1), 0, []string{}},
+		{"crossing the new year", date(2026, time.January, 1), date(2026, time.January, 1), 0, []string{"2025-12-31/2026-01-01"}},
+		{"other clinic's closure", date(2025, time.March, 11), date(2025, time.March, 11), 0, []string{}},
+		{"own clinic's closure", date(2025, time.March, 11), date(2025, time.March, 11), 7, []string{"2025-03-10/2025-03-12"}},
+		{"before the first year", date(2023, time.February, 1), date(2023, time.March, 31), 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, occurrence := range Occurrences(closures, tt.from, tt.to, tt.idClinic) {
+				got = append(got, occurrence.From.Format("2006-01-02")+"/"+occurrence.To.Format("2006-01-02"))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package closure
+
+import (
+	"checkpoint2/internal/domain"
+	"time"
+)
+
+// Easter returns Easter Sunday of year in the Gregorian calendar, using the
+// anonymous Gregorian (Meeus/Jones/Butcher) algorithm.
+func Easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// NationalHolidays returns the Brazilian national holidays of year as
+// one-off closures, Carnival included, sorted by date. The movable ones are
+// counted from Easter.
+func NationalHolidays(year int) []domain.Closure {
+	easter := Easter(year)
+	fixed := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	holidays := []domain.Closure{
+		{Reason: "Confraternização Universal", From: fixed(time.January, 1)},
+		{Reason: "Carnaval", From: easter.AddDate(0, 0, -48), To: easter.AddDate(0, 0, -47)},
+		{Reason: "Paixão de Cristo", From: easter.AddDate(0, 0, -2)},
+		{Reason: "Tiradentes", From: fixed(time.April, 21)},
+		{Reason: "Dia do Trabalho", From: fixed(time.May, 1)},
+		{Reason: "Corpus Christi", From: easter.AddDate(0, 0, 60)},
+		{Reason: "Independência do Brasil", From: fixed(time.September, 7)},
+		{Reason: "Nossa Senhora Aparecida", From: fixed(time.October, 12)},
+		{Reason: "Finados", From: fixed(time.November, 2)},
+		{Reason: "Proclamação da República", From: fixed(time.November, 15)},
+	}
+	// Consciência Negra became a national holiday with Law 14.759/2023.
+	if year >= 2024 {
+		holidays = append(holidays, domain.Closure{Reason: "Dia Nacional de Zumbi e da Consciência Negra", From: fixed(time.November, 20)})
+	}
+	holidays = append(holidays, domain.Closure{Reason: "Natal", From: fixed(time.December, 25)})
+
+	for i := range holidays {
+		if holidays[i].To.IsZero() {
+			holidays[i].To = holidays[i].From
+		}
+	}
+	sortByDate(holidays)
+	return holidays
+}
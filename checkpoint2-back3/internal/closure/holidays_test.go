@@ -0,0 +1,64 @@
+package closure
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	tests := []struct {
+		year int
+		want string
+	}{
+		{1818, "1818-03-22"},
+		{2000, "2000-04-23"},
+		{2008, "2008-03-23"},
+		{2011, "2011-04-24"},
+		{2019, "2019-04-21"},
+		{2024, "2024-03-31"},
+		{2025, "2025-04-20"},
+		{2026, "2026-04-05"},
+		{2038, "2038-04-25"},
+		{2285, "2285-03-22"},
+	}
+	for _, tt := range tests {
+		if got := Easter(tt.year).Format("2006-01-02"); got != tt.want {
+			t.Errorf("Easter(%d) = %s, want %s", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestNationalHolidays(t *testing.T) {
+	tests := []struct {
+		year     int
+		reason   string
+		from, to string
+	}{
+		{2024, "Carnaval", "2024-02-12", "2024-02-13"},
+		{2024, "Paixão de Cristo", "2024-03-29", "2024-03-29"},
+		{2024, "Corpus Christi", "2024-05-30", "2024-05-30"},
+		{2025, "Carnaval", "2025-03-03", "2025-03-04"},
+		{2025, "Dia Nacional de Zumbi e da Consciência Negra", "2025-11-20", "2025-11-20"},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, holiday := range NationalHolidays(tt.year) {
+			if holiday.Reason != tt.reason {
+				continue
+			}
+			found = true
+			if from, to := holiday.From.Format("2006-01-02"), holiday.To.Format("2006-01-02"); from != tt.from || to != tt.to {
+				t.Errorf("%s %d = %s to %s, want %s to %s", tt.reason, tt.year, from, to, tt.from, tt.to)
+			}
+		}
+		if !found {
+			t.Errorf("%s missing from %d", tt.reason, tt.year)
+		}
+	}
+
+	for _, holiday := range NationalHolidays(2023) {
+		if holiday.From.Month() == time.November && holiday.From.Day() == 20 {
+			t.Errorf("2023 has %s, which became a holiday in 2024", holiday.Reason)
+		}
+	}
+}
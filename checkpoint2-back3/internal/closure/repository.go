@@ -0,0 +1,72 @@
+package closure
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/store"
+	"time"
+)
+
+type Repository interface {
+	ReadById(id int) (domain.Closure, error)
+	ReadAll() ([]domain.Closure, error)
+	ReadBetween(from time.Time, to time.Time, idClinic int) ([]domain.Closure, error)
+	Create(closure domain.Closure) (domain.Closure, error)
+	Update(id int, closure domain.Closure) (domain.Closure, error)
+	Delete(id int) error
+}
+
+type repository struct {
+	storage store.ClosureStoreInterface
+}
+
+func NewRepository(storage store.ClosureStoreInterface) Repository {
+	return &repository{storage}
+}
+
+func (r *repository) ReadById(id int) (domain.Closure, error) {
+	closure, err := r.storage.ReadById(id)
+	if err != nil {
+		return domain.Closure{}, err
+	}
+	return closure, nil
+}
+
+func (r *repository) ReadAll() ([]domain.Closure, error) {
+	closures, err := r.storage.ReadAll()
+	if err != nil {
+		return []domain.Closure{}, err
+	}
+	return closures, nil
+}
+
+func (r *repository) ReadBetween(from time.Time, to time.Time, idClinic int) ([]domain.Closure, error) {
+	closures, err := r.storage.ReadBetween(from, to, idClinic)
+	if err != nil {
+		return []domain.Closure{}, err
+	}
+	return closures, nil
+}
+
+func (r *repository) Create(c domain.Closure) (domain.Closure, error) {
+	closure, err := r.storage.Create(c)
+	if err != nil {
+		return domain.Closure{}, err
+	}
+	return closure, nil
+}
+
+func (r *repository) Update(id int, c domain.Closure) (domain.Closure, error) {
+	closure, err := r.storage.Update(id, c)
+	if err != nil {
+		return domain.Closure{}, err
+	}
+	return closure, nil
+}
+
+func (r *repository) Delete(id int) error {
+	err := r.storage.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package closure
+
+import (
+	"checkpoint2/internal/clinic"
+	"checkpoint2/internal/domain"
+	"errors"
+	"strings"
+	"time"
+)
+
+type Service interface {
+	ReadById(id int) (domain.Closure, error)
+	ReadAll(idClinic int) ([]domain.Closure, error)
+	Between(from time.Time, to time.Time, idClinic int) ([]domain.Closure, error)
+	Create(closure domain.Closure) (domain.Closure, error)
+	Update(id int, closure domain.Closure) (domain.Closure, error)
+	Delete(id int) error
+	CreateHolidays(year int, idClinic int) ([]domain.Closure, error)
+}
+
+type service struct {
+	r       Repository
+	clinics clinic.Repository
+}
+
+func NewService(r Repository, clinics clinic.Repository) Service {
+	return &service{r, clinics}
+}
+
+func (s *service) ReadById(id int) (domain.Closure, error) {
+	closure, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Closure{}, err
+	}
+	return closure, nil
+}
+
+// ReadAll lists the registered closures, only those applying to idClinic
+// when it is set.
+func (s *service) ReadAll(idClinic int) ([]domain.Closure, error) {
+	closures, err := s.r.ReadAll()
+	if err != nil {
+		return []domain.Closure{}, err
+	}
+	if idClinic == 0 {
+		return closures, nil
+	}
+	applying := []domain.Closure{}
+	for _, closure := range closures {
+		if closure.ClinicId == 0 || closure.ClinicId == idClinic {
+			applying = append(applying, closure)
+		}
+	}
+	return applying, nil
+}
+
+// Between returns the closure occurrences touching the dates from to to
+// that apply to idClinic.
+func (s *service) Between(from time.Time, to time.Time, idClinic int) ([]domain.Closure, error) {
+	closures, err := s.r.ReadBetween(from, to, idClinic)
+	if err != nil {
+		return []domain.Closure{}, err
+	}
+	return Occurrences(closures, from, to, idClinic), nil
+}
+
+func (s *service) Create(closure domain.Closure) (domain.Closure, error) {
+	if err := s.validate(&closure); err != nil {
+		return domain.Closure{}, err
+	}
+	createdClosure, err := s.r.Create(closure)
+	if err != nil {
+		return domain.Closure{}, err
+	}
+	return createdClosure, nil
+}
+
+func (s *service) Update(id int, closure domain.Closure) (domain.Closure, error) {
+	if err := s.validate(&closure); err != nil {
+		return domain.Closure{}, err
+	}
+	updatedClosure, err := s.r.Update(id, closure)
+	if err != nil {
+		return domain.Closure{}, err
+	}
+	return updatedClosure, nil
+}
+
+func (s *service) Delete(id int) error {
+	err := s.r.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateHolidays registers the national holidays of year for idClinic, or
+// for every clinic when it is zero. Holidays already registered with the
+// same reason and date are skipped, so that it can be run again safely.
+func (s *service) CreateHolidays(year int, idClinic int) ([]domain.Closure, error) {
+	if idClinic != 0 {
+		if _, err := s.clinics.ReadById(idClinic); err != nil {
+			return []domain.Closure{}, err
+		}
+	}
+	existing, err := s.r.ReadAll()
+	if err != nil {
+		return []domain.Closure{}, err
+	}
+
+	created := []domain.Closure{}
+	for _, holiday := range NationalHolidays(year) {
+		holiday.ClinicId = idClinic
+		if registered(existing, holiday) {
+			continue
+		}
+		createdClosure, err := s.r.Create(holiday)
+		if err != nil {
+			return created, err
+		}
+		created = append(created, createdClosure)
+	}
+	return created, nil
+}
+
+// validate trims the reason, defaults To to a single day and checks the
+// recurrence and the clinic.
+func (s *service) validate(closure *domain.Closure) error {
+	closure.Reason = strings.TrimSpace(closure.Reason)
+	if closure.Reason == "" {
+		return errors.New("reason can't be empty")
+	}
+	if closure.From.IsZero() {
+		return errors.New("from can't be empty")
+	}
+	if closure.To.IsZero() {
+		closure.To = closure.From
+	}
+	if closure.To.Before(closure.From) {
+		return errors.New("to can't be before from")
+	}
+	switch closure.Recurrence {
+	case domain.ClosureOnce:
+	case domain.ClosureYearly:
+		if !closure.From.AddDate(1, 0, 0).After(closure.To) {
+			return errors.New("a yearly closure must be shorter than a year")
+		}
+	default:
+		return errors.New("recurrence must be empty or yearly")
+	}
+	if closure.ClinicId != 0 {
+		if _, err := s.clinics.ReadById(closure.ClinicId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func registered(closures []domain.Closure, closure domain.Closure) bool {
+	for _, c := range closures {
+		if c.ClinicId == closure.ClinicId && c.Reason == closure.Reason && c.From.Equal(closure.From) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,16 +3,27 @@ package dentist
 import (
 	"checkpoint2/internal/domain"
 	"checkpoint2/pkg/store"
+	"time"
 )
 
 type Repository interface {
 	ReadById(id int) (domain.Dentist, error)
 	ReadAll() ([]domain.Dentist, error)
+	Search(filter domain.DentistFilter) ([]domain.Dentist, int, error)
 	ReadByRegistration(registration string) (domain.Dentist, error)
 	Create(dentist domain.Dentist) (domain.Dentist, error)
 	Update(id int, dentist domain.Dentist) (domain.Dentist, error)
 	Patch(id int, dentist domain.Dentist) (domain.Dentist, error)
 	Delete(id int) error
+	ReadCalendarToken(id int) (string, error)
+	UpdateCalendarToken(id int, token string) error
+	ReadSchedule(id int) ([]domain.WorkingHours, error)
+	UpdateSchedule(id int, schedule []domain.WorkingHours) ([]domain.WorkingHours, error)
+	UpdateClinics(id int, clinicIds []int) ([]int, error)
+	ReadByClinic(idClinic int) ([]domain.Dentist, error)
+	ReadTimeOff(id int, from time.Time, to time.Time) ([]domain.TimeOff, error)
+	CreateTimeOff(timeOff domain.TimeOff) (domain.TimeOff, error)
+	DeleteTimeOff(id int, idTimeOff int) error
 }
 
 type repository struct {
@@ -40,6 +51,14 @@ func (r *repository) ReadAll() ([]domain.Dentist, error) {
 	return dentists, nil
 }
 
+func (r *repository) Search(filter domain.DentistFilter) ([]domain.Dentist, int, error) {
+	dentists, total, err := r.storage.Search(filter)
+	if err != nil {
+		return []domain.Dentist{}, 0, err
+	}
+	return dentists, total, nil
+}
+
 func (r *repository) ReadByRegistration(registration string) (domain.Dentist, error) {
 	dentist, err := r.storage.ReadByRegistration(registration)
 	if err != nil {
@@ -80,4 +99,76 @@ func (r *repository) Delete(id int) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *repository) ReadSchedule(id int) ([]domain.WorkingHours, error) {
+	schedule, err := r.storage.ReadSchedule(id)
+	if err != nil {
+		return []domain.WorkingHours{}, err
+	}
+	return schedule, nil
+}
+
+func (r *repository) UpdateSchedule(id int, schedule []domain.WorkingHours) ([]domain.WorkingHours, error) {
+	updatedSchedule, err := r.storage.UpdateSchedule(id, schedule)
+	if err != nil {
+		return []domain.WorkingHours{}, err
+	}
+	return updatedSchedule, nil
+}
+
+func (r *repository) UpdateClinics(id int, clinicIds []int) ([]int, error) {
+	updatedClinicIds, err := r.storage.UpdateClinics(id, clinicIds)
+	if err != nil {
+		return []int{}, err
+	}
+	return updatedClinicIds, nil
+}
+
+func (r *repository) ReadByClinic(idClinic int) ([]domain.Dentist, error) {
+	dentists, err := r.storage.ReadByClinic(idClinic)
+	if err != nil {
+		return []domain.Dentist{}, err
+	}
+	return dentists, nil
+}
+
+func (r *repository) ReadTimeOff(id int, from time.Time, to time.Time) ([]domain.TimeOff, error) {
+	timeOffs, err := r.storage.ReadTimeOff(id, from, to)
+	if err != nil {
+		return []domain.TimeOff{}, err
+	}
+	return timeOffs, nil
+}
+
+func (r *repository) CreateTimeOff(t domain.TimeOff) (domain.TimeOff, error) {
+	timeOff, err := r.storage.CreateTimeOff(t)
+	if err != nil {
+		return domain.TimeOff{}, err
+	}
+	return timeOff, nil
+}
+
+func (r *repository) DeleteTimeOff(id int, idTimeOff int) error {
+	err := r.storage.DeleteTimeOff(id, idTimeOff)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ReadCalendarToken(id int) (string, error) {
+	token, err := r.storage.ReadCalendarToken(id)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *repository) UpdateCalendarToken(id int, token string) error {
+	err := r.storage.UpdateCalendarToken(id, token)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package dentist
+
+import (
+	"checkpoint2/internal/domain"
+	"time"
+)
+
+const clockLayout = "15:04"
+
+// normalizeSchedule checks the working hours and rewrites their times in the
+// zero-padded hh:mm form, so that "8:00" is kept as "08:00". Blocks of the
+// same weekday can't overlap, though one may start as another ends.
+func normalizeSchedule(schedule []domain.WorkingHours) error {
+	type period struct{ start, end time.Time }
+	periods := make([]period, len(schedule))
+	for i, workingHours := range schedule {
+		if workingHours.Weekday < time.Sunday || workingHours.Weekday > time.Saturday {
+			return &domain.ValidationError{Reason: "weekday must be between 0 (sunday) and 6 (saturday)"}
+		}
+		start, err := time.Parse(clockLayout, workingHours.Start)
+		if err != nil {
+			return &domain.ValidationError{Reason: "start must be in the hh:mm format"}
+		}
+		end, err := time.Parse(clockLayout, workingHours.End)
+		if err != nil {
+			return &domain.ValidationError{Reason: "end must be in the hh:mm format"}
+		}
+		if !end.After(start) {
+			return &domain.ValidationError{Reason: "end must be after start"}
+		}
+		for j, other := range periods[:i] {
+			if schedule[j].Weekday == workingHours.Weekday && other.start.Before(end) && start.Before(other.end) {
+				return &domain.ValidationError{Reason: "working hours can't overlap"}
+			}
+		}
+		periods[i] = period{start, end}
+		schedule[i].Start, schedule[i].End = start.Format(clockLayout), end.Format(clockLayout)
+	}
+	return nil
+}
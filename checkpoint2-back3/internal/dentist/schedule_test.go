@@ -0,0 +1,56 @@
+package dentist
+
+import (
+	"checkpoint2/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNormalizeSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule []domain.WorkingHours
+		want     []domain.WorkingHours
+		wantErr  bool
+	}{
+		{"padded",
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "8:00", End: "12:00"}},
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "08:00", End: "12:00"}}, false},
+		{"split day",
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "08:00", End: "12:00"}, {Weekday: time.Monday, Start: "12:00", End: "17:00"}},
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "08:00", End: "12:00"}, {Weekday: time.Monday, Start: "12:00", End: "17:00"}}, false},
+		{"same hours on other days",
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "08:00", End: "12:00"}, {Weekday: time.Tuesday, Start: "08:00", End: "12:00"}},
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "08:00", End: "12:00"}, {Weekday: time.Tuesday, Start: "08:00", End: "12:00"}}, false},
+		{"overlapping",
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "08:00", End: "12:00"}, {Weekday: time.Monday, Start: "9:00", End: "13:00"}}, nil, true},
+		{"overlap across padding",
+			[]domain.WorkingHours{{Weekday: time.Friday, Start: "13:00", End: "18:00"}, {Weekday: time.Friday, Start: "9:00", End: "14:00"}}, nil, true},
+		{"end before start",
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "12:00", End: "08:00"}}, nil, true},
+		{"empty period",
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "08:00", End: "08:00"}}, nil, true},
+		{"bad clock",
+			[]domain.WorkingHours{{Weekday: time.Monday, Start: "25:00", End: "26:00"}}, nil, true},
+		{"bad weekday",
+			[]domain.WorkingHours{{Weekday: 7, Start: "08:00", End: "12:00"}}, nil, true},
+	}
+	for _, tt := range tests {
+		err := normalizeSchedule(tt.schedule)
+		var validation *domain.ValidationError
+		if errors.As(err, &validation) != tt.wantErr || !tt.wantErr && err != nil {
+			t.Errorf("%s: normalizeSchedule() error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		for i := range tt.want {
+			if tt.schedule[i] != tt.want[i] {
+				t.Errorf("%s: normalizeSchedule() = %v, want %v", tt.name, tt.schedule, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -1,26 +1,47 @@
 package dentist
 
 import (
+	"checkpoint2/internal/clinic"
 	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/search"
+	"checkpoint2/pkg/timezone"
+	"checkpoint2/pkg/token"
 	"errors"
+	"math"
+	"sync"
+	"time"
 )
 
 type Service interface {
 	ReadById(id int) (domain.Dentist, error)
 	ReadAll() ([]domain.Dentist, error)
+	Search(filter domain.DentistFilter) ([]domain.Dentist, int, error)
+	SearchByName(query string, limit int, idClinic int) ([]domain.Dentist, error)
+	Imported(batch domain.ImportBatch)
 	ReadByRegistration(registration string) (domain.Dentist, error)
 	Create(dentist domain.Dentist) (domain.Dentist, error)
 	Update(id int, dentist domain.Dentist) (domain.Dentist, error)
 	Patch(id int, dentist domain.Dentist) (domain.Dentist, error)
 	Delete(id int) error
+	IssueCalendarToken(id int) (string, error)
+	CheckCalendarToken(id int, token string) error
+	UpdateSchedule(id int, schedule []domain.WorkingHours) ([]domain.WorkingHours, error)
+	UpdateClinics(id int, clinicIds []int) ([]int, error)
+	ReadByClinic(idClinic int) ([]domain.Dentist, error)
+	ReadTimeOff(id int, from time.Time, to time.Time) ([]domain.TimeOff, error)
+	CreateTimeOff(timeOff domain.TimeOff) (domain.TimeOff, error)
+	DeleteTimeOff(id int, idTimeOff int) error
 }
 
 type service struct {
-	r Repository
+	r       Repository
+	clinics clinic.Repository
+	indexMu sync.Mutex
+	index   *search.Index
 }
 
-func NewService(r Repository) Service {
-	return &service{r}
+func NewService(r Repository, clinics clinic.Repository) Service {
+	return &service{r: r, clinics: clinics}
 }
 
 func (s *service) ReadById(id int) (domain.Dentist, error) {
@@ -40,71 +61,61 @@ func (s *service) ReadAll() ([]domain.Dentist, error) {
 	return dentists, nil
 }
 
+func (s *service) Search(filter domain.DentistFilter) ([]domain.Dentist, int, error) {
+	dentists, total, err := s.r.Search(filter)
+	if err != nil {
+		return []domain.Dentist{}, 0, err
+	}
+	return dentists, total, nil
+}
+
 func (s *service) ReadByRegistration(registration string) (domain.Dentist, error) {
 	dentist, err := s.r.ReadByRegistration(registration)
 	if err != nil {
 		return domain.Dentist{}, err
 	}
-	
+
 	return dentist, nil
 }
 
 func (s *service) Create(d domain.Dentist) (domain.Dentist, error) {
-	dentists, err := s.ReadAll()
-	if err != nil {
+	if err := timezone.Validate(d.TimeZone); err != nil {
 		return domain.Dentist{}, err
 	}
 
-	for i := range dentists {
-		if dentists[i].Registration == d.Registration {
-			return domain.Dentist{}, errors.New("registration already exists")
-		}
-	}
-
 	newDentist, err := s.r.Create(d)
 	if err != nil {
 		return domain.Dentist{}, err
 	}
+	s.indexName(newDentist.Id, newDentist)
 
 	return newDentist, nil
 }
 
 func (s *service) Update(id int, d domain.Dentist) (domain.Dentist, error) {
-	dentists, err := s.ReadAll()
-	if err != nil {
+	if err := timezone.Validate(d.TimeZone); err != nil {
 		return domain.Dentist{}, err
 	}
 
-	for i := range dentists {
-		if dentists[i].Registration == d.Registration {
-			return domain.Dentist{}, errors.New("registration already exists")
-		}
-	}
-
 	updatedDentist, err := s.r.Update(id, d)
 	if err != nil {
 		return domain.Dentist{}, err
 	}
-	
+	s.indexName(id, updatedDentist)
+
 	return updatedDentist, nil
 }
 
 func (s *service) Patch(id int, dentist domain.Dentist) (domain.Dentist, error) {
-	dentists, err := s.ReadAll()
-	if err != nil {
+	if err := timezone.Validate(dentist.TimeZone); err != nil {
 		return domain.Dentist{}, err
 	}
 
-	for i := range dentists {
-		if dentists[i].Registration == dentist.Registration {
-			return domain.Dentist{}, errors.New("registration already exists")
-		}
-	}
-
 	updatedDentist, err := s.r.Patch(id, dentist)
 	if err != nil {
 		return domain.Dentist{}, err
 	}
+	s.indexName(id, updatedDentist)
 	return updatedDentist, nil
 }
 
@@ -113,6 +124,184 @@ func (s *service) Delete(id int) error {
 	if err != nil {
 		return err
 	}
+	s.unindexName(id)
+
+	return nil
+}
+
+// UpdateSchedule replaces the dentist's weekly working hours.
+func (s *service) UpdateSchedule(id int, schedule []domain.WorkingHours) ([]domain.WorkingHours, error) {
+	if err := normalizeSchedule(schedule); err != nil {
+		return []domain.WorkingHours{}, err
+	}
+	updatedSchedule, err := s.r.UpdateSchedule(id, schedule)
+	if err != nil {
+		return []domain.WorkingHours{}, err
+	}
+	return updatedSchedule, nil
+}
+
+// UpdateClinics replaces the clinics the dentist works at.
+func (s *service) UpdateClinics(id int, clinicIds []int) ([]int, error) {
+	for _, clinicId := range clinicIds {
+		if _, err := s.clinics.ReadById(clinicId); err != nil {
+			return []int{}, err
+		}
+	}
+	updatedClinicIds, err := s.r.UpdateClinics(id, clinicIds)
+	if err != nil {
+		return []int{}, err
+	}
+	return updatedClinicIds, nil
+}
+
+func (s *service) ReadByClinic(idClinic int) ([]domain.Dentist, error) {
+	if _, err := s.clinics.ReadById(idClinic); err != nil {
+		return []domain.Dentist{}, err
+	}
+	dentists, err := s.r.ReadByClinic(idClinic)
+	if err != nil {
+		return []domain.Dentist{}, err
+	}
+	return dentists, nil
+}
+
+func (s *service) ReadTimeOff(id int, from time.Time, to time.Time) ([]domain.TimeOff, error) {
+	timeOffs, err := s.r.ReadTimeOff(id, from, to)
+	if err != nil {
+		return []domain.TimeOff{}, err
+	}
+	return timeOffs, nil
+}
+
+func (s *service) CreateTimeOff(timeOff domain.TimeOff) (domain.TimeOff, error) {
+	createdTimeOff, err := s.r.CreateTimeOff(timeOff)
+	if err != nil {
+		return domain.TimeOff{}, err
+	}
+	return createdTimeOff, nil
+}
+
+func (s *service) DeleteTimeOff(id int, idTimeOff int) error {
+	err := s.r.DeleteTimeOff(id, idTimeOff)
+	if err != nil {
+		return err
+	}
+	return nil
+}
 
+// IssueCalendarToken replaces the dentist's calendar feed token, revoking
+// the previous one.
+func (s *service) IssueCalendarToken(id int) (string, error) {
+	newToken, err := token.New()
+	if err != nil {
+		return "", err
+	}
+	if err := s.r.UpdateCalendarToken(id, newToken); err != nil {
+		return "", err
+	}
+	return newToken, nil
+}
+
+func (s *service) CheckCalendarToken(id int, calendarToken string) error {
+	persistedToken, err := s.r.ReadCalendarToken(id)
+	if err != nil {
+		return err
+	}
+	if !token.Equal(persistedToken, calendarToken) {
+		return errors.New("invalid calendar token")
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// SearchByName ranks the dentists whose name and surname match query, for
+// type-ahead, keeping those who work at idClinic when it isn't zero. The
+// index behind it is built on first use and then kept in step with the
+// writes and imports of this process.
+func (s *service) SearchByName(query string, limit int, idClinic int) ([]domain.Dentist, error) {
+	index, err := s.nameIndex()
+	if err != nil {
+		return []domain.Dentist{}, err
+	}
+
+	matches := index.Search(query, limit)
+	if idClinic != 0 {
+		matches = index.Search(query, math.MaxInt32)
+	}
+
+	dentists := []domain.Dentist{}
+	for _, match := range matches {
+		if len(dentists) == limit {
+			break
+		}
+		dentist, err := s.r.ReadById(match.Id)
+		if err != nil {
+			return []domain.Dentist{}, err
+		}
+		if idClinic != 0 && !worksAt(dentist, idClinic) {
+			continue
+		}
+		dentists = append(dentists, dentist)
+	}
+	return dentists, nil
+}
+
+// worksAt reports whether the dentist is linked to the clinic.
+func worksAt(dentist domain.Dentist, idClinic int) bool {
+	for _, id := range dentist.ClinicIds {
+		if id == idClinic {
+			return true
+		}
+	}
+	return false
+}
+
+// Imported adds the dentists of an import to the name index.
+func (s *service) Imported(batch domain.ImportBatch) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.index == nil {
+		return
+	}
+	for _, row := range batch.Dentists {
+		if dentist, err := s.r.ReadByRegistration(row.Dentist.Registration); err == nil {
+			s.index.Put(dentist.Id, dentist.Name, dentist.Surname)
+		}
+	}
+}
+
+func (s *service) nameIndex() (*search.Index, error) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.index != nil {
+		return s.index, nil
+	}
+
+	dentists, err := s.r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	index := search.NewIndex()
+	for _, dentist := range dentists {
+		index.Put(dentist.Id, dentist.Name, dentist.Surname)
+	}
+	s.index = index
+	return index, nil
+}
+
+// indexName updates the name index, if it was built already.
+func (s *service) indexName(id int, dentist domain.Dentist) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.index != nil {
+		s.index.Put(id, dentist.Name, dentist.Surname)
+	}
+}
+
+func (s *service) unindexName(id int) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.index != nil {
+		s.index.Remove(id)
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+const (
+	AgendaAppointment = "appointment"
+	AgendaGap         = "gap"
+	AgendaTimeOff     = "time_off"
+)
+
+type Agenda struct {
+	Dentist Dentist     `json:"dentist"`
+	From    time.Time   `json:"from"`
+	To      time.Time   `json:"to"`
+	Days    []AgendaDay `json:"days"`
+}
+
+// AgendaDay lists a day's entries in time order. Gaps are the free parts
+// of the working hours between appointments.
+type AgendaDay struct {
+	Date         string        `json:"date"`
+	WorkingHours []Slot        `json:"working_hours"`
+	Entries      []AgendaEntry `json:"entries"`
+}
+
+type AgendaEntry struct {
+	Kind        string       `json:"kind"`
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	Appointment *Appointment `json:"appointment,omitempty"`
+	Reason      string       `json:"reason,omitempty"`
+}
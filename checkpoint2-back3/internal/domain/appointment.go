@@ -1,9 +1,91 @@
 package domain
 
+import "time"
+
+const (
+	StatusScheduled  = "scheduled"
+	StatusConfirmed  = "confirmed"
+	StatusCheckedIn  = "checked_in"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+	StatusNoShow     = "no_show"
+)
+
+// Appointment times are stored in UTC and read back in TimeZone, the zone of
+// the appointment's clinic or else of its dentist. TypeId refers to the
+// catalogue of appointment types, while Description holds free-text notes.
+// OverrideBy names the staff member who allowed booking a restricted patient.
+// Sequence counts the changes made since the appointment was booked and
+// UpdatedAt is the time of the last one.
 type Appointment struct {
-	Id          int     `json:"id"`
-	Patient     Patient `json:"patient"`
-	Dentist     Dentist `json:"dentist"`
-	Date        string  `json:"date" binding:"required"`
-	Description string  `json:"description" binding:"required"`
+	Id           int           `json:"id"`
+	Patient      Patient       `json:"patient"`
+	Dentist      Dentist       `json:"dentist"`
+	Start        time.Time     `json:"start"`
+	End          time.Time     `json:"end"`
+	Description  string        `json:"description"`
+	Status       string        `json:"status"`
+	SeriesId     int           `json:"series_id,omitempty"`
+	RoomId       int           `json:"room_id,omitempty"`
+	ClinicId     int           `json:"clinic_id,omitempty"`
+	TypeId       int           `json:"type_id,omitempty"`
+	OverrideBy   string        `json:"override_by,omitempty"`
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
+	TimeZone     string        `json:"time_zone,omitempty"`
+	Sequence     int           `json:"-"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// CancelledByPatient is the CancelledBy of cancellations made by the patient,
+// the only ones that can count against them.
+const CancelledByPatient = "patient"
+
+// Cancellation records why, when and by whom an appointment was cancelled.
+// Late is set when the patient cancelled with less notice than the
+// cancellation policy asks for.
+type Cancellation struct {
+	Reason      string    `json:"reason"`
+	CancelledBy string    `json:"cancelled_by"`
+	CancelledAt time.Time `json:"cancelled_at"`
+	Late        bool      `json:"late"`
+}
+
+// Strike counts late cancellations and no-shows against a patient, who is
+// restricted once their strikes reach Threshold. A zero Threshold never
+// restricts.
+type Strike struct {
+	PatientId         int
+	LateCancellations int
+	NoShows           int
+	Threshold         int
+}
+
+const (
+	ChangeReschedule = "reschedule"
+	ChangeUpdate     = "update"
+)
+
+// AppointmentChange keeps the values an appointment had before a reschedule
+// or an update changed its time, dentist or description.
+type AppointmentChange struct {
+	Id            int       `json:"id"`
+	AppointmentId int       `json:"appointment_id"`
+	Kind          string    `json:"kind"`
+	DentistId     int       `json:"dentist_id"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Description   string    `json:"description"`
+	ChangedBy     string    `json:"changed_by,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+type StatusChange struct {
+	Id            int       `json:"id"`
+	AppointmentId int       `json:"appointment_id"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	ChangedBy     string    `json:"changed_by"`
+	ChangedAt     time.Time `json:"changed_at"`
 }
@@ -0,0 +1,15 @@
+package domain
+
+// AppointmentType is an entry of the catalogue of procedures, such as a
+// cleaning, an extraction or a root canal. Duration, in minutes, is given to
+// appointments of the type booked without an end, and Equipment is what the
+// room they take place in must be fitted with. Color is a "#rrggbb" code for
+// calendars and Price is in reais.
+type AppointmentType struct {
+	Id        int      `json:"id"`
+	Name      string   `json:"name" binding:"required"`
+	Duration  int      `json:"duration"`
+	Color     string   `json:"color"`
+	Price     float64  `json:"price"`
+	Equipment []string `json:"equipment"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+// Clinic is a branch of the practice. Dentists may work at several clinics,
+// while patients are shared by all of them. TimeZone is the IANA zone the
+// clinic's appointments and closures are read in.
+type Clinic struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name" binding:"required"`
+	Address  string `json:"address"`
+	TimeZone string `json:"time_zone"`
+}
+
+// ClinicReport counts the appointments of a clinic by status. ClinicId 0
+// gathers the appointments not placed at any clinic.
+type ClinicReport struct {
+	ClinicId   int            `json:"clinic_id"`
+	ClinicName string         `json:"clinic_name"`
+	Total      int            `json:"total"`
+	ByStatus   map[string]int `json:"by_status"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+const (
+	ClosureOnce   = ""
+	ClosureYearly = "yearly"
+)
+
+// Closure is a period, in whole days, in which no appointments can be
+// booked. From and To are inclusive dates. ClinicId 0 closes every clinic.
+// A yearly closure repeats on the same dates every year from From's year
+// on.
+type Closure struct {
+	Id         int       `json:"id"`
+	ClinicId   int       `json:"clinic_id,omitempty"`
+	Reason     string    `json:"reason"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Recurrence string    `json:"recurrence,omitempty"`
+}
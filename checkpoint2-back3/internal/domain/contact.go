@@ -0,0 +1,42 @@
+package domain
+
+const (
+	PhoneMobile = "mobile"
+	PhoneHome   = "home"
+	PhoneWork   = "work"
+)
+
+// Contacts are the ways to reach a patient. Address and EmergencyContact are
+// nil until registered.
+type Contacts struct {
+	Email            string            `json:"email,omitempty"`
+	Phones           []Phone           `json:"phones"`
+	Address          *Address          `json:"address,omitempty"`
+	EmergencyContact *EmergencyContact `json:"emergency_contact,omitempty"`
+}
+
+// Phone numbers are kept as digits only: the area code and the number,
+// without the country code.
+type Phone struct {
+	Id     int    `json:"id"`
+	Type   string `json:"type" binding:"required"`
+	Number string `json:"number" binding:"required"`
+}
+
+// Address is a Brazilian postal address. CEP is kept as 00000-000 and State
+// as the two-letter code of the federative unit.
+type Address struct {
+	Street     string `json:"street"`
+	Number     string `json:"number"`
+	Complement string `json:"complement,omitempty"`
+	District   string `json:"district"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	CEP        string `json:"cep"`
+}
+
+type EmergencyContact struct {
+	Name         string `json:"name"`
+	Relationship string `json:"relationship,omitempty"`
+	Phone        string `json:"phone"`
+}
@@ -1,8 +1,11 @@
 package domain
 
 type Dentist struct {
-	Id           int    `json:"id"`
-	Surname      string `json:"surname" binding:"required"`
-	Name         string `json:"name" binding:"required"`
-	Registration string `json:"registration" binding:"required"`
+	Id           int            `json:"id"`
+	Surname      string         `json:"surname" binding:"required"`
+	Name         string         `json:"name" binding:"required"`
+	Registration string         `json:"registration" binding:"required"`
+	Schedule     []WorkingHours `json:"schedule,omitempty"`
+	ClinicIds    []int          `json:"clinic_ids,omitempty"`
+	TimeZone     string         `json:"time_zone,omitempty"`
 }
@@ -0,0 +1,11 @@
+package domain
+
+// ValidationError is returned when a value sent by the client breaks one of
+// the rules of the domain.
+type ValidationError struct {
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
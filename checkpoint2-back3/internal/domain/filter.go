@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// AppointmentFilter narrows an appointment search. Zero values are ignored.
+// Sort is a field name optionally prefixed with "-" for descending order.
+type AppointmentFilter struct {
+	DentistId           int
+	DentistRegistration string
+	PatientId           int
+	PatientRG           string
+	From                time.Time
+	To                  time.Time
+	Text                string
+	Status              string
+	ClinicId            int
+	TypeId              int
+	Sort                string
+	Limit               int
+	Offset              int
+}
+
+// PatientFilter narrows a patient listing. Zero values are ignored. Name
+// matches the start of the name, and Sort works as in AppointmentFilter. With
+// After set the listing continues after that row and Offset is ignored.
+type PatientFilter struct {
+	Name   string
+	RG     string
+	Sort   string
+	Limit  int
+	Offset int
+	After  Cursor
+}
+
+// DentistFilter narrows a dentist listing the way PatientFilter does.
+type DentistFilter struct {
+	Name         string
+	Registration string
+	ClinicId     int
+	Sort         string
+	Limit        int
+	Offset       int
+	After        Cursor
+}
+
+// Cursor marks the last row of a page by its value in the sorted column and
+// its id. A zero Id marks no row.
+type Cursor struct {
+	Value string
+	Id    int
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// ImportAppointment is an appointment read from an import file. The patient
+// and the dentist are referenced by RG and registration, and may be created
+// by the same import.
+type ImportAppointment struct {
+	Row                 int       `json:"row"`
+	PatientRG           string    `json:"patient_rg"`
+	DentistRegistration string    `json:"dentist_registration"`
+	Start               time.Time `json:"start"`
+	End                 time.Time `json:"end"`
+	Description         string    `json:"description"`
+	ClinicId            int       `json:"clinic_id,omitempty"`
+	RoomId              int       `json:"room_id,omitempty"`
+}
+
+type ImportPatient struct {
+	Row     int     `json:"row"`
+	Patient Patient `json:"patient"`
+}
+
+type ImportDentist struct {
+	Row     int     `json:"row"`
+	Dentist Dentist `json:"dentist"`
+}
+
+type ImportBatch struct {
+	Patients     []ImportPatient     `json:"patients"`
+	Dentists     []ImportDentist     `json:"dentists"`
+	Appointments []ImportAppointment `json:"appointments"`
+}
+
+// ImportRowError points at the row of an import file that failed validation
+// or conflicts with existing data. Row 0 refers to the whole file.
+type ImportRowError struct {
+	File    string `json:"file"`
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	DryRun       bool             `json:"dry_run"`
+	Committed    bool             `json:"committed"`
+	Patients     int              `json:"patients"`
+	Dentists     int              `json:"dentists"`
+	Appointments int              `json:"appointments"`
+	Errors       []ImportRowError `json:"errors"`
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+const (
+	ToothHealthy     = "healthy"
+	ToothCaries      = "caries"
+	ToothRestoration = "restoration"
+	ToothMissing     = "missing"
+	ToothImplant     = "implant"
+	ToothCrown       = "crown"
+)
+
+const (
+	SurfaceMesial   = "mesial"
+	SurfaceDistal   = "distal"
+	SurfaceBuccal   = "buccal"
+	SurfaceLingual  = "lingual"
+	SurfaceOcclusal = "occlusal"
+	SurfaceIncisal  = "incisal"
+)
+
+// ToothChange is a condition found or treated on a tooth during an
+// appointment, on one of its surfaces or, without Surface, on the tooth as a
+// whole. Teeth are numbered in FDI notation: the quadrant, 1 to 4 for
+// permanent teeth and 5 to 8 for deciduous ones, followed by the position
+// from the midline. MadeAt is the start of the appointment.
+type ToothChange struct {
+	Id            int       `json:"id"`
+	PatientId     int       `json:"patient_id"`
+	AppointmentId int       `json:"appointment_id"`
+	Tooth         int       `json:"tooth"`
+	Surface       string    `json:"surface,omitempty"`
+	Condition     string    `json:"condition"`
+	Notes         string    `json:"notes,omitempty"`
+	MadeAt        time.Time `json:"made_at"`
+}
+
+// Odontogram is the chart of a patient's teeth as of At. Only the teeth with
+// something recorded on them are listed.
+type Odontogram struct {
+	PatientId int       `json:"patient_id"`
+	At        time.Time `json:"at"`
+	Teeth     []Tooth   `json:"teeth"`
+}
+
+// Tooth is the condition of a tooth as a whole and of each of its surfaces,
+// along with the appointment that last changed it.
+type Tooth struct {
+	Number        int               `json:"number"`
+	Condition     string            `json:"condition"`
+	Surfaces      map[string]string `json:"surfaces,omitempty"`
+	AppointmentId int               `json:"appointment_id"`
+	ChangedAt     time.Time         `json:"changed_at"`
+}
@@ -1,9 +1,60 @@
 package domain
 
+import "time"
+
+const (
+	BirthDateLayout = "2006-01-02"
+	AdultAge        = 18
+)
+
+// Patient counts the late cancellations and no-shows of its appointments.
+// Restricted is set once they reach the cancellation policy's threshold, and
+// from then on booking the patient requires a staff override. Age is computed
+// from BirthDate when it is known. Contacts and Guardians are only filled in
+// when reading a single patient.
 type Patient struct {
-	Id               int    `json:"id"`
-	Surname          string `json:"surname" binding:"required"`
-	Name             string `json:"name" binding:"required"`
-	RG               string `json:"rg" binding:"required"`
-	RegistrationDate string `json:"registration_date" binding:"required"`
+	Id                int        `json:"id"`
+	Surname           string     `json:"surname" binding:"required"`
+	Name              string     `json:"name" binding:"required"`
+	RG                string     `json:"rg" binding:"required"`
+	CPF               string     `json:"cpf,omitempty"`
+	RegistrationDate  string     `json:"registration_date" binding:"required"`
+	BirthDate         string     `json:"birth_date,omitempty"`
+	Age               *int       `json:"age,omitempty"`
+	LateCancellations int        `json:"late_cancellations"`
+	NoShows           int        `json:"no_shows"`
+	Restricted        bool       `json:"restricted"`
+	Contacts          *Contacts  `json:"contacts,omitempty"`
+	Guardians         []Guardian `json:"guardians,omitempty"`
+}
+
+// Guardian is responsible for a patient, usually a minor. It is either
+// another patient, named by PatientId, or someone reached through Phone and
+// Email.
+type Guardian struct {
+	Id           int    `json:"id"`
+	PatientId    int    `json:"patient_id,omitempty"`
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+	Phone        string `json:"phone,omitempty"`
+	Email        string `json:"email,omitempty"`
+}
+
+// Age is how many full years someone born on birth has on the day of on.
+func Age(birth time.Time, on time.Time) int {
+	years := on.Year() - birth.Year()
+	if on.Month() < birth.Month() || on.Month() == birth.Month() && on.Day() < birth.Day() {
+		years--
+	}
+	return years
+}
+
+// MinorOn tells whether the patient is under age on the day of on. Patients
+// without a birth date are taken as adults.
+func (p Patient) MinorOn(on time.Time) bool {
+	birth, err := time.Parse(BirthDateLayout, p.BirthDate)
+	if err != nil {
+		return false
+	}
+	return Age(birth, on) < AdultAge
 }
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+const (
+	ReminderSending = "sending"
+	ReminderSent    = "sent"
+	ReminderFailed  = "failed"
+	ReminderSkipped = "skipped"
+)
+
+// Reminder records a reminder sent, or being sent, for an appointment at a
+// given offset before its start. Start is the start the reminder was sent
+// for, so that a rescheduled appointment gets reminded again. Its token
+// authorizes the confirm and cancel links of the message until then.
+// ClaimedAt is when the sender last took the reminder on; one left sending
+// for too long is taken to have been dropped and may be claimed again.
+type Reminder struct {
+	Id            int           `json:"id"`
+	AppointmentId int           `json:"appointment_id"`
+	Start         time.Time     `json:"start"`
+	Offset        time.Duration `json:"offset"`
+	Channel       string        `json:"channel"`
+	Token         string        `json:"-"`
+	Status        string        `json:"status"`
+	Error         string        `json:"error,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	ClaimedAt     time.Time     `json:"claimed_at"`
+	SentAt        *time.Time    `json:"sent_at,omitempty"`
+}
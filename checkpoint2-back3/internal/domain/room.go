@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"strings"
+)
+
+// Room is a treatment room or chair where appointments take place.
+// Equipment lists what it is fitted with, such as "x-ray" or "surgery", so
+// that appointments needing it can be matched to a suitable room. ClinicId
+// is the clinic the room belongs to.
+type Room struct {
+	Id        int      `json:"id"`
+	Name      string   `json:"name" binding:"required"`
+	Equipment []string `json:"equipment"`
+	ClinicId  int      `json:"clinic_id,omitempty"`
+}
+
+// HasEquipment reports whether the room is fitted with every item of
+// equipment.
+func (r Room) HasEquipment(equipment []string) bool {
+	for _, required := range equipment {
+		found := false
+		for _, item := range r.Equipment {
+			if item == required {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeEquipment lower-cases and trims every item and drops repeated
+// ones. Empty items and items with a comma are rejected.
+func NormalizeEquipment(equipment []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, item := range equipment {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" || strings.Contains(item, ",") {
+			return nil, &ValidationError{Reason: "invalid equipment " + item}
+		}
+		if !seen[item] {
+			seen[item] = true
+			normalized = append(normalized, item)
+		}
+	}
+	return normalized, nil
+}
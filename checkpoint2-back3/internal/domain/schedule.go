@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// WorkingHours is one block of a dentist's weekly schedule. Start and End
+// are wall-clock times in the "15:04" layout.
+type WorkingHours struct {
+	Weekday time.Weekday `json:"weekday"`
+	Start   string       `json:"start" binding:"required"`
+	End     string       `json:"end" binding:"required"`
+}
+
+type TimeOff struct {
+	Id        int       `json:"id"`
+	DentistId int       `json:"dentist_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Reason    string    `json:"reason"`
+}
+
+// Slot is a free period. RoomId, when set, is a suitable room free for the
+// whole slot.
+type Slot struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	RoomId int       `json:"room_id,omitempty"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Recurrence is the subset of RFC 5545 RRULE supported for appointment
+// series: a weekly or monthly frequency with an interval, bounded by a count
+// of occurrences or an end date.
+type Recurrence struct {
+	Frequency string    `json:"frequency"`
+	Interval  int       `json:"interval"`
+	Count     int       `json:"count,omitempty"`
+	Until     time.Time `json:"until"`
+}
+
+type AppointmentSeries struct {
+	Id           int           `json:"id"`
+	Patient      Patient       `json:"patient"`
+	Dentist      Dentist       `json:"dentist"`
+	Rule         string        `json:"rule"`
+	Description  string        `json:"description"`
+	Appointments []Appointment `json:"appointments"`
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+const (
+	WaitlistWaiting = "waiting"
+	WaitlistOffered = "offered"
+	WaitlistBooked  = "booked"
+	WaitlistLeft    = "left"
+
+	HoldPending  = "pending"
+	HoldClaimed  = "claimed"
+	HoldDeclined = "declined"
+	HoldExpired  = "expired"
+)
+
+// WaitlistEntry is a patient waiting for a slot. DentistId 0 accepts any
+// dentist and ClinicId 0 any clinic. From and To are the inclusive dates the patient can come, and
+// EarliestTime and LatestTime, when set, bound the time of day in the
+// "15:04" layout.
+type WaitlistEntry struct {
+	Id           int        `json:"id"`
+	Patient      Patient    `json:"patient"`
+	DentistId    int        `json:"dentist_id,omitempty"`
+	ClinicId     int        `json:"clinic_id,omitempty"`
+	From         time.Time  `json:"from"`
+	To           time.Time  `json:"to"`
+	EarliestTime string     `json:"earliest_time,omitempty"`
+	LatestTime   string     `json:"latest_time,omitempty"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Holds        []SlotHold `json:"holds,omitempty"`
+}
+
+// SlotHold reserves a freed slot for a waitlist entry until ExpiresAt.
+type SlotHold struct {
+	Id        int       `json:"id"`
+	EntryId   int       `json:"entry_id"`
+	DentistId int       `json:"dentist_id"`
+	ClinicId  int       `json:"clinic_id,omitempty"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Status    string    `json:"status"`
+}
@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"bufio"
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/document"
+	"checkpoint2/pkg/ical"
+	"checkpoint2/pkg/timezone"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const defaultAppointmentDuration = 30 * time.Minute
+
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "02/01/2006 15:04"}
+
+// csvFile reads a CSV file whose first line names the columns, in any
+// order. Spreadsheets exported with a semicolon separator are accepted too.
+type csvFile struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func openCSV(r io.Reader, required ...string) (*csvFile, error) {
+	buffered := bufio.NewReader(r)
+	firstLine, err := buffered.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	reader := csv.NewReader(io.MultiReader(strings.NewReader(firstLine), buffered))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+	return &csvFile{reader: reader, columns: columns}, nil
+}
+
+// next returns the following record and its line number, with a helper to
+// read its fields by column name.
+func (f *csvFile) next() (int, func(column string) string, error) {
+	record, err := f.reader.Read()
+	if err != nil {
+		return 0, nil, err
+	}
+	line, _ := f.reader.FieldPos(0)
+	field := func(column string) string {
+		i, ok := f.columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	return line, field, nil
+}
+
+// readCSV calls row for every record of a CSV file, collecting the errors
+// of malformed lines instead of stopping at the first one.
+func readCSV(file string, r io.Reader, required []string, row func(line int, field func(string) string) error) []domain.ImportRowError {
+	var rowErrors []domain.ImportRowError
+	f, err := openCSV(r, required...)
+	if err != nil {
+		return append(rowErrors, domain.ImportRowError{File: file, Message: err.Error()})
+	}
+	for {
+		line, field, err := f.next()
+		if errors.Is(err, io.EOF) {
+			return rowErrors
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rowErrors = append(rowErrors, domain.ImportRowError{File: file, Row: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return append(rowErrors, domain.ImportRowError{File: file, Message: err.Error()})
+		}
+		if err := row(line, field); err != nil {
+			rowErrors = append(rowErrors, domain.ImportRowError{File: file, Row: line, Message: err.Error()})
+		}
+	}
+}
+
+// ParsePatientsCSV reads patients from a CSV file with the columns surname,
+// name, rg and registration_date, and optionally cpf and birth_date. The
+// documents and birth date are validated with the other rows.
+func ParsePatientsCSV(r io.Reader) ([]domain.ImportPatient, []domain.ImportRowError) {
+	var patients []domain.ImportPatient
+	rowErrors := readCSV(FilePatients, r, []string{"surname", "name", "rg", "registration_date"}, func(line int, field func(string) string) error {
+		patients = append(patients, domain.ImportPatient{
+			Row: line,
+			Patient: domain.Patient{
+				Surname:          field("surname"),
+				Name:             field("name"),
+				RG:               field("rg"),
+				CPF:              field("cpf"),
+				RegistrationDate: field("registration_date"),
+				BirthDate:        field("birth_date"),
+			},
+		})
+		return nil
+	})
+	return patients, rowErrors
+}
+
+// ParseDentistsCSV reads dentists from a CSV file with the columns surname,
+// name and registration.
+func ParseDentistsCSV(r io.Reader) ([]domain.ImportDentist, []domain.ImportRowError) {
+	var dentists []domain.ImportDentist
+	rowErrors := readCSV(FileDentists, r, []string{"surname", "name", "registration"}, func(line int, field func(string) string) error {
+		dentists = append(dentists, domain.ImportDentist{
+			Row: line,
+			Dentist: domain.Dentist{
+				Surname:      field("surname"),
+				Name:         field("name"),
+				Registration: field("registration"),
+			},
+		})
+		return nil
+	})
+	return dentists, rowErrors
+}
+
+// ParseAppointmentsCSV reads appointments from a CSV file with the columns
+// patient_rg, dentist_registration, start, description and, optionally, end.
+// Appointments without end last the default 30 minutes.
+func ParseAppointmentsCSV(r io.Reader) ([]domain.ImportAppointment, []domain.ImportRowError) {
+	var appointments []domain.ImportAppointment
+	rowErrors := readCSV(FileAppointments, r, []string{"patient_rg", "dentist_registration", "start", "description"}, func(line int, field func(string) string) error {
+		start, err := parseTime(field("start"))
+		if err != nil {
+			return err
+		}
+		end := start.Add(defaultAppointmentDuration)
+		if field("end") != "" {
+			if end, err = parseTime(field("end")); err != nil {
+				return err
+			}
+		}
+		appointments = append(appointments, domain.ImportAppointment{
+			Row:                 line,
+			PatientRG:           document.RG(field("patient_rg")),
+			DentistRegistration: field("dentist_registration"),
+			Start:               start,
+			End:                 end,
+			Description:         field("description"),
+		})
+		return nil
+	})
+	return appointments, rowErrors
+}
+
+// ParseAppointmentsICS reads appointments from the VEVENTs of an iCalendar
+// file. The patient and the dentist come from the X-PATIENT-RG and
+// X-DENTIST-REGISTRATION properties, and the description from DESCRIPTION or,
+// when it is empty, SUMMARY. Rows are numbered by event, starting at 1, and
+// an event whose times can't be read fails only its own row.
+func ParseAppointmentsICS(r io.Reader) ([]domain.ImportAppointment, []domain.ImportRowError) {
+	events, err := ical.Parse(r)
+	if err != nil {
+		return nil, []domain.ImportRowError{{File: FileAppointments, Message: err.Error()}}
+	}
+
+	var appointments []domain.ImportAppointment
+	var rowErrors []domain.ImportRowError
+	for i, event := range events {
+		if event.Status == "CANCELLED" {
+			continue
+		}
+		if event.Err != nil {
+			rowErrors = append(rowErrors, domain.ImportRowError{File: FileAppointments, Row: i + 1, Message: event.Err.Error()})
+			continue
+		}
+		if event.Start.IsZero() {
+			rowErrors = append(rowErrors, domain.ImportRowError{File: FileAppointments, Row: i + 1, Message: "missing DTSTART"})
+			continue
+		}
+		appointment := domain.ImportAppointment{
+			Row:                 i + 1,
+			PatientRG:           document.RG(event.Properties["X-PATIENT-RG"]),
+			DentistRegistration: strings.TrimSpace(event.Properties["X-DENTIST-REGISTRATION"]),
+			Start:               event.Start,
+			End:                 event.End,
+			Description:         event.Description,
+		}
+		if appointment.End.IsZero() {
+			appointment.End = appointment.Start.Add(defaultAppointmentDuration)
+		}
+		if appointment.Description == "" {
+			appointment.Description = event.Summary
+		}
+		appointments = append(appointments, appointment)
+	}
+	return appointments, rowErrors
+}
+
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := timezone.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected ISO 8601 or dd/mm/yyyy hh:mm", value)
+}
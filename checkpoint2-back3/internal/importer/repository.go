@@ -0,0 +1,26 @@
+package importer
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/store"
+)
+
+type Repository interface {
+	Import(batch domain.ImportBatch) error
+}
+
+type repository struct {
+	storage store.ImportStoreInterface
+}
+
+func NewRepository(storage store.ImportStoreInterface) Repository {
+	return &repository{storage}
+}
+
+func (r *repository) Import(batch domain.ImportBatch) error {
+	err := r.storage.Import(batch)
+	if err != nil {
+		return err
+	}
+	return nil
+}
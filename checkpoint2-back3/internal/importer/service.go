@@ -0,0 +1,303 @@
+package importer
+
+import (
+	"checkpoint2/internal/appointment"
+	"checkpoint2/internal/dentist"
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/patient"
+	"checkpoint2/pkg/timezone"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	FilePatients     = "patients"
+	FileDentists     = "dentists"
+	FileAppointments = "appointments"
+
+	FormatCSV = "csv"
+	FormatICS = "ics"
+)
+
+// Files are the import sources. Any of them may be nil; appointments are
+// read as CSV or iCalendar according to AppointmentsFormat.
+type Files struct {
+	Patients           io.Reader
+	Dentists           io.Reader
+	Appointments       io.Reader
+	AppointmentsFormat string
+}
+
+type Service interface {
+	Import(files Files, dryRun bool) (domain.ImportReport, error)
+	AddListener(listener Listener)
+}
+
+// Listener is told about every batch an import saved.
+type Listener interface {
+	Imported(batch domain.ImportBatch)
+}
+
+type service struct {
+	r            Repository
+	patients     patient.Repository
+	dentists     dentist.Repository
+	appointments appointment.Service
+	listeners    []Listener
+}
+
+func NewService(r Repository, patients patient.Repository, dentists dentist.Repository, appointments appointment.Service) Service {
+	return &service{r: r, patients: patients, dentists: dentists, appointments: appointments}
+}
+
+func (s *service) AddListener(listener Listener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// Import validates every row of files against the existing data and against
+// the other rows. In dry-run mode, or when any row fails, nothing is saved
+// and the report lists every failure; otherwise all rows are saved in a
+// single transaction.
+func (s *service) Import(files Files, dryRun bool) (domain.ImportReport, error) {
+	batch, rowErrors := parse(files)
+
+	v, err := s.newValidator()
+	if err != nil {
+		return domain.ImportReport{}, err
+	}
+	batch.Patients = v.patients(batch.Patients)
+	batch.Dentists = v.dentists(batch.Dentists)
+	if batch.Appointments, err = v.appointments(batch.Appointments); err != nil {
+		return domain.ImportReport{}, err
+	}
+	rowErrors = append(rowErrors, v.errors...)
+
+	report := domain.ImportReport{
+		DryRun:       dryRun,
+		Patients:     len(batch.Patients),
+		Dentists:     len(batch.Dentists),
+		Appointments: len(batch.Appointments),
+		Errors:       rowErrors,
+	}
+	if dryRun || len(rowErrors) > 0 {
+		return report, nil
+	}
+
+	if err := s.r.Import(batch); err != nil {
+		return domain.ImportReport{}, err
+	}
+	report.Committed = true
+	for _, listener := range s.listeners {
+		listener.Imported(batch)
+	}
+	return report, nil
+}
+
+func parse(files Files) (domain.ImportBatch, []domain.ImportRowError) {
+	var batch domain.ImportBatch
+	var rowErrors, fileErrors []domain.ImportRowError
+
+	if files.Patients != nil {
+		batch.Patients, fileErrors = ParsePatientsCSV(files.Patients)
+		rowErrors = append(rowErrors, fileErrors...)
+	}
+	if files.Dentists != nil {
+		batch.Dentists, fileErrors = ParseDentistsCSV(files.Dentists)
+		rowErrors = append(rowErrors, fileErrors...)
+	}
+	if files.Appointments != nil {
+		if files.AppointmentsFormat == FormatICS {
+			batch.Appointments, fileErrors = ParseAppointmentsICS(files.Appointments)
+		} else {
+			batch.Appointments, fileErrors = ParseAppointmentsCSV(files.Appointments)
+		}
+		rowErrors = append(rowErrors, fileErrors...)
+	}
+	return batch, rowErrors
+}
+
+// validator keeps the RGs and registrations known so far, both existing and
+// imported, to match appointments and detect repeated rows.
+type validator struct {
+	bookings         appointment.Service
+	patientIds       map[string]int
+	patientCpfs      map[string]bool
+	dentistIds       map[string]int
+	importedPatients map[string]int
+	importedCpfs     map[string]int
+	importedDentists map[string]int
+	errors           []domain.ImportRowError
+}
+
+func (s *service) newValidator() (*validator, error) {
+	v := &validator{
+		bookings:         s.appointments,
+		patientIds:       map[string]int{},
+		patientCpfs:      map[string]bool{},
+		dentistIds:       map[string]int{},
+		importedPatients: map[string]int{},
+		importedCpfs:     map[string]int{},
+		importedDentists: map[string]int{},
+	}
+
+	patients, err := s.patients.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range patients {
+		v.patientIds[p.RG] = p.Id
+		if p.CPF != "" {
+			v.patientCpfs[p.CPF] = true
+		}
+	}
+
+	dentists, err := s.dentists.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dentists {
+		v.dentistIds[d.Registration] = d.Id
+	}
+	return v, nil
+}
+
+func (v *validator) fail(file string, row int, format string, args ...interface{}) {
+	v.errors = append(v.errors, domain.ImportRowError{File: file, Row: row, Message: fmt.Sprintf(format, args...)})
+}
+
+// patients validates and normalises every row the way patient.Service does
+// before checking it against the existing and the other imported patients.
+func (v *validator) patients(rows []domain.ImportPatient) []domain.ImportPatient {
+	var valid []domain.ImportPatient
+	for _, row := range rows {
+		p := row.Patient
+		if p.Surname == "" || p.Name == "" || p.RG == "" || p.RegistrationDate == "" {
+			v.fail(FilePatients, row.Row, "surname, name, rg and registration_date can't be empty")
+			continue
+		}
+		p, err := patient.Normalize(p)
+		if err != nil {
+			v.fail(FilePatients, row.Row, "%s", err.Error())
+			continue
+		}
+		switch {
+		case v.patientIds[p.RG] != 0:
+			v.fail(FilePatients, row.Row, "rg %s already exists", p.RG)
+		case v.importedPatients[p.RG] != 0:
+			v.fail(FilePatients, row.Row, "rg %s is repeated from row %d", p.RG, v.importedPatients[p.RG])
+		case p.CPF != "" && v.patientCpfs[p.CPF]:
+			v.fail(FilePatients, row.Row, "cpf %s already exists", p.CPF)
+		case p.CPF != "" && v.importedCpfs[p.CPF] != 0:
+			v.fail(FilePatients, row.Row, "cpf %s is repeated from row %d", p.CPF, v.importedCpfs[p.CPF])
+		default:
+			v.importedPatients[p.RG] = row.Row
+			if p.CPF != "" {
+				v.importedCpfs[p.CPF] = row.Row
+			}
+			row.Patient = p
+			valid = append(valid, row)
+		}
+	}
+	return valid
+}
+
+func (v *validator) dentists(rows []domain.ImportDentist) []domain.ImportDentist {
+	var valid []domain.ImportDentist
+	for _, row := range rows {
+		d := row.Dentist
+		switch {
+		case d.Surname == "" || d.Name == "" || d.Registration == "":
+			v.fail(FileDentists, row.Row, "surname, name and registration can't be empty")
+		case v.dentistIds[d.Registration] != 0:
+			v.fail(FileDentists, row.Row, "registration %s already exists", d.Registration)
+		case v.importedDentists[d.Registration] != 0:
+			v.fail(FileDentists, row.Row, "registration %s is repeated from row %d", d.Registration, v.importedDentists[d.Registration])
+		default:
+			v.importedDentists[d.Registration] = row.Row
+			valid = append(valid, row)
+		}
+	}
+	return valid
+}
+
+// appointments matches every row to its patient and dentist and checks it
+// the same way a booking is checked, plus overlaps between imported rows.
+// Patients and dentists created by the import have no bookings or working
+// hours yet, so they are only checked against the other rows.
+func (v *validator) appointments(rows []domain.ImportAppointment) ([]domain.ImportAppointment, error) {
+	var valid []domain.ImportAppointment
+	var placedRows []domain.Appointment
+	for _, row := range rows {
+		if row.PatientRG == "" || row.DentistRegistration == "" || row.Description == "" {
+			v.fail(FileAppointments, row.Row, "patient rg, dentist registration and description can't be empty")
+			continue
+		}
+		if !row.End.After(row.Start) {
+			v.fail(FileAppointments, row.Row, "end must be after start")
+			continue
+		}
+
+		idPatient, existingPatient := v.patientIds[row.PatientRG]
+		if !existingPatient && v.importedPatients[row.PatientRG] == 0 {
+			v.fail(FileAppointments, row.Row, "patient with rg %s not found", row.PatientRG)
+			continue
+		}
+		idDentist, existingDentist := v.dentistIds[row.DentistRegistration]
+		if !existingDentist && v.importedDentists[row.DentistRegistration] == 0 {
+			v.fail(FileAppointments, row.Row, "dentist with registration %s not found", row.DentistRegistration)
+			continue
+		}
+
+		loc, err := v.bookings.Location(idDentist, 0)
+		if err != nil {
+			return nil, err
+		}
+		row.Start, row.End = timezone.Localize(row.Start, loc), timezone.Localize(row.End, loc)
+
+		placed, err := v.bookings.CheckSlot(domain.Appointment{
+			Patient: domain.Patient{Id: idPatient},
+			Dentist: domain.Dentist{Id: idDentist},
+			Start:   row.Start,
+			End:     row.End,
+		}, placedRows)
+		var conflict *appointment.ConflictError
+		var unavailable *appointment.UnavailableError
+		var restricted *appointment.RestrictedError
+		var guardian *appointment.GuardianRequiredError
+		if errors.As(err, &conflict) || errors.As(err, &unavailable) || errors.As(err, &restricted) || errors.As(err, &guardian) {
+			v.fail(FileAppointments, row.Row, "%s", err.Error())
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		row.ClinicId, row.RoomId = placed.ClinicId, placed.RoomId
+
+		if overlapping, ok := overlaps(valid, row); ok {
+			v.fail(FileAppointments, row.Row, "overlaps the appointment on row %d", overlapping.Row)
+			continue
+		}
+		valid = append(valid, row)
+		placedRows = append(placedRows, placed)
+	}
+	return valid, nil
+}
+
+// overlaps returns the accepted row that shares the patient, the dentist or
+// the room with row at an overlapping time.
+func overlaps(accepted []domain.ImportAppointment, row domain.ImportAppointment) (domain.ImportAppointment, bool) {
+	for _, other := range accepted {
+		samePerson := other.PatientRG == row.PatientRG || other.DentistRegistration == row.DentistRegistration
+		sameRoom := row.RoomId != 0 && other.RoomId == row.RoomId
+		if (samePerson || sameRoom) && intersects(other.Start, other.End, row.Start, row.End) {
+			return other, true
+		}
+	}
+	return domain.ImportAppointment{}, false
+}
+
+func intersects(start time.Time, end time.Time, otherStart time.Time, otherEnd time.Time) bool {
+	return start.Before(otherEnd) && otherStart.Before(end)
+}
@@ -0,0 +1,118 @@
+package odontogram
+
+import (
+	"checkpoint2/internal/domain"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// chart is the state of a patient's teeth, built by replaying their changes
+// in the order they were made.
+type chart map[int]*domain.Tooth
+
+func newChart(changes []domain.ToothChange) chart {
+	c := chart{}
+	for _, change := range changes {
+		c.apply(change)
+	}
+	return c
+}
+
+// apply records change on its tooth. A condition of the whole tooth, healthy
+// included, replaces whatever its surfaces had, while a healthy surface is
+// simply cleared.
+func (c chart) apply(change domain.ToothChange) {
+	tooth, ok := c[change.Tooth]
+	if !ok {
+		tooth = &domain.Tooth{Number: change.Tooth, Condition: domain.ToothHealthy}
+		c[change.Tooth] = tooth
+	}
+
+	switch {
+	case change.Surface == "":
+		tooth.Condition = change.Condition
+		tooth.Surfaces = nil
+	case change.Condition == domain.ToothHealthy:
+		delete(tooth.Surfaces, change.Surface)
+	default:
+		if tooth.Surfaces == nil {
+			tooth.Surfaces = map[string]string{}
+		}
+		tooth.Surfaces[change.Surface] = change.Condition
+	}
+	tooth.AppointmentId, tooth.ChangedAt = change.AppointmentId, change.MadeAt
+}
+
+// check refuses a change that makes no sense on the tooth as charted: only an
+// implant can take the place of a missing tooth, unless it is found healthy
+// after all.
+func (c chart) check(change domain.ToothChange) error {
+	tooth, ok := c[change.Tooth]
+	if !ok || tooth.Condition != domain.ToothMissing {
+		return nil
+	}
+	if change.Surface != "" || change.Condition != domain.ToothImplant && change.Condition != domain.ToothHealthy {
+		return &domain.ValidationError{Reason: fmt.Sprintf("tooth %d is missing", change.Tooth)}
+	}
+	return nil
+}
+
+// teeth lists the teeth with something recorded on them, by number.
+func (c chart) teeth() []domain.Tooth {
+	teeth := []domain.Tooth{}
+	for _, tooth := range c {
+		if tooth.Condition != domain.ToothHealthy || len(tooth.Surfaces) > 0 {
+			teeth = append(teeth, *tooth)
+		}
+	}
+	sort.Slice(teeth, func(a, b int) bool {
+		return teeth[a].Number < teeth[b].Number
+	})
+	return teeth
+}
+
+// normalizeChange validates change and puts its condition and surface in the
+// form they are kept in. Caries and restorations are charted per surface, and
+// missing teeth, implants and crowns on the whole tooth. Front teeth, the
+// first three of each quadrant, have an incisal edge instead of an occlusal
+// surface.
+func normalizeChange(change domain.ToothChange) (domain.ToothChange, error) {
+	quadrant, position := change.Tooth/10, change.Tooth%10
+	switch {
+	case quadrant >= 1 && quadrant <= 4 && position >= 1 && position <= 8:
+	case quadrant >= 5 && quadrant <= 8 && position >= 1 && position <= 5:
+	default:
+		return domain.ToothChange{}, &domain.ValidationError{Reason: fmt.Sprintf("invalid tooth %d, expected FDI notation such as 11 or 85", change.Tooth)}
+	}
+
+	change.Condition = strings.ToLower(strings.TrimSpace(change.Condition))
+	change.Surface = strings.ToLower(strings.TrimSpace(change.Surface))
+	change.Notes = strings.TrimSpace(change.Notes)
+
+	switch change.Condition {
+	case domain.ToothCaries, domain.ToothRestoration:
+		if change.Surface == "" {
+			return domain.ToothChange{}, &domain.ValidationError{Reason: fmt.Sprintf("%s on tooth %d needs a surface", change.Condition, change.Tooth)}
+		}
+	case domain.ToothMissing, domain.ToothImplant, domain.ToothCrown:
+		if change.Surface != "" {
+			return domain.ToothChange{}, &domain.ValidationError{Reason: fmt.Sprintf("%s applies to the whole tooth, not a surface", change.Condition)}
+		}
+	case domain.ToothHealthy:
+	default:
+		return domain.ToothChange{}, &domain.ValidationError{Reason: "condition must be healthy, caries, restoration, missing, implant or crown"}
+	}
+
+	front := position <= 3
+	switch change.Surface {
+	case "", domain.SurfaceMesial, domain.SurfaceDistal, domain.SurfaceBuccal, domain.SurfaceLingual:
+	case domain.SurfaceOcclusal, domain.SurfaceIncisal:
+		if front != (change.Surface == domain.SurfaceIncisal) {
+			return domain.ToothChange{}, &domain.ValidationError{Reason: fmt.Sprintf("tooth %d has no %s surface", change.Tooth, change.Surface)}
+		}
+	default:
+		return domain.ToothChange{}, &domain.ValidationError{Reason: "surface must be mesial, distal, buccal, lingual, occlusal or incisal"}
+	}
+	return change, nil
+}
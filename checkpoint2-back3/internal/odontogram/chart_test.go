@@ -0,0 +1,67 @@
+package odontogram
+
+import (
+	"checkpoint2/internal/domain"
+	"errors"
+	"testing"
+)
+
+func TestNormalizeChange(t *testing.T) {
+	tests := []struct {
+		tooth     int
+		surface   string
+		condition string
+		want      domain.ToothChange
+		wantErr   bool
+	}{
+		{18, "", "Missing", domain.ToothChange{Tooth: 18, Condition: domain.ToothMissing}, false},
+		{18, " Occlusal ", "caries", domain.ToothChange{Tooth: 18, Surface: domain.SurfaceOcclusal, Condition: domain.ToothCaries}, false},
+		{18, "incisal", "caries", domain.ToothChange{}, true},
+		{19, "", "missing", domain.ToothChange{}, true},
+		{10, "", "missing", domain.ToothChange{}, true},
+		{11, "incisal", "restoration", domain.ToothChange{Tooth: 11, Surface: domain.SurfaceIncisal, Condition: domain.ToothRestoration}, false},
+		{11, "occlusal", "restoration", domain.ToothChange{}, true},
+		{48, "mesial", "caries", domain.ToothChange{Tooth: 48, Surface: domain.SurfaceMesial, Condition: domain.ToothCaries}, false},
+		{55, "occlusal", "caries", domain.ToothChange{Tooth: 55, Surface: domain.SurfaceOcclusal, Condition: domain.ToothCaries}, false},
+		{56, "", "missing", domain.ToothChange{}, true},
+		{85, "", "crown", domain.ToothChange{Tooth: 85, Condition: domain.ToothCrown}, false},
+		{91, "", "missing", domain.ToothChange{}, true},
+		{18, "", "caries", domain.ToothChange{}, true},
+		{18, "mesial", "crown", domain.ToothChange{}, true},
+		{18, "palatal", "caries", domain.ToothChange{}, true},
+		{18, "", "fractured", domain.ToothChange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeChange(domain.ToothChange{Tooth: tt.tooth, Surface: tt.surface, Condition: tt.condition})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeChange(%d, %q, %q) error = %v, want error %v", tt.tooth, tt.surface, tt.condition, err, tt.wantErr)
+			continue
+		}
+		var validation *domain.ValidationError
+		if err != nil && !errors.As(err, &validation) {
+			t.Errorf("normalizeChange(%d, %q, %q) error = %T, want *domain.ValidationError", tt.tooth, tt.surface, tt.condition, err)
+		}
+		if got != tt.want {
+			t.Errorf("normalizeChange(%d, %q, %q) = %+v, want %+v", tt.tooth, tt.surface, tt.condition, got, tt.want)
+		}
+	}
+}
+
+func TestChartCheck(t *testing.T) {
+	c := newChart([]domain.ToothChange{{Tooth: 36, Condition: domain.ToothMissing}})
+	tests := []struct {
+		change  domain.ToothChange
+		wantErr bool
+	}{
+		{domain.ToothChange{Tooth: 36, Condition: domain.ToothImplant}, false},
+		{domain.ToothChange{Tooth: 36, Condition: domain.ToothHealthy}, false},
+		{domain.ToothChange{Tooth: 36, Condition: domain.ToothCrown}, true},
+		{domain.ToothChange{Tooth: 36, Surface: domain.SurfaceOcclusal, Condition: domain.ToothCaries}, true},
+		{domain.ToothChange{Tooth: 37, Condition: domain.ToothCrown}, false},
+	}
+	for _, tt := range tests {
+		if err := c.check(tt.change); (err != nil) != tt.wantErr {
+			t.Errorf("check(%+v) error = %v, want error %v", tt.change, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package odontogram
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/store"
+	"time"
+)
+
+type Repository interface {
+	ReadChanges(idPatient int, tooth int, until time.Time) ([]domain.ToothChange, error)
+	CreateChanges(changes []domain.ToothChange) ([]domain.ToothChange, error)
+}
+
+type repository struct {
+	storage store.OdontogramStoreInterface
+}
+
+func NewRepository(storage store.OdontogramStoreInterface) Repository {
+	return &repository{storage}
+}
+
+func (r *repository) ReadChanges(idPatient int, tooth int, until time.Time) ([]domain.ToothChange, error) {
+	changes, err := r.storage.ReadChanges(idPatient, tooth, until)
+	if err != nil {
+		return []domain.ToothChange{}, err
+	}
+	return changes, nil
+}
+
+func (r *repository) CreateChanges(changes []domain.ToothChange) ([]domain.ToothChange, error) {
+	createdChanges, err := r.storage.CreateChanges(changes)
+	if err != nil {
+		return []domain.ToothChange{}, err
+	}
+	return createdChanges, nil
+}
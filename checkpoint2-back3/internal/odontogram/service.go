@@ -0,0 +1,114 @@
+package odontogram
+
+import (
+	"checkpoint2/internal/appointment"
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/patient"
+	"fmt"
+	"time"
+)
+
+type Service interface {
+	Read(idPatient int, at time.Time) (domain.Odontogram, error)
+	ReadHistory(idPatient int, tooth int) ([]domain.ToothChange, error)
+	Apply(idPatient int, idAppointment int, changes []domain.ToothChange) (domain.Odontogram, error)
+	Location(idPatient int) (*time.Location, error)
+}
+
+type service struct {
+	r            Repository
+	patients     patient.Repository
+	appointments appointment.Service
+}
+
+func NewService(r Repository, patients patient.Repository, appointments appointment.Service) Service {
+	return &service{r: r, patients: patients, appointments: appointments}
+}
+
+// Read charts the patient's teeth as of at, counting the changes made in the
+// appointments started by then. A zero at gives the current chart.
+func (s *service) Read(idPatient int, at time.Time) (domain.Odontogram, error) {
+	if _, err := s.patients.ReadById(idPatient); err != nil {
+		return domain.Odontogram{}, err
+	}
+	changes, err := s.r.ReadChanges(idPatient, 0, at)
+	if err != nil {
+		return domain.Odontogram{}, err
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return domain.Odontogram{PatientId: idPatient, At: at, Teeth: newChart(changes).teeth()}, nil
+}
+
+// ReadHistory lists every change made to the patient's teeth, only those to
+// tooth when it is set.
+func (s *service) ReadHistory(idPatient int, tooth int) ([]domain.ToothChange, error) {
+	if _, err := s.patients.ReadById(idPatient); err != nil {
+		return []domain.ToothChange{}, err
+	}
+	changes, err := s.r.ReadChanges(idPatient, tooth, time.Time{})
+	if err != nil {
+		return []domain.ToothChange{}, err
+	}
+	return changes, nil
+}
+
+// Apply records changes made to the patient's teeth during an appointment of
+// theirs that took place, and returns the current chart. The changes are
+// checked in order against the chart as of the appointment, so a tooth
+// extracted earlier in it can only receive an implant.
+func (s *service) Apply(idPatient int, idAppointment int, changes []domain.ToothChange) (domain.Odontogram, error) {
+	if len(changes) == 0 {
+		return domain.Odontogram{}, &domain.ValidationError{Reason: "changes can't be empty"}
+	}
+	a, err := s.appointments.ReadById(idAppointment)
+	if err != nil {
+		return domain.Odontogram{}, err
+	}
+	if a.Patient.Id != idPatient {
+		return domain.Odontogram{}, &domain.ValidationError{Reason: fmt.Sprintf("appointment %d is not patient %d's", idAppointment, idPatient)}
+	}
+	switch a.Status {
+	case domain.StatusCheckedIn, domain.StatusInProgress, domain.StatusCompleted:
+	default:
+		return domain.Odontogram{}, &domain.ValidationError{Reason: fmt.Sprintf("changes can't be recorded in a %s appointment", a.Status)}
+	}
+
+	previous, err := s.r.ReadChanges(idPatient, 0, a.Start)
+	if err != nil {
+		return domain.Odontogram{}, err
+	}
+	c := newChart(previous)
+	recorded := make([]domain.ToothChange, 0, len(changes))
+	for _, change := range changes {
+		change, err := normalizeChange(change)
+		if err != nil {
+			return domain.Odontogram{}, err
+		}
+		if err := c.check(change); err != nil {
+			return domain.Odontogram{}, err
+		}
+		change.PatientId, change.AppointmentId, change.MadeAt = idPatient, idAppointment, a.Start
+		c.apply(change)
+		recorded = append(recorded, change)
+	}
+
+	if _, err := s.r.CreateChanges(recorded); err != nil {
+		return domain.Odontogram{}, err
+	}
+	return s.Read(idPatient, time.Time{})
+}
+
+// Location is the zone the patient is seen in: that of the clinic or dentist
+// of their latest appointment, or the default zone when they have none.
+func (s *service) Location(idPatient int) (*time.Location, error) {
+	latest, _, err := s.appointments.Search(domain.AppointmentFilter{PatientId: idPatient, Sort: "-start", Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) == 0 {
+		return s.appointments.Location(0, 0)
+	}
+	return s.appointments.Location(latest[0].Dentist.Id, latest[0].ClinicId)
+}
@@ -0,0 +1,205 @@
+package patient
+
+import (
+	"checkpoint2/internal/domain"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var cepPattern = regexp.MustCompile(`^(\d{5})-?(\d{3})$`)
+
+var states = map[string]bool{
+	"AC": true, "AL": true, "AP": true, "AM": true, "BA": true, "CE": true, "DF": true,
+	"ES": true, "GO": true, "MA": true, "MT": true, "MS": true, "MG": true, "PA": true,
+	"PB": true, "PR": true, "PE": true, "PI": true, "RJ": true, "RN": true, "RS": true,
+	"RO": true, "RR": true, "SC": true, "SP": true, "SE": true, "TO": true,
+}
+
+func (s *service) ReadContacts(id int) (domain.Contacts, error) {
+	if _, err := s.r.ReadById(id); err != nil {
+		return domain.Contacts{}, err
+	}
+	contacts, err := s.r.ReadContacts(id)
+	if err != nil {
+		return domain.Contacts{}, err
+	}
+	return contacts, nil
+}
+
+// UpdateContacts replaces every contact of the patient. Anything left out of
+// contacts is removed.
+func (s *service) UpdateContacts(id int, contacts domain.Contacts) (domain.Contacts, error) {
+	contacts, err := normalizeContacts(contacts)
+	if err != nil {
+		return domain.Contacts{}, err
+	}
+	updatedContacts, err := s.r.UpdateContacts(id, contacts)
+	if err != nil {
+		return domain.Contacts{}, err
+	}
+	return updatedContacts, nil
+}
+
+func (s *service) DeleteContacts(id int) error {
+	_, err := s.r.UpdateContacts(id, domain.Contacts{})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *service) CreatePhone(id int, phone domain.Phone) (domain.Phone, error) {
+	phone, err := normalizePhone(phone)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+	createdPhone, err := s.r.CreatePhone(id, phone)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+	return createdPhone, nil
+}
+
+func (s *service) UpdatePhone(id int, phone domain.Phone) (domain.Phone, error) {
+	phone, err := normalizePhone(phone)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+	updatedPhone, err := s.r.UpdatePhone(id, phone)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+	return updatedPhone, nil
+}
+
+func (s *service) DeletePhone(id int, idPhone int) error {
+	err := s.r.DeletePhone(id, idPhone)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalizeContacts validates contacts and puts the email, phones and address
+// in the form they are kept in.
+func normalizeContacts(contacts domain.Contacts) (domain.Contacts, error) {
+	var err error
+	if contacts.Email != "" {
+		if contacts.Email, err = normalizeEmail(contacts.Email); err != nil {
+			return domain.Contacts{}, err
+		}
+	}
+
+	phones := make([]domain.Phone, 0, len(contacts.Phones))
+	for _, phone := range contacts.Phones {
+		phone, err := normalizePhone(phone)
+		if err != nil {
+			return domain.Contacts{}, err
+		}
+		phones = append(phones, phone)
+	}
+	contacts.Phones = phones
+
+	if contacts.Address != nil {
+		address, err := normalizeAddress(*contacts.Address)
+		if err != nil {
+			return domain.Contacts{}, err
+		}
+		contacts.Address = &address
+	}
+
+	if emergency := contacts.EmergencyContact; emergency != nil {
+		if strings.TrimSpace(emergency.Name) == "" {
+			return domain.Contacts{}, errors.New("emergency contact name can't be empty")
+		}
+		number, err := normalizePhoneNumber(emergency.Phone)
+		if err != nil {
+			return domain.Contacts{}, fmt.Errorf("emergency contact %w", err)
+		}
+		contacts.EmergencyContact = &domain.EmergencyContact{
+			Name:         strings.TrimSpace(emergency.Name),
+			Relationship: strings.TrimSpace(emergency.Relationship),
+			Phone:        number,
+		}
+	}
+	return contacts, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		return "", errors.New("invalid email")
+	}
+	return email, nil
+}
+
+func normalizePhone(phone domain.Phone) (domain.Phone, error) {
+	switch phone.Type {
+	case domain.PhoneMobile, domain.PhoneHome, domain.PhoneWork:
+	default:
+		return domain.Phone{}, errors.New("phone type must be mobile, home or work")
+	}
+
+	number, err := normalizePhoneNumber(phone.Number)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+	if phone.Type == domain.PhoneMobile && len(number) != 11 {
+		return domain.Phone{}, errors.New("mobile phone must have 11 digits with the area code")
+	}
+	phone.Number = number
+	return phone, nil
+}
+
+// normalizePhoneNumber keeps the digits of a Brazilian phone number, dropping
+// the country code. What is left must be a two-digit area code followed by
+// eight digits, or nine for mobiles, which start with 9.
+func normalizePhoneNumber(number string) (string, error) {
+	var digits strings.Builder
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune(" +-().", r):
+		default:
+			return "", errors.New("invalid phone number")
+		}
+	}
+
+	value := digits.String()
+	if (len(value) == 12 || len(value) == 13) && strings.HasPrefix(value, "55") {
+		value = value[2:]
+	}
+	if len(value) != 10 && len(value) != 11 || value[0] == '0' || value[1] == '0' || len(value) == 11 && value[2] != '9' {
+		return "", errors.New("invalid phone number, expected the area code and 8 or 9 digits")
+	}
+	return value, nil
+}
+
+func normalizeAddress(address domain.Address) (domain.Address, error) {
+	match := cepPattern.FindStringSubmatch(strings.TrimSpace(address.CEP))
+	if match == nil {
+		return domain.Address{}, errors.New("invalid cep, expected 00000-000")
+	}
+
+	normalized := domain.Address{
+		Street:     strings.TrimSpace(address.Street),
+		Number:     strings.TrimSpace(address.Number),
+		Complement: strings.TrimSpace(address.Complement),
+		District:   strings.TrimSpace(address.District),
+		City:       strings.TrimSpace(address.City),
+		State:      strings.ToUpper(strings.TrimSpace(address.State)),
+		CEP:        match[1] + "-" + match[2],
+	}
+	if normalized.Street == "" || normalized.Number == "" || normalized.District == "" || normalized.City == "" {
+		return domain.Address{}, errors.New("street, number, district and city can't be empty")
+	}
+	if !states[normalized.State] {
+		return domain.Address{}, errors.New("invalid state")
+	}
+	return normalized, nil
+}
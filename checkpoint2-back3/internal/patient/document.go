@@ -0,0 +1,51 @@
+package patient
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/document"
+)
+
+// Normalize validates the patient's birth date, RG and CPF and puts them in
+// the form they are kept in, as every write of a patient does. Patients
+// saved in bulk by an import go through it too.
+func Normalize(patient domain.Patient) (domain.Patient, error) {
+	birthDate, err := normalizeBirthDate(patient.BirthDate)
+	if err != nil {
+		return domain.Patient{}, err
+	}
+	patient.BirthDate = birthDate
+	return normalizeDocuments(patient)
+}
+
+// normalizeDocuments puts the patient's RG and CPF in the form they are kept
+// and looked up in. An empty RG is left for Patch to keep the current one.
+func normalizeDocuments(patient domain.Patient) (domain.Patient, error) {
+	if patient.RG != "" {
+		patient.RG = document.RG(patient.RG)
+		if patient.RG == "" {
+			return domain.Patient{}, &domain.ValidationError{Reason: "invalid rg"}
+		}
+	}
+	if patient.CPF != "" {
+		cpf, err := document.CPF(patient.CPF)
+		if err != nil {
+			return domain.Patient{}, &domain.ValidationError{Reason: err.Error()}
+		}
+		patient.CPF = cpf
+	}
+	return patient, nil
+}
+
+// ReadByCpf looks a patient up by CPF, which may come with or without its
+// punctuation.
+func (s *service) ReadByCpf(cpf string) (domain.Patient, error) {
+	cpf, err := document.CPF(cpf)
+	if err != nil {
+		return domain.Patient{}, &domain.ValidationError{Reason: err.Error()}
+	}
+	patient, err := s.r.ReadByCpf(cpf)
+	if err != nil {
+		return domain.Patient{}, err
+	}
+	return patient, nil
+}
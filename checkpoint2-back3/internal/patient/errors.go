@@ -0,0 +1,14 @@
+package patient
+
+import "fmt"
+
+// GuardianOfError is returned when deleting a patient who is still the
+// guardian of other patients.
+type GuardianOfError struct {
+	PatientId int
+	WardIds   []int
+}
+
+func (e *GuardianOfError) Error() string {
+	return fmt.Sprintf("patient %d is the guardian of patients %v", e.PatientId, e.WardIds)
+}
@@ -0,0 +1,113 @@
+package patient
+
+import (
+	"checkpoint2/internal/domain"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// birthDateLayouts are the layouts a birth date is accepted in. It is kept as
+// the first one.
+var birthDateLayouts = []string{domain.BirthDateLayout, "02/01/2006"}
+
+// normalizeBirthDate checks that birthDate is a plausible day of birth and
+// puts it in domain.BirthDateLayout. An empty birth date is left unknown.
+func normalizeBirthDate(birthDate string) (string, error) {
+	birthDate = strings.TrimSpace(birthDate)
+	if birthDate == "" {
+		return "", nil
+	}
+	for _, layout := range birthDateLayouts {
+		birth, err := time.Parse(layout, birthDate)
+		if err != nil {
+			continue
+		}
+		if birth.Year() < 1900 || birth.After(time.Now()) {
+			return "", &domain.ValidationError{Reason: "birth date must be between 1900 and today"}
+		}
+		return birth.Format(domain.BirthDateLayout), nil
+	}
+	return "", &domain.ValidationError{Reason: "invalid birth date, expected yyyy-mm-dd or dd/mm/yyyy"}
+}
+
+func (s *service) ReadGuardians(id int) ([]domain.Guardian, error) {
+	if _, err := s.r.ReadById(id); err != nil {
+		return []domain.Guardian{}, err
+	}
+	guardians, err := s.r.ReadGuardians(id)
+	if err != nil {
+		return []domain.Guardian{}, err
+	}
+	return guardians, nil
+}
+
+// CreateGuardian makes someone responsible for the patient. A guardian given
+// by PatientId must be another patient of age; otherwise the guardian needs a
+// name and a phone, and may have an email.
+func (s *service) CreateGuardian(id int, guardian domain.Guardian) (domain.Guardian, error) {
+	guardian, err := s.normalizeGuardian(id, guardian)
+	if err != nil {
+		return domain.Guardian{}, err
+	}
+	createdGuardian, err := s.r.CreateGuardian(id, guardian)
+	if err != nil {
+		return domain.Guardian{}, err
+	}
+	return createdGuardian, nil
+}
+
+func (s *service) DeleteGuardian(id int, idGuardian int) error {
+	err := s.r.DeleteGuardian(id, idGuardian)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *service) normalizeGuardian(id int, guardian domain.Guardian) (domain.Guardian, error) {
+	relationship := strings.TrimSpace(guardian.Relationship)
+	if relationship == "" {
+		return domain.Guardian{}, errors.New("guardian relationship can't be empty")
+	}
+
+	if guardian.PatientId != 0 {
+		if guardian.PatientId == id {
+			return domain.Guardian{}, errors.New("a patient can't be their own guardian")
+		}
+		adult, err := s.r.ReadById(guardian.PatientId)
+		if err != nil {
+			return domain.Guardian{}, fmt.Errorf("guardian %w", err)
+		}
+		if adult.MinorOn(time.Now()) {
+			return domain.Guardian{}, errors.New("guardian must be of age")
+		}
+		return domain.Guardian{
+			PatientId:    adult.Id,
+			Name:         adult.Name + " " + adult.Surname,
+			Relationship: relationship,
+		}, nil
+	}
+
+	name := strings.TrimSpace(guardian.Name)
+	if name == "" {
+		return domain.Guardian{}, errors.New("guardian name can't be empty")
+	}
+	phone, err := normalizePhoneNumber(guardian.Phone)
+	if err != nil {
+		return domain.Guardian{}, fmt.Errorf("guardian %w", err)
+	}
+	email := ""
+	if guardian.Email != "" {
+		if email, err = normalizeEmail(guardian.Email); err != nil {
+			return domain.Guardian{}, fmt.Errorf("guardian %w", err)
+		}
+	}
+	return domain.Guardian{
+		Name:         name,
+		Relationship: relationship,
+		Phone:        phone,
+		Email:        email,
+	}, nil
+}
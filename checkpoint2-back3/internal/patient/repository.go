@@ -8,11 +8,25 @@ import (
 type Repository interface {
 	ReadById(id int) (domain.Patient, error)
 	ReadAll() ([]domain.Patient, error)
+	Search(filter domain.PatientFilter) ([]domain.Patient, int, error)
 	ReadByRg(rg string) (domain.Patient, error)
+	ReadByCpf(cpf string) (domain.Patient, error)
 	Create(patient domain.Patient) (domain.Patient, error)
 	Update(id int, patient domain.Patient) (domain.Patient, error)
 	Patch(id int, patient domain.Patient) (domain.Patient, error)
 	Delete(id int) error
+	ReadCalendarToken(id int) (string, error)
+	UpdateCalendarToken(id int, token string) error
+	UpdateRestricted(id int, restricted bool) error
+	ReadContacts(id int) (domain.Contacts, error)
+	UpdateContacts(id int, contacts domain.Contacts) (domain.Contacts, error)
+	CreatePhone(id int, phone domain.Phone) (domain.Phone, error)
+	UpdatePhone(id int, phone domain.Phone) (domain.Phone, error)
+	DeletePhone(id int, idPhone int) error
+	ReadGuardians(id int) ([]domain.Guardian, error)
+	ReadWards(id int) ([]int, error)
+	CreateGuardian(id int, guardian domain.Guardian) (domain.Guardian, error)
+	DeleteGuardian(id int, idGuardian int) error
 }
 
 type repository struct {
@@ -39,6 +53,14 @@ func (r *repository) ReadAll() ([]domain.Patient, error) {
 	return patients, nil
 }
 
+func (r *repository) Search(filter domain.PatientFilter) ([]domain.Patient, int, error) {
+	patients, total, err := r.storage.Search(filter)
+	if err != nil {
+		return []domain.Patient{}, 0, err
+	}
+	return patients, total, nil
+}
+
 func (r *repository) ReadByRg(rg string) (domain.Patient, error) {
 	patient, err := r.storage.ReadByRg(rg)
 	if err != nil {
@@ -47,6 +69,14 @@ func (r *repository) ReadByRg(rg string) (domain.Patient, error) {
 	return patient, nil
 }
 
+func (r *repository) ReadByCpf(cpf string) (domain.Patient, error) {
+	patient, err := r.storage.ReadByCpf(cpf)
+	if err != nil {
+		return domain.Patient{}, err
+	}
+	return patient, nil
+}
+
 func (r *repository) Create(p domain.Patient) (domain.Patient, error) {
 	patient, err := r.storage.Create(p)
 	if err != nil {
@@ -77,4 +107,100 @@ func (r *repository) Delete(id int) error {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *repository) ReadCalendarToken(id int) (string, error) {
+	token, err := r.storage.ReadCalendarToken(id)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *repository) UpdateCalendarToken(id int, token string) error {
+	err := r.storage.UpdateCalendarToken(id, token)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) UpdateRestricted(id int, restricted bool) error {
+	err := r.storage.UpdateRestricted(id, restricted)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ReadContacts(id int) (domain.Contacts, error) {
+	contacts, err := r.storage.ReadContacts(id)
+	if err != nil {
+		return domain.Contacts{}, err
+	}
+	return contacts, nil
+}
+
+func (r *repository) UpdateContacts(id int, contacts domain.Contacts) (domain.Contacts, error) {
+	updatedContacts, err := r.storage.UpdateContacts(id, contacts)
+	if err != nil {
+		return domain.Contacts{}, err
+	}
+	return updatedContacts, nil
+}
+
+func (r *repository) CreatePhone(id int, phone domain.Phone) (domain.Phone, error) {
+	createdPhone, err := r.storage.CreatePhone(id, phone)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+	return createdPhone, nil
+}
+
+func (r *repository) UpdatePhone(id int, phone domain.Phone) (domain.Phone, error) {
+	updatedPhone, err := r.storage.UpdatePhone(id, phone)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+	return updatedPhone, nil
+}
+
+func (r *repository) DeletePhone(id int, idPhone int) error {
+	err := r.storage.DeletePhone(id, idPhone)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ReadGuardians(id int) ([]domain.Guardian, error) {
+	guardians, err := r.storage.ReadGuardians(id)
+	if err != nil {
+		return []domain.Guardian{}, err
+	}
+	return guardians, nil
+}
+
+func (r *repository) ReadWards(id int) ([]int, error) {
+	wards, err := r.storage.ReadWards(id)
+	if err != nil {
+		return []int{}, err
+	}
+	return wards, nil
+}
+
+func (r *repository) CreateGuardian(id int, guardian domain.Guardian) (domain.Guardian, error) {
+	createdGuardian, err := r.storage.CreateGuardian(id, guardian)
+	if err != nil {
+		return domain.Guardian{}, err
+	}
+	return createdGuardian, nil
+}
+
+func (r *repository) DeleteGuardian(id int, idGuardian int) error {
+	err := r.storage.DeleteGuardian(id, idGuardian)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -2,25 +2,47 @@ package patient
 
 import (
 	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/document"
+	"checkpoint2/pkg/search"
+	"checkpoint2/pkg/token"
 	"errors"
+	"sync"
 )
 
 type Service interface {
 	ReadById(id int) (domain.Patient, error)
 	ReadAll() ([]domain.Patient, error)
+	Search(filter domain.PatientFilter) ([]domain.Patient, int, error)
+	SearchByName(query string, limit int) ([]domain.Patient, error)
+	Imported(batch domain.ImportBatch)
 	ReadByRg(rg string) (domain.Patient, error)
+	ReadByCpf(cpf string) (domain.Patient, error)
 	Create(patient domain.Patient) (domain.Patient, error)
 	Update(id int, patient domain.Patient) (domain.Patient, error)
 	Patch(id int, patient domain.Patient) (domain.Patient, error)
 	Delete(id int) error
+	IssueCalendarToken(id int) (string, error)
+	CheckCalendarToken(id int, token string) error
+	LiftRestriction(id int) (domain.Patient, error)
+	ReadContacts(id int) (domain.Contacts, error)
+	UpdateContacts(id int, contacts domain.Contacts) (domain.Contacts, error)
+	DeleteContacts(id int) error
+	CreatePhone(id int, phone domain.Phone) (domain.Phone, error)
+	UpdatePhone(id int, phone domain.Phone) (domain.Phone, error)
+	DeletePhone(id int, idPhone int) error
+	ReadGuardians(id int) ([]domain.Guardian, error)
+	CreateGuardian(id int, guardian domain.Guardian) (domain.Guardian, error)
+	DeleteGuardian(id int, idGuardian int) error
 }
 
 type service struct {
-	r Repository
+	r       Repository
+	indexMu sync.Mutex
+	index   *search.Index
 }
 
 func NewService(r Repository) Service {
-	return &service{r}
+	return &service{r: r}
 }
 
 func (s *service) ReadById(id int) (domain.Patient, error) {
@@ -32,7 +54,7 @@ func (s *service) ReadById(id int) (domain.Patient, error) {
 }
 
 func (s *service) ReadByRg(rg string) (domain.Patient, error) {
-	patient, err := s.r.ReadByRg(rg)
+	patient, err := s.r.ReadByRg(document.RG(rg))
 	if err != nil {
 		return domain.Patient{}, err
 	}
@@ -47,67 +69,177 @@ func (s *service) ReadAll() ([]domain.Patient, error) {
 	return patients, nil
 }
 
-func (s *service) Create(patient domain.Patient) (domain.Patient, error) {
-	patients, err := s.ReadAll()
+func (s *service) Search(filter domain.PatientFilter) ([]domain.Patient, int, error) {
+	if filter.RG != "" {
+		filter.RG = document.RG(filter.RG)
+	}
+	patients, total, err := s.r.Search(filter)
 	if err != nil {
-		return domain.Patient{}, err
+		return []domain.Patient{}, 0, err
 	}
+	return patients, total, nil
+}
 
-	for i := range patients {
-		if patients[i].RG == patient.RG {
-			return domain.Patient{}, errors.New("rg already exists")
-		}
+func (s *service) Create(patient domain.Patient) (domain.Patient, error) {
+	patient, err := Normalize(patient)
+	if err != nil {
+		return domain.Patient{}, err
 	}
 
 	createdPatient, err := s.r.Create(patient)
 	if err != nil {
 		return domain.Patient{}, err
 	}
+	s.indexName(createdPatient.Id, createdPatient)
 	return createdPatient, nil
 }
 
 func (s *service) Update(id int, patient domain.Patient) (domain.Patient, error) {
-	patients, err := s.ReadAll()
+	patient, err := Normalize(patient)
 	if err != nil {
 		return domain.Patient{}, err
 	}
 
-	for i := range patients {
-		if patients[i].RG == patient.RG {
-			return domain.Patient{}, errors.New("rg already exists")
-		}
-	}
-
 	updatedPatient, err := s.r.Update(id, patient)
 	if err != nil {
 		return domain.Patient{}, err
 	}
+	s.indexName(id, updatedPatient)
 	return updatedPatient, nil
 }
 
 func (s *service) Patch(id int, patient domain.Patient) (domain.Patient, error) {
-	patients, err := s.ReadAll()
+	patient, err := Normalize(patient)
 	if err != nil {
 		return domain.Patient{}, err
 	}
 
-	for i := range patients {
-		if patients[i].RG == patient.RG {
-			return domain.Patient{}, errors.New("rg already exists")
-		}
-	}
-
 	updatedPatient, err := s.r.Patch(id, patient)
 	if err != nil {
 		return domain.Patient{}, err
 	}
+	s.indexName(id, updatedPatient)
 	return updatedPatient, nil
 }
 
+// Delete removes the patient, unless they are still the guardian of another
+// patient.
 func (s *service) Delete(id int) error {
-	err := s.r.Delete(id)
+	wards, err := s.r.ReadWards(id)
+	if err != nil {
+		return err
+	}
+	if len(wards) > 0 {
+		return &GuardianOfError{PatientId: id, WardIds: wards}
+	}
+	err = s.r.Delete(id)
+	if err != nil {
+		return err
+	}
+	s.unindexName(id)
+	return nil
+}
+
+// IssueCalendarToken replaces the patient's calendar feed token, revoking
+// the previous one.
+func (s *service) IssueCalendarToken(id int) (string, error) {
+	newToken, err := token.New()
+	if err != nil {
+		return "", err
+	}
+	if err := s.r.UpdateCalendarToken(id, newToken); err != nil {
+		return "", err
+	}
+	return newToken, nil
+}
+
+func (s *service) CheckCalendarToken(id int, calendarToken string) error {
+	persistedToken, err := s.r.ReadCalendarToken(id)
 	if err != nil {
 		return err
 	}
+	if !token.Equal(persistedToken, calendarToken) {
+		return errors.New("invalid calendar token")
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// LiftRestriction lets the patient be booked again without a staff
+// override. The counters are kept, so a further late cancellation or no-show
+// restricts the patient again.
+func (s *service) LiftRestriction(id int) (domain.Patient, error) {
+	if err := s.r.UpdateRestricted(id, false); err != nil {
+		return domain.Patient{}, err
+	}
+	return s.r.ReadById(id)
+}
+
+// SearchByName ranks the patients whose name and surname match query, for
+// type-ahead. The index behind it is built on first use and then kept in step
+// with the writes and imports of this process.
+func (s *service) SearchByName(query string, limit int) ([]domain.Patient, error) {
+	index, err := s.nameIndex()
+	if err != nil {
+		return []domain.Patient{}, err
+	}
+
+	patients := []domain.Patient{}
+	for _, match := range index.Search(query, limit) {
+		patient, err := s.r.ReadById(match.Id)
+		if err != nil {
+			return []domain.Patient{}, err
+		}
+		patients = append(patients, patient)
+	}
+	return patients, nil
+}
+
+// Imported adds the patients of an import to the name index.
+func (s *service) Imported(batch domain.ImportBatch) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.index == nil {
+		return
+	}
+	for _, row := range batch.Patients {
+		if patient, err := s.r.ReadByRg(row.Patient.RG); err == nil {
+			s.index.Put(patient.Id, patient.Name, patient.Surname)
+		}
+	}
+}
+
+func (s *service) nameIndex() (*search.Index, error) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.index != nil {
+		return s.index, nil
+	}
+
+	patients, err := s.r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	index := search.NewIndex()
+	for _, patient := range patients {
+		index.Put(patient.Id, patient.Name, patient.Surname)
+	}
+	s.index = index
+	return index, nil
+}
+
+// indexName updates the name index, if it was built already.
+func (s *service) indexName(id int, patient domain.Patient) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.index != nil {
+		s.index.Put(id, patient.Name, patient.Surname)
+	}
+}
+
+func (s *service) unindexName(id int) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.index != nil {
+		s.index.Remove(id)
+	}
+}
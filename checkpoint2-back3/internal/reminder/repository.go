@@ -0,0 +1,63 @@
+package reminder
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/store"
+	"time"
+)
+
+type Repository interface {
+	Claim(reminder domain.Reminder) (domain.Reminder, bool, error)
+	Reclaim(reminder domain.Reminder, claimedBefore time.Time) (domain.Reminder, bool, error)
+	UpdateStatus(id int, status string, sendError string, sentAt *time.Time) error
+	ReadByToken(token string) (domain.Reminder, error)
+	ReadByAppointment(idAppointment int) ([]domain.Reminder, error)
+}
+
+type repository struct {
+	storage store.ReminderStoreInterface
+}
+
+func NewRepository(storage store.ReminderStoreInterface) Repository {
+	return &repository{storage}
+}
+
+func (r *repository) Claim(reminder domain.Reminder) (domain.Reminder, bool, error) {
+	claimed, ok, err := r.storage.Claim(reminder)
+	if err != nil {
+		return domain.Reminder{}, false, err
+	}
+	return claimed, ok, nil
+}
+
+func (r *repository) Reclaim(reminder domain.Reminder, claimedBefore time.Time) (domain.Reminder, bool, error) {
+	claimed, ok, err := r.storage.Reclaim(reminder, claimedBefore)
+	if err != nil {
+		return domain.Reminder{}, false, err
+	}
+	return claimed, ok, nil
+}
+
+func (r *repository) UpdateStatus(id int, status string, sendError string, sentAt *time.Time) error {
+	err := r.storage.UpdateStatus(id, status, sendError, sentAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ReadByToken(token string) (domain.Reminder, error) {
+	reminder, err := r.storage.ReadByToken(token)
+	if err != nil {
+		return domain.Reminder{}, err
+	}
+	return reminder, nil
+}
+
+func (r *repository) ReadByAppointment(idAppointment int) ([]domain.Reminder, error) {
+	reminders, err := r.storage.ReadByAppointment(idAppointment)
+	if err != nil {
+		return []domain.Reminder{}, err
+	}
+	return reminders, nil
+}
@@ -0,0 +1,271 @@
+package reminder
+
+import (
+	"checkpoint2/internal/appointment"
+	"checkpoint2/internal/domain"
+	"checkpoint2/internal/patient"
+	"checkpoint2/pkg/notify"
+	"checkpoint2/pkg/token"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const searchPageSize = 200
+
+// ErrOutdated is returned for the links of a reminder sent before its
+// appointment was rescheduled.
+var ErrOutdated = errors.New("reminder is out of date, the appointment was rescheduled")
+
+// Config sets when reminders go out and how they reach the patient. Offsets
+// are durations before the appointment start, such as 48h and 2h. BaseURL is
+// the public address used in the confirm and cancel links. A reminder left
+// sending for longer than ClaimTimeout, as when the process stopped while
+// sending it, is claimed again by the next run.
+type Config struct {
+	Offsets      []time.Duration
+	Channel      string
+	BaseURL      string
+	ClaimTimeout time.Duration
+}
+
+type Service interface {
+	SendDue(now time.Time) error
+	ReadByAppointment(idAppointment int) ([]domain.Reminder, error)
+	ReadAppointment(token string) (domain.Appointment, error)
+	Confirm(token string) (domain.Appointment, error)
+	Cancel(token string) (domain.Appointment, error)
+}
+
+type service struct {
+	r            Repository
+	appointments appointment.Service
+	patients     patient.Repository
+	notifier     notify.Notifier
+	config       Config
+}
+
+func NewService(r Repository, appointments appointment.Service, patients patient.Repository, notifier notify.Notifier, config Config) Service {
+	offsets := append([]time.Duration{}, config.Offsets...)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	config.Offsets = offsets
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	return &service{r: r, appointments: appointments, patients: patients, notifier: notifier, config: config}
+}
+
+// SendDue sends the reminders whose offset has been reached for the
+// appointments still to come. When several offsets are due at once, as for
+// an appointment booked at short notice, only the closest one is sent and the
+// others are recorded as skipped. An appointment that can't be reminded is
+// logged and left for the next run.
+func (s *service) SendDue(now time.Time) error {
+	if len(s.config.Offsets) == 0 {
+		return nil
+	}
+
+	filter := domain.AppointmentFilter{
+		From:  now,
+		To:    now.Add(s.config.Offsets[len(s.config.Offsets)-1]),
+		Sort:  "start",
+		Limit: searchPageSize,
+	}
+	for {
+		appointments, total, err := s.appointments.Search(filter)
+		if err != nil {
+			return err
+		}
+		for _, a := range appointments {
+			if err := s.remind(a, now); err != nil {
+				log.Printf("reminder: appointment %d: %v", a.Id, err)
+			}
+		}
+		filter.Offset += len(appointments)
+		if len(appointments) == 0 || filter.Offset >= total {
+			return nil
+		}
+	}
+}
+
+func (s *service) remind(a domain.Appointment, now time.Time) error {
+	if a.Status != domain.StatusScheduled && a.Status != domain.StatusConfirmed || !a.Start.After(now) {
+		return nil
+	}
+
+	var due []time.Duration
+	for _, offset := range s.config.Offsets {
+		if a.Start.Sub(now) <= offset {
+			due = append(due, offset)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	for _, offset := range due[1:] {
+		if _, _, err := s.claim(a, offset, domain.ReminderSkipped, now); err != nil {
+			return err
+		}
+	}
+
+	to, guardian, err := s.recipient(a)
+	if err != nil {
+		return err
+	}
+
+	reminder, claimed, err := s.claim(a, due[0], domain.ReminderSending, now)
+	if err != nil || !claimed {
+		return err
+	}
+
+	if err := s.notifier.Notify(s.message(a, reminder, to, guardian)); err != nil {
+		log.Printf("reminder: appointment %d: %v", a.Id, err)
+		return s.r.UpdateStatus(reminder.Id, domain.ReminderFailed, err.Error(), nil)
+	}
+	sentAt := time.Now()
+	return s.r.UpdateStatus(reminder.Id, domain.ReminderSent, "", &sentAt)
+}
+
+// claim records the reminder of a at offset with status, unless it already
+// is. A reminder being sent is also claimed when the one on record has been
+// left sending for longer than the claim timeout.
+func (s *service) claim(a domain.Appointment, offset time.Duration, status string, now time.Time) (domain.Reminder, bool, error) {
+	reminderToken, err := token.New()
+	if err != nil {
+		return domain.Reminder{}, false, err
+	}
+	reminder := domain.Reminder{
+		AppointmentId: a.Id,
+		Start:         a.Start,
+		Offset:        offset,
+		Channel:       s.config.Channel,
+		Token:         reminderToken,
+		Status:        status,
+		CreatedAt:     now,
+		ClaimedAt:     now,
+	}
+	claimed, ok, err := s.r.Claim(reminder)
+	if err != nil || ok || status != domain.ReminderSending {
+		return claimed, ok, err
+	}
+	return s.r.Reclaim(reminder, now.Add(-s.config.ClaimTimeout))
+}
+
+// recipient tells who the reminder of a goes to: the patient or, for a minor
+// on the day of the appointment, their first guardian. Guardians who are
+// patients are reached through their own contacts. guardian tells which one
+// it is.
+func (s *service) recipient(a domain.Appointment) (to notify.Message, guardian bool, err error) {
+	patient, err := s.patients.ReadById(a.Patient.Id)
+	if err != nil {
+		return notify.Message{}, false, err
+	}
+
+	if !patient.MinorOn(a.Start) || len(patient.Guardians) == 0 {
+		to = notify.Message{Name: patient.Name + " " + patient.Surname}
+		if patient.Contacts != nil {
+			to.Email, to.Phone = patient.Contacts.Email, contactPhone(patient.Contacts.Phones)
+		}
+		return to, false, nil
+	}
+
+	first := patient.Guardians[0]
+	to = notify.Message{Name: first.Name, Email: first.Email}
+	if first.Phone != "" {
+		to.Phone = "+55" + first.Phone
+	}
+	if first.PatientId != 0 {
+		contacts, err := s.patients.ReadContacts(first.PatientId)
+		if err != nil {
+			return notify.Message{}, false, err
+		}
+		to.Email, to.Phone = contacts.Email, contactPhone(contacts.Phones)
+	}
+	return to, true, nil
+}
+
+// contactPhone picks the phone to text, in international format: the mobile
+// phone, or the first phone when there is no mobile.
+func contactPhone(phones []domain.Phone) string {
+	var phone string
+	for _, p := range phones {
+		if phone == "" || p.Type == domain.PhoneMobile {
+			phone = "+55" + p.Number
+		}
+		if p.Type == domain.PhoneMobile {
+			break
+		}
+	}
+	return phone
+}
+
+// message writes the reminder of a to to, speaking of the patient in the
+// third person when to is their guardian.
+func (s *service) message(a domain.Appointment, reminder domain.Reminder, to notify.Message, guardian bool) notify.Message {
+	start := a.Start
+	link := fmt.Sprintf("%s/reminders/%s", s.config.BaseURL, reminder.Token)
+
+	greeting, whose := a.Patient.Name, "your"
+	if guardian {
+		greeting, whose = to.Name, a.Patient.Name+"'s"
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", greeting)
+	fmt.Fprintf(&body, "This is a reminder of %s appointment with Dr. %s %s on %s at %s (%s).\n\n",
+		whose, a.Dentist.Name, a.Dentist.Surname, start.Format("02/01/2006"), start.Format("15:04 MST"), a.Description)
+	fmt.Fprintf(&body, "Confirm: %s/confirm\n", link)
+	fmt.Fprintf(&body, "Cancel: %s/cancel\n", link)
+
+	to.Subject = "Appointment reminder for " + start.Format("02/01/2006 15:04")
+	to.Body = body.String()
+	return to
+}
+
+func (s *service) ReadByAppointment(idAppointment int) ([]domain.Reminder, error) {
+	if _, err := s.appointments.ReadById(idAppointment); err != nil {
+		return []domain.Reminder{}, err
+	}
+	reminders, err := s.r.ReadByAppointment(idAppointment)
+	if err != nil {
+		return []domain.Reminder{}, err
+	}
+	return reminders, nil
+}
+
+// ReadAppointment returns the appointment of the reminder whose link carried
+// reminderToken, as long as it still starts when the reminder said.
+func (s *service) ReadAppointment(reminderToken string) (domain.Appointment, error) {
+	reminder, err := s.r.ReadByToken(reminderToken)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	appointment, err := s.appointments.ReadById(reminder.AppointmentId)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if !appointment.Start.Equal(reminder.Start) {
+		return domain.Appointment{}, ErrOutdated
+	}
+	return appointment, nil
+}
+
+// Confirm confirms the appointment of the reminder whose link carried
+// reminderToken, on behalf of the patient.
+func (s *service) Confirm(reminderToken string) (domain.Appointment, error) {
+	appointment, err := s.ReadAppointment(reminderToken)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	return s.appointments.Transition(appointment.Id, domain.StatusConfirmed, "patient")
+}
+
+func (s *service) Cancel(reminderToken string) (domain.Appointment, error) {
+	appointment, err := s.ReadAppointment(reminderToken)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	return s.appointments.Cancel(appointment.Id, domain.Cancellation{Reason: "cancelled from reminder", CancelledBy: domain.CancelledByPatient})
+}
@@ -0,0 +1,74 @@
+package reminder
+
+import (
+	"checkpoint2/internal/domain"
+	"testing"
+	"time"
+)
+
+// fakeReminders keeps the reminders on record by appointment and offset, as
+// the unique index of the SQL store does.
+type fakeReminders struct {
+	Repository
+	reminders map[time.Duration]domain.Reminder
+}
+
+func (r *fakeReminders) Claim(reminder domain.Reminder) (domain.Reminder, bool, error) {
+	if _, ok := r.reminders[reminder.Offset]; ok {
+		return domain.Reminder{}, false, nil
+	}
+	reminder.Id = len(r.reminders) + 1
+	r.reminders[reminder.Offset] = reminder
+	return reminder, true, nil
+}
+
+func (r *fakeReminders) Reclaim(reminder domain.Reminder, claimedBefore time.Time) (domain.Reminder, bool, error) {
+	stored := r.reminders[reminder.Offset]
+	if stored.Status != domain.ReminderSending || !stored.ClaimedAt.Before(claimedBefore) {
+		return domain.Reminder{}, false, nil
+	}
+	stored.ClaimedAt = reminder.ClaimedAt
+	r.reminders[reminder.Offset] = stored
+	return stored, true, nil
+}
+
+func TestClaim(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	a := domain.Appointment{Id: 1, Start: now.Add(2 * time.Hour)}
+
+	tests := []struct {
+		name      string
+		status    string
+		claimedAt time.Time
+		want      bool
+	}{
+		{"sending, left behind", domain.ReminderSending, now.Add(-11 * time.Minute), true},
+		{"sending, still in time", domain.ReminderSending, now.Add(-9 * time.Minute), false},
+		{"sent", domain.ReminderSent, now.Add(-time.Hour), false},
+		{"failed", domain.ReminderFailed, now.Add(-time.Hour), false},
+		{"skipped", domain.ReminderSkipped, now.Add(-time.Hour), false},
+	}
+	for _, tt := range tests {
+		r := &fakeReminders{reminders: map[time.Duration]domain.Reminder{
+			2 * time.Hour: {Id: 7, AppointmentId: 1, Offset: 2 * time.Hour, Token: "first", Status: tt.status, ClaimedAt: tt.claimedAt},
+		}}
+		s := &service{r: r, config: Config{ClaimTimeout: 10 * time.Minute}}
+
+		reminder, claimed, err := s.claim(a, 2*time.Hour, domain.ReminderSending, now)
+		if err != nil || claimed != tt.want {
+			t.Errorf("%s: claim() = %v, %v, want claimed %v", tt.name, claimed, err, tt.want)
+			continue
+		}
+		if claimed && (reminder.Id != 7 || reminder.Token != "first" || !reminder.ClaimedAt.Equal(now)) {
+			t.Errorf("%s: claim() = %+v, want reminder 7 with its token, claimed now", tt.name, reminder)
+		}
+	}
+
+	r := &fakeReminders{reminders: map[time.Duration]domain.Reminder{
+		2 * time.Hour: {Id: 7, AppointmentId: 1, Offset: 2 * time.Hour, Status: domain.ReminderSending, ClaimedAt: now.Add(-time.Hour)},
+	}}
+	s := &service{r: r, config: Config{ClaimTimeout: 10 * time.Minute}}
+	if _, claimed, err := s.claim(a, 2*time.Hour, domain.ReminderSkipped, now); err != nil || claimed {
+		t.Errorf("claim() of a skipped reminder = %v, %v, want it left to its sender", claimed, err)
+	}
+}
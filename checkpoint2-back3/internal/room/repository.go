@@ -0,0 +1,80 @@
+package room
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/store"
+)
+
+type Repository interface {
+	ReadById(id int) (domain.Room, error)
+	ReadAll() ([]domain.Room, error)
+	ReadByClinic(idClinic int) ([]domain.Room, error)
+	Create(room domain.Room) (domain.Room, error)
+	Update(id int, room domain.Room) (domain.Room, error)
+	Patch(id int, room domain.Room) (domain.Room, error)
+	Delete(id int) error
+}
+
+type repository struct {
+	storage store.RoomStoreInterface
+}
+
+func NewRepository(storage store.RoomStoreInterface) Repository {
+	return &repository{storage}
+}
+
+func (r *repository) ReadById(id int) (domain.Room, error) {
+	room, err := r.storage.ReadById(id)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	return room, nil
+}
+
+func (r *repository) ReadAll() ([]domain.Room, error) {
+	rooms, err := r.storage.ReadAll()
+	if err != nil {
+		return []domain.Room{}, err
+	}
+	return rooms, nil
+}
+
+func (r *repository) ReadByClinic(idClinic int) ([]domain.Room, error) {
+	rooms, err := r.storage.ReadByClinic(idClinic)
+	if err != nil {
+		return []domain.Room{}, err
+	}
+	return rooms, nil
+}
+
+func (r *repository) Create(ro domain.Room) (domain.Room, error) {
+	room, err := r.storage.Create(ro)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	return room, nil
+}
+
+func (r *repository) Update(id int, ro domain.Room) (domain.Room, error) {
+	room, err := r.storage.Update(id, ro)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	return room, nil
+}
+
+func (r *repository) Patch(id int, ro domain.Room) (domain.Room, error) {
+	room, err := r.storage.Patch(id, ro)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	return room, nil
+}
+
+func (r *repository) Delete(id int) error {
+	err := r.storage.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package room
+
+import (
+	"checkpoint2/internal/clinic"
+	"checkpoint2/internal/domain"
+	"strings"
+)
+
+type Service interface {
+	ReadById(id int) (domain.Room, error)
+	ReadAll() ([]domain.Room, error)
+	ReadByClinic(idClinic int) ([]domain.Room, error)
+	Create(room domain.Room) (domain.Room, error)
+	Update(id int, room domain.Room) (domain.Room, error)
+	Patch(id int, room domain.Room) (domain.Room, error)
+	Delete(id int) error
+}
+
+type service struct {
+	r       Repository
+	clinics clinic.Repository
+}
+
+func NewService(r Repository, clinics clinic.Repository) Service {
+	return &service{r, clinics}
+}
+
+func (s *service) ReadById(id int) (domain.Room, error) {
+	room, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	return room, nil
+}
+
+func (s *service) ReadAll() ([]domain.Room, error) {
+	rooms, err := s.r.ReadAll()
+	if err != nil {
+		return []domain.Room{}, err
+	}
+	return rooms, nil
+}
+
+func (s *service) ReadByClinic(idClinic int) ([]domain.Room, error) {
+	rooms, err := s.r.ReadByClinic(idClinic)
+	if err != nil {
+		return []domain.Room{}, err
+	}
+	return rooms, nil
+}
+
+func (s *service) Create(room domain.Room) (domain.Room, error) {
+	if err := s.normalize(&room); err != nil {
+		return domain.Room{}, err
+	}
+	createdRoom, err := s.r.Create(room)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	return createdRoom, nil
+}
+
+func (s *service) Update(id int, room domain.Room) (domain.Room, error) {
+	if err := s.normalize(&room); err != nil {
+		return domain.Room{}, err
+	}
+	updatedRoom, err := s.r.Update(id, room)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	return updatedRoom, nil
+}
+
+func (s *service) Patch(id int, room domain.Room) (domain.Room, error) {
+	if err := s.normalize(&room); err != nil {
+		return domain.Room{}, err
+	}
+	updatedRoom, err := s.r.Patch(id, room)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	return updatedRoom, nil
+}
+
+func (s *service) Delete(id int) error {
+	err := s.r.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalize trims the name, lower-cases the equipment and drops repeated
+// items, and rejects an unknown clinic. The database rejects a name already
+// used by another room.
+func (s *service) normalize(room *domain.Room) error {
+	if room.ClinicId != 0 {
+		if _, err := s.clinics.ReadById(room.ClinicId); err != nil {
+			return err
+		}
+	}
+
+	room.Name = strings.TrimSpace(room.Name)
+	if room.Equipment != nil {
+		equipment, err := domain.NormalizeEquipment(room.Equipment)
+		if err != nil {
+			return err
+		}
+		room.Equipment = equipment
+	}
+	return nil
+}
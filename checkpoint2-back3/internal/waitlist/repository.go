@@ -0,0 +1,117 @@
+package waitlist
+
+import (
+	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/store"
+	"time"
+)
+
+type Repository interface {
+	ReadById(id int) (domain.WaitlistEntry, error)
+	ReadAll(idDentist int, idClinic int) ([]domain.WaitlistEntry, error)
+	ReadCandidates(idDentist int, idClinic int, start time.Time, end time.Time) ([]domain.WaitlistEntry, error)
+	Create(entry domain.WaitlistEntry) (domain.WaitlistEntry, error)
+	UpdateStatus(id int, status string) error
+	ReadHold(id int) (domain.SlotHold, error)
+	ReadHoldsBySlot(idDentist int, start time.Time) ([]domain.SlotHold, error)
+	ReadExpiredHolds(now time.Time) ([]domain.SlotHold, error)
+	Offer(hold domain.SlotHold) (domain.SlotHold, error)
+	UpdateHoldStatus(id int, status string) error
+	Claim(hold domain.SlotHold, appointment domain.Appointment) (int, error)
+}
+
+type repository struct {
+	storage store.WaitlistStoreInterface
+}
+
+func NewRepository(storage store.WaitlistStoreInterface) Repository {
+	return &repository{storage}
+}
+
+func (r *repository) ReadById(id int) (domain.WaitlistEntry, error) {
+	entry, err := r.storage.ReadById(id)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+	return entry, nil
+}
+
+func (r *repository) ReadAll(idDentist int, idClinic int) ([]domain.WaitlistEntry, error) {
+	entries, err := r.storage.ReadAll(idDentist, idClinic)
+	if err != nil {
+		return []domain.WaitlistEntry{}, err
+	}
+	return entries, nil
+}
+
+func (r *repository) ReadCandidates(idDentist int, idClinic int, start time.Time, end time.Time) ([]domain.WaitlistEntry, error) {
+	entries, err := r.storage.ReadCandidates(idDentist, idClinic, start, end)
+	if err != nil {
+		return []domain.WaitlistEntry{}, err
+	}
+	return entries, nil
+}
+
+func (r *repository) Create(e domain.WaitlistEntry) (domain.WaitlistEntry, error) {
+	entry, err := r.storage.Create(e)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+	return entry, nil
+}
+
+func (r *repository) UpdateStatus(id int, status string) error {
+	err := r.storage.UpdateStatus(id, status)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ReadHold(id int) (domain.SlotHold, error) {
+	hold, err := r.storage.ReadHold(id)
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+	return hold, nil
+}
+
+func (r *repository) ReadHoldsBySlot(idDentist int, start time.Time) ([]domain.SlotHold, error) {
+	holds, err := r.storage.ReadHoldsBySlot(idDentist, start)
+	if err != nil {
+		return []domain.SlotHold{}, err
+	}
+	return holds, nil
+}
+
+func (r *repository) ReadExpiredHolds(now time.Time) ([]domain.SlotHold, error) {
+	holds, err := r.storage.ReadExpiredHolds(now)
+	if err != nil {
+		return []domain.SlotHold{}, err
+	}
+	return holds, nil
+}
+
+func (r *repository) Offer(h domain.SlotHold) (domain.SlotHold, error) {
+	hold, err := r.storage.Offer(h)
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+	return hold, nil
+}
+
+func (r *repository) UpdateHoldStatus(id int, status string) error {
+	err := r.storage.UpdateHoldStatus(id, status)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *repository) Claim(hold domain.SlotHold, appointment domain.Appointment) (int, error) {
+	id, err := r.storage.Claim(hold, appointment)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,229 @@
+package waitlist
+
+import (
+	"checkpoint2/internal/appointment"
+	"checkpoint2/internal/domain"
+	"errors"
+	"log"
+	"time"
+)
+
+type Service interface {
+	ReadById(id int) (domain.WaitlistEntry, error)
+	ReadAll(idDentist int, idClinic int) ([]domain.WaitlistEntry, error)
+	Join(entry domain.WaitlistEntry) (domain.WaitlistEntry, error)
+	Leave(id int) error
+	Claim(idHold int) (domain.Appointment, error)
+	Decline(idHold int) error
+	ExpireHolds() error
+	SlotReleased(released domain.Appointment)
+}
+
+type service struct {
+	r            Repository
+	appointments appointment.Service
+	holdDuration time.Duration
+}
+
+// NewService returns a waitlist service that offers freed slots for
+// holdDuration before passing them to the next candidate.
+func NewService(r Repository, appointments appointment.Service, holdDuration time.Duration) Service {
+	return &service{r, appointments, holdDuration}
+}
+
+func (s *service) ReadById(id int) (domain.WaitlistEntry, error) {
+	entry, err := s.r.ReadById(id)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+	return entry, nil
+}
+
+func (s *service) ReadAll(idDentist int, idClinic int) ([]domain.WaitlistEntry, error) {
+	entries, err := s.r.ReadAll(idDentist, idClinic)
+	if err != nil {
+		return []domain.WaitlistEntry{}, err
+	}
+	return entries, nil
+}
+
+func (s *service) Join(entry domain.WaitlistEntry) (domain.WaitlistEntry, error) {
+	createdEntry, err := s.r.Create(entry)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+	return createdEntry, nil
+}
+
+func (s *service) Leave(id int) error {
+	entry, err := s.r.ReadById(id)
+	if err != nil {
+		return err
+	}
+	if entry.Status == domain.WaitlistBooked || entry.Status == domain.WaitlistLeft {
+		return errors.New("waitlist entry is already closed")
+	}
+
+	if err := s.r.UpdateStatus(id, domain.WaitlistLeft); err != nil {
+		return err
+	}
+
+	for _, hold := range entry.Holds {
+		if hold.Status == domain.HoldPending {
+			return s.passHold(hold, domain.HoldDeclined)
+		}
+	}
+	return nil
+}
+
+// Claim books the held slot for the patient of the hold's entry. The booking
+// is checked like any other, except against the hold itself, and saved
+// together with the hold and the entry it closes.
+func (s *service) Claim(idHold int) (domain.Appointment, error) {
+	hold, err := s.r.ReadHold(idHold)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if hold.Status != domain.HoldPending || !time.Now().Before(hold.ExpiresAt) {
+		return domain.Appointment{}, errors.New("hold is no longer available")
+	}
+	entry, err := s.r.ReadById(hold.EntryId)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+
+	booking := domain.Appointment{
+		Start:       hold.Start,
+		End:         hold.End,
+		Description: "Waitlist",
+		ClinicId:    hold.ClinicId,
+	}
+	booking, err = s.appointments.Prepare(booking, entry.Patient.Id, hold.DentistId)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+
+	id, err := s.r.Claim(hold, booking)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	return s.appointments.ReadById(id)
+}
+
+func (s *service) Decline(idHold int) error {
+	hold, err := s.r.ReadHold(idHold)
+	if err != nil {
+		return err
+	}
+	if hold.Status != domain.HoldPending {
+		return errors.New("hold is no longer available")
+	}
+	if err := s.r.UpdateStatus(hold.EntryId, domain.WaitlistWaiting); err != nil {
+		return err
+	}
+	return s.passHold(hold, domain.HoldDeclined)
+}
+
+// ExpireHolds closes the holds nobody claimed in time and offers their slots
+// to the next candidates.
+func (s *service) ExpireHolds() error {
+	holds, err := s.r.ReadExpiredHolds(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, hold := range holds {
+		if err := s.r.UpdateStatus(hold.EntryId, domain.WaitlistWaiting); err != nil {
+			return err
+		}
+		if err := s.passHold(hold, domain.HoldExpired); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SlotReleased offers a freed slot to the first matching waitlist entry.
+func (s *service) SlotReleased(released domain.Appointment) {
+	slot := domain.Slot{Start: released.Start, End: released.End}
+	if err := s.offer(released.Dentist.Id, released.ClinicId, slot); err != nil {
+		log.Println("waitlist:", err)
+	}
+}
+
+// passHold closes hold with status and offers its slot to the next candidate.
+func (s *service) passHold(hold domain.SlotHold, status string) error {
+	if err := s.r.UpdateHoldStatus(hold.Id, status); err != nil {
+		return err
+	}
+	return s.offer(hold.DentistId, hold.ClinicId, domain.Slot{Start: hold.Start, End: hold.End})
+}
+
+// offer places a hold on slot for the oldest waiting entry that matches it
+// and wasn't offered this slot before. The hold keeps the clinic the slot
+// was freed at, so that claiming it books there again. Entries' dates and
+// times of day are compared with the slot in the clinic's zone.
+func (s *service) offer(idDentist int, idClinic int, slot domain.Slot) error {
+	if !slot.Start.After(time.Now()) {
+		return nil
+	}
+	loc, err := s.appointments.Location(idDentist, idClinic)
+	if err != nil {
+		return err
+	}
+	slot = domain.Slot{Start: slot.Start.In(loc), End: slot.End.In(loc)}
+	candidates, err := s.r.ReadCandidates(idDentist, idClinic, slot.Start, slot.End)
+	if err != nil {
+		return err
+	}
+	previous, err := s.r.ReadHoldsBySlot(idDentist, slot.Start)
+	if err != nil {
+		return err
+	}
+	for _, hold := range previous {
+		if hold.Status == domain.HoldPending {
+			return nil
+		}
+	}
+
+	for _, entry := range candidates {
+		if !matchesTimeOfDay(entry, slot) || wasOffered(entry, previous) {
+			continue
+		}
+
+		hold := domain.SlotHold{
+			EntryId:   entry.Id,
+			DentistId: idDentist,
+			ClinicId:  idClinic,
+			Start:     slot.Start,
+			End:       slot.End,
+			ExpiresAt: time.Now().Add(s.holdDuration),
+			Status:    domain.HoldPending,
+		}
+		createdHold, err := s.r.Offer(hold)
+		if err != nil {
+			return err
+		}
+		log.Printf("waitlist: slot %s held for entry %d until %s", slot.Start.Format(time.RFC3339), entry.Id, createdHold.ExpiresAt.Format(time.RFC3339))
+		return nil
+	}
+	return nil
+}
+
+func matchesTimeOfDay(entry domain.WaitlistEntry, slot domain.Slot) bool {
+	if entry.EarliestTime != "" && slot.Start.Format("15:04") < entry.EarliestTime {
+		return false
+	}
+	if entry.LatestTime != "" && slot.End.Format("15:04") > entry.LatestTime {
+		return false
+	}
+	return true
+}
+
+func wasOffered(entry domain.WaitlistEntry, holds []domain.SlotHold) bool {
+	for _, hold := range holds {
+		if hold.EntryId == entry.Id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+-- Appointments left scheduled or confirmed from before no-shows were flagged
+-- are taken as attended, so that they don't count against their patients.
+-- Run once on databases created before that; a fresh install of
+-- checkpoint2_backend3-db.sql doesn't need it.
+START TRANSACTION;
+
+INSERT INTO `checkpoint2`.`appointment_status_history` (`appointment_id`, `from_status`, `to_status`, `changed_by`, `changed_at`)
+SELECT `id`, `status`, 'completed', 'system', UTC_TIMESTAMP()
+FROM `checkpoint2`.`appointment`
+WHERE `status` IN ('scheduled', 'confirmed') AND `end_time` < UTC_TIMESTAMP();
+
+UPDATE `checkpoint2`.`appointment`
+SET `status` = 'completed'
+WHERE `status` IN ('scheduled', 'confirmed') AND `end_time` < UTC_TIMESTAMP();
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- RGs are kept without punctuation and in upper case, as document.RG leaves
+-- them, so that lookups find the records saved before that. Two records of
+-- the same RG written apart make this fail on idx_patient_rg and have to be
+-- merged first. Run once on databases created before RGs were normalised; a
+-- fresh install of checkpoint2_backend3-db.sql doesn't need it.
+UPDATE `checkpoint2`.`patient`
+SET `rg` = UPPER(REGEXP_REPLACE(`rg`, '[^0-9A-Za-z]', ''))
+WHERE `rg` <> UPPER(REGEXP_REPLACE(`rg`, '[^0-9A-Za-z]', ''));
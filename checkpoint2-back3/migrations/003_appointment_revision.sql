@@ -0,0 +1,7 @@
+-- Appointments count their changes and keep the time of the last one, which
+-- calendar feeds send as SEQUENCE and LAST-MODIFIED. Existing appointments
+-- start at sequence 0, last changed now. Run once on databases created
+-- before that; a fresh install of checkpoint2_backend3-db.sql doesn't need it.
+ALTER TABLE `checkpoint2`.`appointment`
+ADD COLUMN `sequence` INT NOT NULL DEFAULT 0,
+ADD COLUMN `updated_at` DATETIME NOT NULL DEFAULT (UTC_TIMESTAMP());
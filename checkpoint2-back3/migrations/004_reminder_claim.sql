@@ -0,0 +1,13 @@
+-- Reminders keep when they were last claimed, so that one left sending by a
+-- process that stopped halfway is claimed again once that is old enough.
+-- Reminders on record take their creation as their claim. Run once on
+-- databases created before that; a fresh install of
+-- checkpoint2_backend3-db.sql doesn't need it.
+ALTER TABLE `checkpoint2`.`appointment_reminder`
+ADD COLUMN `claimed_at` DATETIME NULL AFTER `created_at`;
+
+UPDATE `checkpoint2`.`appointment_reminder`
+SET `claimed_at` = `created_at`;
+
+ALTER TABLE `checkpoint2`.`appointment_reminder`
+MODIFY COLUMN `claimed_at` DATETIME NOT NULL;
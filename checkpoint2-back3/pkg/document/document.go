@@ -0,0 +1,65 @@
+package document
+
+import (
+	"errors"
+	"strings"
+)
+
+// CPF checks a CPF number, with or without its punctuation, and returns its
+// 11 digits. Numbers whose digits are all the same pass the check digits but
+// are not valid.
+func CPF(cpf string) (string, error) {
+	var digits []int
+	for _, r := range strings.TrimSpace(cpf) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, int(r-'0'))
+		case r == '.' || r == '-' || r == ' ':
+		default:
+			return "", errors.New("invalid cpf")
+		}
+	}
+	if len(digits) != 11 {
+		return "", errors.New("invalid cpf, expected 11 digits")
+	}
+
+	repeated := true
+	for _, d := range digits[1:] {
+		repeated = repeated && d == digits[0]
+	}
+	if repeated || checkDigit(digits[:9]) != digits[9] || checkDigit(digits[:10]) != digits[10] {
+		return "", errors.New("invalid cpf check digits")
+	}
+
+	var b strings.Builder
+	for _, d := range digits {
+		b.WriteByte(byte('0' + d))
+	}
+	return b.String(), nil
+}
+
+// checkDigit computes the digit following digits: their sum weighted from
+// len(digits)+1 down to 2, modulo 11, where remainders under 2 give 0.
+func checkDigit(digits []int) int {
+	sum := 0
+	for i, d := range digits {
+		sum += d * (len(digits) + 1 - i)
+	}
+	if rest := sum % 11; rest >= 2 {
+		return 11 - rest
+	}
+	return 0
+}
+
+// RG strips the punctuation states print RG numbers with, keeping its
+// letters and digits in upper case, so that "12.345.678-x" and "12345678X"
+// are the same RG.
+func RG(rg string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(rg) {
+		if r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
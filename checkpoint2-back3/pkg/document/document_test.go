@@ -0,0 +1,54 @@
+package document
+
+import "testing"
+
+func TestCPF(t *testing.T) {
+	tests := []struct {
+		cpf     string
+		want    string
+		wantErr bool
+	}{
+		{"529.982.247-25", "52998224725", false},
+		{"52998224725", "52998224725", false},
+		{"111.444.777-35", "11144477735", false},
+		{" 390.533.447-05 ", "39053344705", false},
+		{"123 456 789 09", "12345678909", false},
+		{"529.982.247-24", "", true},
+		{"529.982.247-15", "", true},
+		{"111.111.111-11", "", true},
+		{"000.000.000-00", "", true},
+		{"5299822472", "", true},
+		{"529982247250", "", true},
+		{"529.982.247/25", "", true},
+		{"52998224a25", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := CPF(tt.cpf)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CPF(%q) error = %v, want error %v", tt.cpf, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CPF(%q) = %q, want %q", tt.cpf, got, tt.want)
+		}
+	}
+}
+
+func TestRG(t *testing.T) {
+	tests := []struct {
+		rg   string
+		want string
+	}{
+		{"12.345.678-9", "123456789"},
+		{"12.345.678-x", "12345678X"},
+		{"12345678X", "12345678X"},
+		{" mg-12.345.678 ", "MG12345678"},
+		{"--", ""},
+	}
+	for _, tt := range tests {
+		if got := RG(tt.rg); got != tt.want {
+			t.Errorf("RG(%q) = %q, want %q", tt.rg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package ical
+
+import (
+	"bufio"
+	"checkpoint2/pkg/timezone"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateTimeLayout      = "20060102T150405Z"
+	localDateTimeLayout = "20060102T150405"
+	maxLineOctets       = 75
+)
+
+// Event is the subset of an RFC 5545 VEVENT used for appointments.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	Status      string
+	// Sequence and LastModified tell calendar apps that an event they
+	// already have under UID has changed. Parse leaves them in Properties.
+	Sequence     int
+	LastModified time.Time
+	// Properties holds the raw values of the properties not mapped above,
+	// such as X- extensions, keyed by upper-case name.
+	Properties map[string]string
+	// Err is set when a property of the event couldn't be read, such as an
+	// all-day DTSTART, leaving the event incomplete. It holds the first such
+	// error.
+	Err error
+}
+
+// Write encodes events as a VCALENDAR named name.
+func Write(w io.Writer, name string, events []Event) error {
+	buf := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(dateTimeLayout)
+
+	writeLine(buf, "BEGIN:VCALENDAR")
+	writeLine(buf, "VERSION:2.0")
+	writeLine(buf, "PRODID:-//checkpoint2//appointments//PT")
+	writeLine(buf, "CALSCALE:GREGORIAN")
+	writeLine(buf, "METHOD:PUBLISH")
+	writeLine(buf, "X-WR-CALNAME:"+escapeText(name))
+	for _, event := range events {
+		writeLine(buf, "BEGIN:VEVENT")
+		writeLine(buf, "UID:"+event.UID)
+		writeLine(buf, "DTSTAMP:"+stamp)
+		writeLine(buf, "DTSTART:"+event.Start.UTC().Format(dateTimeLayout))
+		writeLine(buf, "DTEND:"+event.End.UTC().Format(dateTimeLayout))
+		writeLine(buf, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(buf, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Status != "" {
+			writeLine(buf, "STATUS:"+event.Status)
+		}
+		writeLine(buf, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		if !event.LastModified.IsZero() {
+			writeLine(buf, "LAST-MODIFIED:"+event.LastModified.UTC().Format(dateTimeLayout))
+		}
+		writeLine(buf, "END:VEVENT")
+	}
+	writeLine(buf, "END:VCALENDAR")
+
+	return buf.Flush()
+}
+
+// writeLine writes a content line ended by CRLF, folding it so that no line
+// is longer than 75 octets without splitting a UTF-8 sequence.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		fmt.Fprintf(w, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+	fmt.Fprintf(w, "%s\r\n", line)
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func escapeText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// Parse decodes the VEVENTs of an iCalendar stream. Times with a TZID are
+// read in that zone and floating times are left in timezone.Floating, for the
+// caller to place. Components nested in an event, such as its VALARMs, are
+// skipped. An event with a property that can't be read is still returned,
+// with Err set, so that the others can be used; only a malformed stream
+// fails as a whole.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var event *Event
+	var components []string
+	for i, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d: malformed content line", i+1)
+		}
+		switch name {
+		case "BEGIN":
+			component := strings.ToUpper(value)
+			components = append(components, component)
+			if component == "VEVENT" {
+				event = &Event{Properties: map[string]string{}}
+			}
+			continue
+		case "END":
+			component := strings.ToUpper(value)
+			if len(components) == 0 || components[len(components)-1] != component {
+				return nil, fmt.Errorf("line %d: END:%s without BEGIN", i+1, component)
+			}
+			components = components[:len(components)-1]
+			if component == "VEVENT" && event != nil {
+				events = append(events, *event)
+				event = nil
+			}
+			continue
+		}
+		if event == nil || components[len(components)-1] != "VEVENT" {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			event.UID = value
+		case "SUMMARY":
+			event.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			event.Description = unescapeText(value)
+		case "STATUS":
+			event.Status = strings.ToUpper(value)
+		case "DTSTART", "DTEND":
+			t, err := parseDateTime(value, params)
+			if err != nil {
+				if event.Err == nil {
+					event.Err = fmt.Errorf("line %d: %s: %w", i+1, name, err)
+				}
+				continue
+			}
+			if name == "DTSTART" {
+				event.Start = t
+			} else {
+				event.End = t
+			}
+		default:
+			event.Properties[name] = unescapeText(value)
+		}
+	}
+	if len(components) > 0 {
+		return nil, fmt.Errorf("unterminated %s", components[len(components)-1])
+	}
+	return events, nil
+}
+
+// unfold joins the continuation lines, which start with a space or a tab,
+// to the line they belong to.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// splitLine splits "NAME;PARAM=value:VALUE" into its parts. Quoted parameter
+// values may contain colons.
+func splitLine(line string) (string, map[string]string, string, bool) {
+	quoted := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		}
+		if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := map[string]string{}
+	for _, param := range parts[1:] {
+		if key, value, found := strings.Cut(param, "="); found {
+			params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseDateTime reads a DATE-TIME value. All-day DATE values have no time
+// for an appointment to start at and are refused.
+func parseDateTime(value string, params map[string]string) (time.Time, error) {
+	if strings.ToUpper(params["VALUE"]) == "DATE" {
+		return time.Time{}, fmt.Errorf("all-day date %s has no time", value)
+	}
+	tzid := params["TZID"]
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(dateTimeLayout, value)
+	}
+	location := timezone.Floating
+	if tzid != "" {
+		loaded, err := timezone.Load(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %s", tzid)
+		}
+		location = loaded
+	}
+	t, err := time.ParseInLocation(localDateTimeLayout, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date-time %s", value)
+	}
+	return t, nil
+}
+
+func unescapeText(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
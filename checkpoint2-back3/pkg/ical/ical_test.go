@@ -0,0 +1,105 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const exported = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1@example.com\r\n" +
+	"DTSTART:20240304T130000Z\r\n" +
+	"DTEND:20240304T140000Z\r\n" +
+	"SUMMARY:Cleaning\r\n" +
+	"DESCRIPTION:Six-monthly cleaning\r\n" +
+	"X-PATIENT-RG:123456789\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"DESCRIPTION:This is an event reminder\r\n" +
+	"TRIGGER:-P0DT0H30M0S\r\n" +
+	"END:VALARM\r\n" +
+	"STATUS:confirmed\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20240305\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:3@example.com\r\n" +
+	"DTSTART;TZID=America/Sao_Paulo:20240306T090000\r\n" +
+	"DTEND;TZID=America/Sao_Paulo:20240306T093000\r\n" +
+	"SUMMARY:Check-up\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(exported))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Parse() gave %d events, want 3", len(events))
+	}
+
+	first := events[0]
+	if first.Err != nil || first.Description != "Six-monthly cleaning" || first.Status != "CONFIRMED" {
+		t.Errorf("Parse() first event = %+v, want its own description and status", first)
+	}
+	if !first.Start.Equal(time.Date(2024, 3, 4, 13, 0, 0, 0, time.UTC)) || !first.End.Equal(time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("Parse() first event from %v to %v", first.Start, first.End)
+	}
+	for _, name := range []string{"ACTION", "TRIGGER"} {
+		if _, ok := first.Properties[name]; ok {
+			t.Errorf("Parse() kept the alarm's %s on the event", name)
+		}
+	}
+	if first.Properties["X-PATIENT-RG"] != "123456789" {
+		t.Errorf("Parse() X-PATIENT-RG = %q", first.Properties["X-PATIENT-RG"])
+	}
+
+	if events[1].Err == nil || events[1].UID != "2@example.com" {
+		t.Errorf("Parse() all-day event = %+v, want an error", events[1])
+	}
+
+	third := events[2]
+	if third.Err != nil || third.Start.Location().String() != "America/Sao_Paulo" || third.Start.Hour() != 9 {
+		t.Errorf("Parse() third event = %+v, want 09:00 in America/Sao_Paulo", third)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []string{
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nEND:VEVENT\r\n",
+		"BEGIN:VCALENDAR\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nnot a content line\r\nEND:VCALENDAR\r\n",
+	}
+	for _, stream := range tests {
+		if _, err := Parse(strings.NewReader(stream)); err == nil {
+			t.Errorf("Parse(%q) error = nil, want one", stream)
+		}
+	}
+}
+
+func TestWriteRevision(t *testing.T) {
+	var b strings.Builder
+	err := Write(&b, "Agenda", []Event{
+		{UID: "1@example.com", Start: time.Date(2024, 3, 4, 13, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC),
+			Sequence: 2, LastModified: time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("BRT", -3*60*60))},
+		{UID: "2@example.com", Start: time.Date(2024, 3, 5, 13, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, line := range []string{"SEQUENCE:2\r\n", "LAST-MODIFIED:20240301T123000Z\r\n", "SEQUENCE:0\r\n"} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Write() = %q, want a %q line", b.String(), line)
+		}
+	}
+	if strings.Count(b.String(), "LAST-MODIFIED") != 1 {
+		t.Errorf("Write() = %q, want LAST-MODIFIED only on the first event", b.String())
+	}
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+type smsGateway struct {
+	url    string
+	apiKey string
+}
+
+// NewSMSGateway returns a notifier that posts {"to", "message"} as JSON to
+// an SMS gateway, authenticating with apiKey as a bearer token.
+func NewSMSGateway(url string, apiKey string) Notifier {
+	return &smsGateway{url: url, apiKey: apiKey}
+}
+
+func (n *smsGateway) Notify(message Message) error {
+	if message.Phone == "" {
+		return ErrNoAddress
+	}
+	body, err := json.Marshal(map[string]string{
+		"to":      message.Phone,
+		"message": message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if n.apiKey != "" {
+		request.Header.Set("Authorization", "Bearer "+n.apiKey)
+	}
+	return send(request)
+}
+
+type webhook struct {
+	url    string
+	secret string
+}
+
+// NewWebhook returns a notifier that posts the message as JSON to url. With a
+// secret, the body is signed with HMAC-SHA256 in the X-Signature header so
+// that the receiver can check where it came from.
+func NewWebhook(url string, secret string) Notifier {
+	return &webhook{url: url, secret: secret}
+}
+
+func (n *webhook) Notify(message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		request.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return send(request)
+}
+
+func send(request *http.Request) error {
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", request.URL.Host, response.Status)
+	}
+	return nil
+}
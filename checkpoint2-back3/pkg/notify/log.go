@@ -0,0 +1,21 @@
+package notify
+
+import (
+	"io"
+	"log"
+)
+
+type logNotifier struct {
+	logger *log.Logger
+}
+
+// NewLog returns a notifier that only writes the messages to w, such as a
+// file or stdout, for local testing.
+func NewLog(w io.Writer) Notifier {
+	return &logNotifier{logger: log.New(w, "notify: ", log.LstdFlags)}
+}
+
+func (n *logNotifier) Notify(message Message) error {
+	n.logger.Printf("to=%q email=%q phone=%q subject=%q\n%s\n", message.Name, message.Email, message.Phone, message.Subject, message.Body)
+	return nil
+}
@@ -0,0 +1,21 @@
+package notify
+
+import "errors"
+
+// ErrNoAddress is returned when the recipient has no address for the
+// notifier's channel, such as an e-mail for SMTP.
+var ErrNoAddress = errors.New("recipient has no address for this channel")
+
+// Message is a notification to a person. Each channel uses the address it
+// needs and ignores the others.
+type Message struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+type Notifier interface {
+	Notify(message Message) error
+}
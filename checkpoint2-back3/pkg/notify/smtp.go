@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type smtpNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTP returns a notifier that sends e-mails through the SMTP server at
+// addr (host:port). Authentication is skipped when username is empty.
+func NewSMTP(addr string, username string, password string, from string) Notifier {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpNotifier{addr: addr, auth: auth, from: from}
+}
+
+func (n *smtpNotifier) Notify(message Message) error {
+	if message.Email == "" {
+		return ErrNoAddress
+	}
+
+	var mail strings.Builder
+	fmt.Fprintf(&mail, "From: %s\r\n", n.from)
+	fmt.Fprintf(&mail, "To: %s\r\n", message.Email)
+	fmt.Fprintf(&mail, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	mail.WriteString("MIME-Version: 1.0\r\n")
+	mail.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	mail.WriteString("\r\n")
+	mail.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{message.Email}, []byte(mail.String()))
+}
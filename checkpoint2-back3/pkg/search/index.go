@@ -0,0 +1,230 @@
+package search
+
+import (
+	"sort"
+	"sync"
+)
+
+// Index finds short texts such as names by the words they start with,
+// ignoring accents and tolerating typos. It lives in memory, so its owner
+// must Put and Remove texts as they change. Typos are looked up among the
+// distinct words rather than the texts, which share most of their words.
+type Index struct {
+	mu    sync.RWMutex
+	texts map[int][]string
+	ids   map[string][]int
+	grams map[string][]string
+}
+
+// Match is an indexed id and how well its text matched, from 0 to 1.
+type Match struct {
+	Id    int
+	Score float64
+}
+
+func NewIndex() *Index {
+	return &Index{
+		texts: map[int][]string{},
+		ids:   map[string][]int{},
+		grams: map[string][]string{},
+	}
+}
+
+// Put indexes texts under id, replacing whatever id had before.
+func (i *Index) Put(id int, texts ...string) {
+	seen := map[string]bool{}
+	var words []string
+	for _, text := range texts {
+		for _, word := range Words(text) {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+	i.texts[id] = words
+	for _, word := range words {
+		if _, ok := i.ids[word]; !ok {
+			for _, gram := range grams(word, false) {
+				i.grams[gram] = append(i.grams[gram], word)
+			}
+		}
+		i.ids[word] = append(i.ids[word], id)
+	}
+}
+
+func (i *Index) Remove(id int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+}
+
+func (i *Index) remove(id int) {
+	words, ok := i.texts[id]
+	if !ok {
+		return
+	}
+	delete(i.texts, id)
+	for _, word := range words {
+		i.ids[word] = withoutId(i.ids[word], id)
+		if len(i.ids[word]) > 0 {
+			continue
+		}
+		delete(i.ids, word)
+		for _, gram := range grams(word, false) {
+			i.grams[gram] = withoutWord(i.grams[gram], word)
+			if len(i.grams[gram]) == 0 {
+				delete(i.grams, gram)
+			}
+		}
+	}
+}
+
+func withoutId(ids []int, id int) []int {
+	for n, other := range ids {
+		if other == id {
+			return append(ids[:n], ids[n+1:]...)
+		}
+	}
+	return ids
+}
+
+func withoutWord(words []string, word string) []string {
+	for n, other := range words {
+		if other == word {
+			return append(words[:n], words[n+1:]...)
+		}
+	}
+	return words
+}
+
+// Search returns up to limit ids whose words match every word of query,
+// best first. A query word matches a word equal to it, starting with it, or
+// a few typos away from either, ranked in that order. Each id scores the
+// average of its best match for every query word.
+func (i *Index) Search(query string, limit int) []Match {
+	terms := Words(query)
+	if len(terms) == 0 {
+		return []Match{}
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var scores map[int]float64
+	for _, term := range terms {
+		best := map[int]float64{}
+		for word, score := range i.matchWords(term) {
+			for _, id := range i.ids[word] {
+				if _, ok := scores[id]; (scores == nil || ok) && score > best[id] {
+					best[id] = score
+				}
+			}
+		}
+		for id := range best {
+			best[id] += scores[id]
+		}
+		scores = best
+	}
+
+	matches := make([]Match, 0, len(scores))
+	for id, score := range scores {
+		matches = append(matches, Match{Id: id, Score: score / float64(len(terms))})
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].Id < matches[b].Id
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// matchWords scores the indexed words term matches. Only the words sharing
+// enough grams with term are compared, since each typo, a swap of two
+// letters included, spoils at most three grams.
+func (i *Index) matchWords(term string) map[string]float64 {
+	termGrams := grams(term, true)
+	needed := len(termGrams) - 3*typos(term)
+	if needed < 1 {
+		needed = 1
+	}
+
+	shared := map[string]int{}
+	for _, gram := range termGrams {
+		for _, word := range i.grams[gram] {
+			shared[word]++
+		}
+	}
+
+	matched := map[string]float64{}
+	for word, count := range shared {
+		if count < needed {
+			continue
+		}
+		if score := wordScore(term, word); score > 0 {
+			matched[word] = score
+		}
+	}
+	return matched
+}
+
+func wordScore(term string, word string) float64 {
+	t, w := []rune(term), []rune(word)
+	allowed := typos(term)
+	switch {
+	case term == word:
+		return 1
+	case len(w) > len(t) && string(w[:len(t)]) == term:
+		return 0.75 + 0.15*float64(len(t))/float64(len(w))
+	}
+
+	if d := distance(t, w); d <= allowed {
+		return 0.7 - 0.15*float64(d)
+	}
+	best := allowed + 1
+	for n := len(t) - 1; n <= len(t)+1; n++ {
+		if n > 0 && n < len(w) {
+			best = min(best, distance(t, w[:n]))
+		}
+	}
+	if best <= allowed {
+		return 0.6 - 0.15*float64(best)
+	}
+	return 0
+}
+
+// typos is how many typos a term of its length may carry and still match.
+func typos(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	}
+	return 2
+}
+
+// grams splits word into the pairs of letters of "^word$", or of "^word" for
+// a prefix. Pairs rather than triples keep a typo near the start of a short
+// name from hiding it.
+func grams(word string, prefix bool) []string {
+	padded := []rune("^" + word)
+	if !prefix {
+		padded = append(padded, '$')
+	}
+
+	var result []string
+	for n := 2; n <= len(padded); n++ {
+		result = append(result, string(padded[n-2:n]))
+	}
+	return result
+}
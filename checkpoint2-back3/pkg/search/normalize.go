@@ -0,0 +1,78 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// folds maps accented letters to the plain letter they are typed as.
+var folds = map[rune]rune{}
+
+func init() {
+	for plain, accented := range map[rune]string{
+		'a': "àáâãäåā",
+		'c': "çćč",
+		'e': "èéêëē",
+		'i': "ìíîïī",
+		'n': "ñń",
+		'o': "òóôõöøō",
+		'u': "ùúûüū",
+		'y': "ýÿ",
+	} {
+		for _, r := range accented {
+			folds[r] = plain
+		}
+	}
+}
+
+// Words splits text into lower-case words without accents, so that "José"
+// and "jose" read the same. Anything but letters and digits separates words.
+func Words(text string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if plain, ok := folds[r]; ok {
+			r = plain
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			r = ' '
+		}
+		b.WriteRune(r)
+	}
+	return strings.Fields(b.String())
+}
+
+// distance counts the letters to insert, delete, replace or swap with the
+// next one to turn a into b.
+func distance(a []rune, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -2,15 +2,45 @@ package store
 
 import (
 	"checkpoint2/internal/domain"
+	"checkpoint2/pkg/timezone"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 )
 
 type sqlStoreAppointment struct {
 	db *sql.DB
 }
 
+const selectAppointment = `SELECT appointment.id, patient.id, patient.surname, patient.name, patient.rg, patient.registration_date, 
+					dentist.id, dentist.surname, dentist.name, dentist.registration, 
+					appointment.start_time, appointment.end_time, appointment.description, appointment.status, COALESCE(appointment.series_id, 0), 
+					COALESCE(appointment.room_id, 0), COALESCE(appointment.clinic_id, 0), COALESCE(appointment.type_id, 0), 
+					COALESCE(appointment.override_by, ''), COALESCE(appointment.cancellation_reason, ''), 
+					COALESCE(appointment.cancelled_by, ''), appointment.cancelled_at, appointment.late_cancellation, 
+					COALESCE(clinic.time_zone, dentist.time_zone, ''), appointment.sequence, appointment.updated_at 
+					FROM appointment 
+					INNER JOIN patient 
+					ON patient.id = appointment.patient_id 
+					INNER JOIN dentist 
+					ON dentist.id = appointment.dentist_id 
+					LEFT JOIN clinic 
+					ON clinic.id = appointment.clinic_id `
+
+// appointmentSortColumns maps the sort keys accepted by Search to columns.
+var appointmentSortColumns = map[string]string{
+	"start":       "appointment.start_time",
+	"end":         "appointment.end_time",
+	"dentist":     "dentist.name, dentist.surname",
+	"patient":     "patient.name, patient.surname",
+	"description": "appointment.description",
+	"status":      "appointment.status",
+	"id":          "appointment.id",
+}
+
 func NewSQLStoreAppointment(db *sql.DB) AppointmentStoreInterface {
 	return &sqlStoreAppointment{
 		db: db,
@@ -18,59 +48,613 @@ func NewSQLStoreAppointment(db *sql.DB) AppointmentStoreInterface {
 }
 
 func (s *sqlStoreAppointment) ReadById(id int) (domain.Appointment, error) {
-	queryGetById := `SELECT appointment.id, patient.id, patient.surname, patient.name, patient.rg, patient.registration_date, 
-	                dentist.id, dentist.surname, dentist.name, dentist.registration, 
-					appointment.date, appointment.description 
-					FROM appointment 
+	appointments, err := s.readAppointments(selectAppointment+"WHERE appointment.id = ?", id)
+	if err != nil {
+		return domain.Appointment{}, err
+	}
+	if len(appointments) == 0 {
+		return domain.Appointment{}, errors.New("appointment not found")
+	}
+	return appointments[0], nil
+}
+
+func (s *sqlStoreAppointment) ReadByRg(rg string) ([]domain.Appointment, error) {
+	return s.readAppointments(selectAppointment+"WHERE patient.rg = ?", rg)
+}
+
+func (s *sqlStoreAppointment) ReadConflictsByDentist(idDentist int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	queryGetConflicts := selectAppointment + `WHERE appointment.dentist_id = ? 
+					AND appointment.start_time < ? AND appointment.end_time > ? 
+					AND appointment.status <> 'cancelled'`
+
+	return s.readAppointments(queryGetConflicts, idDentist, end, start)
+}
+
+func (s *sqlStoreAppointment) ReadConflictsByPatient(idPatient int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	queryGetConflicts := selectAppointment + `WHERE appointment.patient_id = ? 
+					AND appointment.start_time < ? AND appointment.end_time > ? 
+					AND appointment.status <> 'cancelled'`
+
+	return s.readAppointments(queryGetConflicts, idPatient, end, start)
+}
+
+func (s *sqlStoreAppointment) ReadConflictsByRoom(idRoom int, start time.Time, end time.Time) ([]domain.Appointment, error) {
+	queryGetConflicts := selectAppointment + `WHERE appointment.room_id = ? 
+					AND appointment.start_time < ? AND appointment.end_time > ? 
+					AND appointment.status <> 'cancelled' 
+					ORDER BY appointment.start_time`
+
+	return s.readAppointments(queryGetConflicts, idRoom, end.UTC(), start.UTC())
+}
+
+// ReadConflictingHolds returns the waitlist holds still pending on the
+// dentist's agenda within the period for a patient other than idPatient.
+func (s *sqlStoreAppointment) ReadConflictingHolds(idDentist int, idPatient int, start time.Time, end time.Time) ([]domain.SlotHold, error) {
+	queryGetHolds := `SELECT waitlist_hold.id, waitlist_hold.entry_id, waitlist_hold.dentist_id, COALESCE(waitlist_hold.clinic_id, 0), 
+					waitlist_hold.start_time, waitlist_hold.end_time, waitlist_hold.expires_at, waitlist_hold.status 
+					FROM waitlist_hold 
+					INNER JOIN waitlist_entry 
+					ON waitlist_entry.id = waitlist_hold.entry_id 
+					WHERE waitlist_hold.dentist_id = ? AND waitlist_entry.patient_id <> ? 
+					AND waitlist_hold.start_time < ? AND waitlist_hold.end_time > ? 
+					AND waitlist_hold.status = 'pending' AND waitlist_hold.expires_at > ?`
+
+	rows, err := s.db.Query(queryGetHolds, idDentist, idPatient, end.UTC(), start.UTC(), time.Now().UTC())
+	if err != nil {
+		return []domain.SlotHold{}, err
+	}
+
+	defer rows.Close()
+
+	var holds []domain.SlotHold
+	for rows.Next() {
+		var hold domain.SlotHold
+
+		if err := rows.Scan(
+			&hold.Id,
+			&hold.EntryId,
+			&hold.DentistId,
+			&hold.ClinicId,
+			&hold.Start,
+			&hold.End,
+			&hold.ExpiresAt,
+			&hold.Status,
+		); err != nil {
+			return holds, err
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// UpdateStatus moves the appointment from change.From to change.To, keeping
+// the change in its status history and adding strike, when there is one, to
+// the patient, all in one transaction.
+func (s *sqlStoreAppointment) UpdateStatus(change domain.StatusChange, strike *domain.Strike) error {
+	queryUpdate := `UPDATE appointment SET status = ?, sequence = sequence + 1, updated_at = UTC_TIMESTAMP() 
+					WHERE id = ? AND status = ?`
+	queryInsert := `INSERT INTO appointment_status_history (appointment_id, from_status, to_status, changed_by, changed_at) 
+					VALUES (?, ?, ?, ?, ?)`
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(queryUpdate, change.To, change.AppointmentId, change.From)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("appointment status changed concurrently")
+	}
+
+	if _, err := tx.Exec(
+		queryInsert,
+		change.AppointmentId,
+		change.From,
+		change.To,
+		change.ChangedBy,
+		change.ChangedAt.UTC(),
+	); err != nil {
+		return err
+	}
+
+	if strike != nil {
+		if err := addStrikes(tx, *strike); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Cancel moves the appointment to cancelled as UpdateStatus does, keeping
+// the reason and time of the cancellation.
+func (s *sqlStoreAppointment) Cancel(change domain.StatusChange, cancellation domain.Cancellation, strike *domain.Strike) error {
+	queryUpdate := `UPDATE appointment SET status = ?, cancellation_reason = ?, cancelled_by = ?, cancelled_at = ?, late_cancellation = ?, 
+					sequence = sequence + 1, updated_at = UTC_TIMESTAMP() WHERE id = ? AND status = ?`
+	queryInsert := `INSERT INTO appointment_status_history (appointment_id, from_status, to_status, changed_by, changed_at) 
+					VALUES (?, ?, ?, ?, ?)`
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		queryUpdate,
+		change.To,
+		cancellation.Reason,
+		cancellation.CancelledBy,
+		cancellation.CancelledAt.UTC(),
+		cancellation.Late,
+		change.AppointmentId,
+		change.From)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("appointment status changed concurrently")
+	}
+
+	if _, err := tx.Exec(
+		queryInsert,
+		change.AppointmentId,
+		change.From,
+		change.To,
+		change.ChangedBy,
+		change.ChangedAt.UTC(),
+	); err != nil {
+		return err
+	}
+
+	if strike != nil {
+		if err := addStrikes(tx, *strike); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReadOverdue returns the scheduled and confirmed appointments that ended
+// after after and by before.
+func (s *sqlStoreAppointment) ReadOverdue(after time.Time, before time.Time) ([]domain.Appointment, error) {
+	queryGetOverdue := selectAppointment + `WHERE appointment.status IN ('scheduled', 'confirmed') 
+					AND appointment.end_time > ? AND appointment.end_time <= ? 
+					ORDER BY appointment.end_time`
+
+	return s.readAppointments(queryGetOverdue, after.UTC(), before.UTC())
+}
+
+func (s *sqlStoreAppointment) ReadStatusHistory(id int) ([]domain.StatusChange, error) {
+	queryGetHistory := `SELECT id, appointment_id, from_status, to_status, changed_by, changed_at 
+					FROM appointment_status_history 
+					WHERE appointment_id = ? 
+					ORDER BY changed_at, id`
+
+	var changes []domain.StatusChange
+	rows, err := s.db.Query(queryGetHistory, id)
+	if err != nil {
+		return []domain.StatusChange{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var change domain.StatusChange
+
+		if err := rows.Scan(
+			&change.Id,
+			&change.AppointmentId,
+			&change.From,
+			&change.To,
+			&change.ChangedBy,
+			&change.ChangedAt,
+		); err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+func (s *sqlStoreAppointment) ReadSeries(id int) (domain.AppointmentSeries, error) {
+	queryGetSeries := `SELECT appointment_series.id, patient.id, patient.surname, patient.name, patient.rg, patient.registration_date, 
+					dentist.id, dentist.surname, dentist.name, dentist.registration, 
+					appointment_series.rule, appointment_series.description 
+					FROM appointment_series 
 					INNER JOIN patient 
-					ON patient.id = appointment.patient_id 
+					ON patient.id = appointment_series.patient_id 
 					INNER JOIN dentist 
-					ON dentist.id = appointment.dentist_id 
-					WHERE appointment.id = ?`
+					ON dentist.id = appointment_series.dentist_id 
+					WHERE appointment_series.id = ?`
+	queryGetAppointments := selectAppointment + `WHERE appointment.series_id = ? 
+					ORDER BY appointment.start_time`
 
-	row := s.db.QueryRow(queryGetById, id)
+	row := s.db.QueryRow(queryGetSeries, id)
 
-	appointment := domain.Appointment{}
+	series := domain.AppointmentSeries{}
 
 	err := row.Scan(
-		&appointment.Id,
-		&appointment.Patient.Id,
-		&appointment.Patient.Surname,
-		&appointment.Patient.Name,
-		&appointment.Patient.RG,
-		&appointment.Patient.RegistrationDate,
-		&appointment.Dentist.Id,
-		&appointment.Dentist.Surname,
-		&appointment.Dentist.Name,
-		&appointment.Dentist.Registration,
-		&appointment.Date,
-		&appointment.Description,
+		&series.Id,
+		&series.Patient.Id,
+		&series.Patient.Surname,
+		&series.Patient.Name,
+		&series.Patient.RG,
+		&series.Patient.RegistrationDate,
+		&series.Dentist.Id,
+		&series.Dentist.Surname,
+		&series.Dentist.Name,
+		&series.Dentist.Registration,
+		&series.Rule,
+		&series.Description,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return appointment, errors.New("appointment not found")
+		return series, errors.New("series not found")
 	}
 
 	if err != nil {
-		return appointment, err
+		return series, err
 	}
 
-	return appointment, nil
+	series.Appointments, err = s.readAppointments(queryGetAppointments, id)
+	if err != nil {
+		return series, err
+	}
+
+	return series, nil
 }
 
-func (s *sqlStoreAppointment) ReadByRg(rg string) ([]domain.Appointment, error) {
-	queryGetByRg := `SELECT appointment.id, patient.id, patient.surname, patient.name, patient.rg, patient.registration_date, 
-					dentist.id, dentist.surname, dentist.name, dentist.registration, 
-					appointment.date, appointment.description 
+func (s *sqlStoreAppointment) CreateSeries(series domain.AppointmentSeries) (domain.AppointmentSeries, error) {
+	querySeries := "INSERT INTO appointment_series (patient_id, dentist_id, rule, description) VALUES (?, ?, ?, ?)"
+	queryInsert := "INSERT INTO appointment (patient_id, dentist_id, start_time, end_time, description, series_id, room_id, clinic_id, type_id, override_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		querySeries,
+		series.Patient.Id,
+		series.Dentist.Id,
+		series.Rule,
+		series.Description)
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+
+	for _, appointment := range series.Appointments {
+		if _, err := tx.Exec(
+			queryInsert,
+			series.Patient.Id,
+			series.Dentist.Id,
+			appointment.Start.UTC(),
+			appointment.End.UTC(),
+			appointment.Description,
+			lastId,
+			nullableId(appointment.RoomId),
+			nullableId(appointment.ClinicId),
+			nullableId(appointment.TypeId),
+			nullableString(appointment.OverrideBy)); err != nil {
+			return domain.AppointmentSeries{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.AppointmentSeries{}, err
+	}
+
+	return s.ReadSeries(int(lastId))
+}
+
+// PatchSeries stores the patched occurrences of a series, the series' own
+// row when it has an id, and the history of what the occurrences were, all
+// in one transaction.
+func (s *sqlStoreAppointment) PatchSeries(series domain.AppointmentSeries, history []domain.AppointmentChange) ([]domain.Appointment, error) {
+	querySeries := "UPDATE appointment_series SET patient_id = ?, dentist_id = ?, rule = ?, description = ? WHERE id = ?"
+	queryUpdate := `UPDATE appointment SET patient_id = ?, dentist_id = ?, start_time = ?, end_time = ?, description = ?, room_id = ?, clinic_id = ?, type_id = ?, 
+					sequence = sequence + 1, updated_at = UTC_TIMESTAMP() WHERE id = ?`
+	queryHistory := `INSERT INTO appointment_history (appointment_id, kind, dentist_id, start_time, end_time, description, changed_by, reason, changed_at) 
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return []domain.Appointment{}, err
+	}
+	defer tx.Rollback()
+
+	if series.Id != 0 {
+		if _, err := tx.Exec(
+			querySeries,
+			series.Patient.Id,
+			series.Dentist.Id,
+			series.Rule,
+			series.Description,
+			series.Id); err != nil {
+			return []domain.Appointment{}, err
+		}
+	}
+
+	for _, appointment := range series.Appointments {
+		if _, err := tx.Exec(
+			queryUpdate,
+			appointment.Patient.Id,
+			appointment.Dentist.Id,
+			appointment.Start.UTC(),
+			appointment.End.UTC(),
+			appointment.Description,
+			nullableId(appointment.RoomId),
+			nullableId(appointment.ClinicId),
+			nullableId(appointment.TypeId),
+			appointment.Id); err != nil {
+			return []domain.Appointment{}, err
+		}
+	}
+
+	for _, change := range history {
+		if _, err := tx.Exec(
+			queryHistory,
+			change.AppointmentId,
+			change.Kind,
+			change.DentistId,
+			change.Start.UTC(),
+			change.End.UTC(),
+			change.Description,
+			change.ChangedBy,
+			change.Reason,
+			change.ChangedAt.UTC()); err != nil {
+			return []domain.Appointment{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return []domain.Appointment{}, err
+	}
+
+	appointments := make([]domain.Appointment, 0, len(series.Appointments))
+	for _, appointment := range series.Appointments {
+		patched, err := s.ReadById(appointment.Id)
+		if err != nil {
+			return []domain.Appointment{}, err
+		}
+		appointments = append(appointments, patched)
+	}
+	return appointments, nil
+}
+
+func (s *sqlStoreAppointment) CreateHistory(change domain.AppointmentChange) error {
+	queryInsert := `INSERT INTO appointment_history (appointment_id, kind, dentist_id, start_time, end_time, description, changed_by, reason, changed_at) 
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := s.db.Exec(
+		queryInsert,
+		change.AppointmentId,
+		change.Kind,
+		change.DentistId,
+		change.Start.UTC(),
+		change.End.UTC(),
+		change.Description,
+		change.ChangedBy,
+		change.Reason,
+		change.ChangedAt.UTC(),
+	)
+	return err
+}
+
+func (s *sqlStoreAppointment) ReadHistory(id int) ([]domain.AppointmentChange, error) {
+	queryGetHistory := `SELECT id, appointment_id, kind, dentist_id, start_time, end_time, description, changed_by, reason, changed_at 
+					FROM appointment_history 
+					WHERE appointment_id = ? 
+					ORDER BY changed_at, id`
+
+	var changes []domain.AppointmentChange
+	rows, err := s.db.Query(queryGetHistory, id)
+	if err != nil {
+		return []domain.AppointmentChange{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var change domain.AppointmentChange
+
+		if err := rows.Scan(
+			&change.Id,
+			&change.AppointmentId,
+			&change.Kind,
+			&change.DentistId,
+			&change.Start,
+			&change.End,
+			&change.Description,
+			&change.ChangedBy,
+			&change.Reason,
+			&change.ChangedAt,
+		); err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+func (s *sqlStoreAppointment) ReadByDentistAndPeriod(idDentist int, from time.Time, to time.Time) ([]domain.Appointment, error) {
+	queryGetByPeriod := selectAppointment + `WHERE appointment.dentist_id = ? 
+					AND appointment.start_time < ? AND appointment.end_time > ? 
+					AND appointment.status <> 'cancelled' 
+					ORDER BY appointment.start_time`
+
+	return s.readAppointments(queryGetByPeriod, idDentist, to.UTC(), from.UTC())
+}
+
+func (s *sqlStoreAppointment) Search(filter domain.AppointmentFilter) ([]domain.Appointment, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.DentistId != 0 {
+		conditions = append(conditions, "appointment.dentist_id = ?")
+		args = append(args, filter.DentistId)
+	}
+	if filter.DentistRegistration != "" {
+		conditions = append(conditions, "dentist.registration = ?")
+		args = append(args, filter.DentistRegistration)
+	}
+	if filter.PatientId != 0 {
+		conditions = append(conditions, "appointment.patient_id = ?")
+		args = append(args, filter.PatientId)
+	}
+	if filter.PatientRG != "" {
+		conditions = append(conditions, "patient.rg = ?")
+		args = append(args, filter.PatientRG)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "appointment.end_time > ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "appointment.start_time < ?")
+		args = append(args, filter.To.UTC())
+	}
+	if filter.Text != "" {
+		conditions = append(conditions, "appointment.description LIKE ?")
+		args = append(args, "%"+escapeLike(filter.Text)+"%")
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "appointment.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.ClinicId != 0 {
+		conditions = append(conditions, "appointment.clinic_id = ?")
+		args = append(args, filter.ClinicId)
+	}
+	if filter.TypeId != 0 {
+		conditions = append(conditions, "appointment.type_id = ?")
+		args = append(args, filter.TypeId)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ") + " "
+	}
+
+	queryCount := `SELECT COUNT(*) 
 					FROM appointment 
 					INNER JOIN patient 
 					ON patient.id = appointment.patient_id 
 					INNER JOIN dentist 
-					ON dentist.id = appointment.dentist_id 
-					WHERE patient.rg = ?`
+					ON dentist.id = appointment.dentist_id ` + where
+
+	var total int
+	if err := s.db.QueryRow(queryCount, args...).Scan(&total); err != nil {
+		return []domain.Appointment{}, 0, err
+	}
+
+	querySearch := selectAppointment + where + "ORDER BY " + appointmentOrderBy(filter.Sort) + " LIMIT ? OFFSET ?"
+	appointments, err := s.readAppointments(querySearch, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return []domain.Appointment{}, 0, err
+	}
+
+	return appointments, total, nil
+}
+
+// ReportByClinic counts the appointments starting between from and to by
+// clinic and status. idClinic, when set, limits the count to that clinic.
+func (s *sqlStoreAppointment) ReportByClinic(from time.Time, to time.Time, idClinic int) ([]domain.ClinicReport, error) {
+	queryReport := `SELECT COALESCE(appointment.clinic_id, 0), COALESCE(clinic.name, ''), appointment.status, COUNT(*) 
+					FROM appointment 
+					LEFT JOIN clinic 
+					ON clinic.id = appointment.clinic_id 
+					WHERE appointment.start_time >= ? AND appointment.start_time < ? `
+	args := []interface{}{from.UTC(), to.UTC()}
+	if idClinic != 0 {
+		queryReport += "AND appointment.clinic_id = ? "
+		args = append(args, idClinic)
+	}
+	queryReport += `GROUP BY appointment.clinic_id, clinic.name, appointment.status 
+					ORDER BY appointment.clinic_id, appointment.status`
+
+	rows, err := s.db.Query(queryReport, args...)
+	if err != nil {
+		return []domain.ClinicReport{}, err
+	}
+
+	defer rows.Close()
+
+	reports := []domain.ClinicReport{}
+	for rows.Next() {
+		var clinicId, count int
+		var clinicName, status string
+
+		if err := rows.Scan(&clinicId, &clinicName, &status, &count); err != nil {
+			return reports, err
+		}
+
+		if len(reports) == 0 || reports[len(reports)-1].ClinicId != clinicId {
+			reports = append(reports, domain.ClinicReport{
+				ClinicId:   clinicId,
+				ClinicName: clinicName,
+				ByStatus:   map[string]int{},
+			})
+		}
+		report := &reports[len(reports)-1]
+		report.Total += count
+		report.ByStatus[status] = count
+	}
+	return reports, rows.Err()
+}
+
+// appointmentOrderBy turns a sort key such as "-start" into an ORDER BY
+// clause, always ending with the id so that pages are stable.
+func appointmentOrderBy(sort string) string {
+	direction := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		direction = "DESC"
+		sort = strings.TrimPrefix(sort, "-")
+	}
+	columns, ok := appointmentSortColumns[sort]
+	if !ok {
+		columns = appointmentSortColumns["start"]
+	}
 
+	var orderBy []string
+	for _, column := range strings.Split(columns, ", ") {
+		orderBy = append(orderBy, fmt.Sprintf("%s %s", column, direction))
+	}
+	return strings.Join(append(orderBy, "appointment.id "+direction), ", ")
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (s *sqlStoreAppointment) readAppointments(query string, args ...interface{}) ([]domain.Appointment, error) {
 	var appointments []domain.Appointment
-	rows, err := s.db.Query(queryGetByRg, rg)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return []domain.Appointment{}, err
 	}
@@ -79,6 +663,8 @@ func (s *sqlStoreAppointment) ReadByRg(rg string) ([]domain.Appointment, error)
 
 	for rows.Next() {
 		appointment := domain.Appointment{}
+		var cancellation domain.Cancellation
+		var cancelledAt sql.NullTime
 
 		if err := rows.Scan(
 			&appointment.Id,
@@ -91,19 +677,47 @@ func (s *sqlStoreAppointment) ReadByRg(rg string) ([]domain.Appointment, error)
 			&appointment.Dentist.Surname,
 			&appointment.Dentist.Name,
 			&appointment.Dentist.Registration,
-			&appointment.Date,
+			&appointment.Start,
+			&appointment.End,
 			&appointment.Description,
+			&appointment.Status,
+			&appointment.SeriesId,
+			&appointment.RoomId,
+			&appointment.ClinicId,
+			&appointment.TypeId,
+			&appointment.OverrideBy,
+			&cancellation.Reason,
+			&cancellation.CancelledBy,
+			&cancelledAt,
+			&cancellation.Late,
+			&appointment.TimeZone,
+			&appointment.Sequence,
+			&appointment.UpdatedAt,
 		); err != nil {
 			return appointments, err
 		}
+		if appointment.TimeZone == "" {
+			appointment.TimeZone = timezone.Default
+		}
+		loc, err := timezone.Load(appointment.TimeZone)
+		if err != nil {
+			return appointments, err
+		}
+		appointment.Start = appointment.Start.In(loc)
+		appointment.End = appointment.End.In(loc)
+		appointment.UpdatedAt = appointment.UpdatedAt.In(loc)
+		if cancelledAt.Valid {
+			cancellation.CancelledAt = cancelledAt.Time.In(loc)
+			appointment.Cancellation = &cancellation
+		}
 		appointments = append(appointments, appointment)
 	}
 
-	return appointments, nil
+	return appointments, rows.Err()
 }
 
 func (s *sqlStoreAppointment) CreateById(appointment domain.Appointment, idPatient int, idDentist int) (domain.Appointment, error) {
-	queryInsert := "INSERT INTO appointment (patient_id, dentist_id, date, description) VALUES (?, ?, ?, ?)"
+	queryInsert := "INSERT INTO appointment (patient_id, dentist_id, start_time, end_time, description, room_id, clinic_id, type_id, override_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
 	stmt, err := s.db.Prepare(queryInsert)
 
@@ -115,8 +729,13 @@ func (s *sqlStoreAppointment) CreateById(appointment domain.Appointment, idPatie
 	res, err := stmt.Exec(
 		idPatient,
 		idDentist,
-		appointment.Date,
-		appointment.Description)
+		appointment.Start.UTC(),
+		appointment.End.UTC(),
+		appointment.Description,
+		nullableId(appointment.RoomId),
+		nullableId(appointment.ClinicId),
+		nullableId(appointment.TypeId),
+		nullableString(appointment.OverrideBy))
 	if err != nil {
 		return domain.Appointment{}, err
 	}
@@ -142,10 +761,10 @@ func (s *sqlStoreAppointment) CreateById(appointment domain.Appointment, idPatie
 }
 
 func (s *sqlStoreAppointment) CreateByRgAndRegistration(appointment domain.Appointment, rgPatient string, registrationDentist string) (domain.Appointment, error) {
-	queryInsert := `INSERT INTO appointment (patient_id, dentist_id, date, description)
+	queryInsert := `INSERT INTO appointment (patient_id, dentist_id, start_time, end_time, description, room_id, clinic_id, type_id, override_by)
 					VALUES ((SELECT patient.id FROM patient WHERE patient.rg = ?), 
 					(SELECT dentist.id FROM dentist WHERE dentist.registration = ?), 
-					?, ?)`
+					?, ?, ?, ?, ?, ?, ?)`
 
 	stmt, err := s.db.Prepare(queryInsert)
 
@@ -157,8 +776,13 @@ func (s *sqlStoreAppointment) CreateByRgAndRegistration(appointment domain.Appoi
 	res, err := stmt.Exec(
 		rgPatient,
 		registrationDentist,
-		appointment.Date,
-		appointment.Description)
+		appointment.Start.UTC(),
+		appointment.End.UTC(),
+		appointment.Description,
+		nullableId(appointment.RoomId),
+		nullableId(appointment.ClinicId),
+		nullableId(appointment.TypeId),
+		nullableString(appointment.OverrideBy))
 	if err != nil {
 		return domain.Appointment{}, err
 	}
@@ -184,7 +808,8 @@ func (s *sqlStoreAppointment) CreateByRgAndRegistration(appointment domain.Appoi
 }
 
 func (s *sqlStoreAppointment) Update(id int, a domain.Appointment) (domain.Appointment, error) {
-	queryUpdate  := "UPDATE appointment SET patient_id = ?, dentist_id = ?, date = ?, description = ? WHERE id = ?"
+	queryUpdate := `UPDATE appointment SET patient_id = ?, dentist_id = ?, start_time = ?, end_time = ?, description = ?, room_id = ?, clinic_id = ?, type_id = ?, 
+					sequence = sequence + 1, updated_at = UTC_TIMESTAMP() WHERE id = ?`
 
 	persistedAppointment, err := s.ReadById(id)
 	if err != nil {
@@ -193,15 +818,23 @@ func (s *sqlStoreAppointment) Update(id int, a domain.Appointment) (domain.Appoi
 
 	persistedAppointment.Patient.Id = a.Patient.Id
 	persistedAppointment.Dentist.Id = a.Dentist.Id
-	persistedAppointment.Date = a.Date
+	persistedAppointment.Start = a.Start
+	persistedAppointment.End = a.End
 	persistedAppointment.Description = a.Description
+	persistedAppointment.RoomId = a.RoomId
+	persistedAppointment.ClinicId = a.ClinicId
+	persistedAppointment.TypeId = a.TypeId
 
 	result, err := s.db.Exec(
 		queryUpdate,
 		persistedAppointment.Patient.Id,
 		persistedAppointment.Dentist.Id,
-		persistedAppointment.Date,
+		persistedAppointment.Start.UTC(),
+		persistedAppointment.End.UTC(),
 		persistedAppointment.Description,
+		nullableId(persistedAppointment.RoomId),
+		nullableId(persistedAppointment.ClinicId),
+		nullableId(persistedAppointment.TypeId),
 		id,
 	)
 	if err != nil {
@@ -223,7 +856,8 @@ func (s *sqlStoreAppointment) Update(id int, a domain.Appointment) (domain.Appoi
 }
 
 func (s *sqlStoreAppointment) Patch(id int, a domain.Appointment) (domain.Appointment, error) {
-	queryUpdate  := "UPDATE appointment SET patient_id = ?, dentist_id = ?, date = ?, description = ? WHERE id = ?"
+	queryUpdate := `UPDATE appointment SET patient_id = ?, dentist_id = ?, start_time = ?, end_time = ?, description = ?, room_id = ?, clinic_id = ?, type_id = ?, 
+					sequence = sequence + 1, updated_at = UTC_TIMESTAMP() WHERE id = ?`
 
 	appointment, err := s.ReadById(id)
 	if err != nil {
@@ -236,19 +870,35 @@ func (s *sqlStoreAppointment) Patch(id int, a domain.Appointment) (domain.Appoin
 	if a.Dentist.Id != 0 {
 		appointment.Dentist.Id = a.Dentist.Id
 	}
-	if a.Date != "" {
-		appointment.Date = a.Date
+	if !a.Start.IsZero() {
+		appointment.Start = a.Start
+	}
+	if !a.End.IsZero() {
+		appointment.End = a.End
 	}
 	if a.Description != "" {
 		appointment.Description = a.Description
 	}
+	if a.RoomId != 0 {
+		appointment.RoomId = a.RoomId
+	}
+	if a.ClinicId != 0 {
+		appointment.ClinicId = a.ClinicId
+	}
+	if a.TypeId != 0 {
+		appointment.TypeId = a.TypeId
+	}
 
 	result, err := s.db.Exec(
 		queryUpdate,
 		appointment.Patient.Id,
 		appointment.Dentist.Id,
-		appointment.Date,
+		appointment.Start.UTC(),
+		appointment.End.UTC(),
 		appointment.Description,
+		nullableId(appointment.RoomId),
+		nullableId(appointment.ClinicId),
+		nullableId(appointment.TypeId),
 		id,
 	)
 	if err != nil {
@@ -268,24 +918,3 @@ func (s *sqlStoreAppointment) Patch(id int, a domain.Appointment) (domain.Appoin
 
 	return appointment, nil
 }
-
-func (s *sqlStoreAppointment) Delete(id int) error {
-	queryDelete := "DELETE FROM appointment WHERE id = ?"
-
-	result, err := s.db.Exec(queryDelete, id)
-	if err != nil {
-		return err
-	}
-
-	affectedRows, err := result.RowsAffected()
-
-	if affectedRows == 0 {
-		return errors.New("appointment not found")
-	}
-
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
\ No newline at end of file
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"database/sql"
+	"errors"
+)
+
+type sqlStoreAppointmentType struct {
+	db *sql.DB
+}
+
+func NewSQLStoreAppointmentType(db *sql.DB) AppointmentTypeStoreInterface {
+	return &sqlStoreAppointmentType{
+		db: db,
+	}
+}
+
+const selectAppointmentType = "SELECT id, name, duration_minutes, color, price, equipment FROM appointment_type "
+
+func (s *sqlStoreAppointmentType) ReadById(id int) (domain.AppointmentType, error) {
+	appointmentTypes, err := s.readAppointmentTypes(selectAppointmentType+"WHERE id = ?", id)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+	if len(appointmentTypes) == 0 {
+		return domain.AppointmentType{}, errors.New("appointment type not found")
+	}
+	return appointmentTypes[0], nil
+}
+
+func (s *sqlStoreAppointmentType) ReadAll() ([]domain.AppointmentType, error) {
+	return s.readAppointmentTypes(selectAppointmentType + "ORDER BY name, id")
+}
+
+func (s *sqlStoreAppointmentType) Create(appointmentType domain.AppointmentType) (domain.AppointmentType, error) {
+	queryInsert := "INSERT INTO appointment_type (name, duration_minutes, color, price, equipment) VALUES (?, ?, ?, ?, ?)"
+
+	res, err := s.db.Exec(
+		queryInsert,
+		appointmentType.Name,
+		appointmentType.Duration,
+		appointmentType.Color,
+		appointmentType.Price,
+		joinEquipment(appointmentType.Equipment))
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+
+	return s.ReadById(int(lastId))
+}
+
+func (s *sqlStoreAppointmentType) Update(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error) {
+	queryUpdate := "UPDATE appointment_type SET name = ?, duration_minutes = ?, color = ?, price = ?, equipment = ? WHERE id = ?"
+
+	if _, err := s.ReadById(id); err != nil {
+		return domain.AppointmentType{}, err
+	}
+
+	if _, err := s.db.Exec(
+		queryUpdate,
+		appointmentType.Name,
+		appointmentType.Duration,
+		appointmentType.Color,
+		appointmentType.Price,
+		joinEquipment(appointmentType.Equipment),
+		id); err != nil {
+		return domain.AppointmentType{}, err
+	}
+
+	return s.ReadById(id)
+}
+
+func (s *sqlStoreAppointmentType) Patch(id int, t domain.AppointmentType) (domain.AppointmentType, error) {
+	queryUpdate := "UPDATE appointment_type SET name = ?, duration_minutes = ?, color = ?, price = ?, equipment = ? WHERE id = ?"
+
+	appointmentType, err := s.ReadById(id)
+	if err != nil {
+		return domain.AppointmentType{}, err
+	}
+
+	if t.Name != "" {
+		appointmentType.Name = t.Name
+	}
+	if t.Duration != 0 {
+		appointmentType.Duration = t.Duration
+	}
+	if t.Color != "" {
+		appointmentType.Color = t.Color
+	}
+	if t.Price != 0 {
+		appointmentType.Price = t.Price
+	}
+	if t.Equipment != nil {
+		appointmentType.Equipment = t.Equipment
+	}
+
+	if _, err := s.db.Exec(
+		queryUpdate,
+		appointmentType.Name,
+		appointmentType.Duration,
+		appointmentType.Color,
+		appointmentType.Price,
+		joinEquipment(appointmentType.Equipment),
+		id); err != nil {
+		return domain.AppointmentType{}, err
+	}
+
+	return s.ReadById(id)
+}
+
+func (s *sqlStoreAppointmentType) Delete(id int) error {
+	queryDelete := "DELETE FROM appointment_type WHERE id = ?"
+
+	result, err := s.db.Exec(queryDelete, id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("appointment type not found")
+	}
+
+	return nil
+}
+
+func (s *sqlStoreAppointmentType) readAppointmentTypes(query string, args ...interface{}) ([]domain.AppointmentType, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []domain.AppointmentType{}, err
+	}
+	defer rows.Close()
+
+	appointmentTypes := []domain.AppointmentType{}
+	for rows.Next() {
+		var appointmentType domain.AppointmentType
+		var equipment string
+
+		if err := rows.Scan(
+			&appointmentType.Id,
+			&appointmentType.Name,
+			&appointmentType.Duration,
+			&appointmentType.Color,
+			&appointmentType.Price,
+			&equipment,
+		); err != nil {
+			return appointmentTypes, err
+		}
+
+		appointmentType.Equipment = splitEquipment(equipment)
+		appointmentTypes = append(appointmentTypes, appointmentType)
+	}
+	return appointmentTypes, rows.Err()
+}
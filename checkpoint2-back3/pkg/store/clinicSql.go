@@ -0,0 +1,125 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"database/sql"
+)
+
+type sqlStoreClinic struct {
+	db *sql.DB
+}
+
+func NewSQLStoreClinic(db *sql.DB) ClinicStoreInterface {
+	return &sqlStoreClinic{
+		db: db,
+	}
+}
+
+const selectClinic = "SELECT id, name, address, time_zone FROM clinic "
+
+func (s *sqlStoreClinic) ReadById(id int) (domain.Clinic, error) {
+	clinics, err := s.readClinics(selectClinic+"WHERE id = ?", id)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+	if len(clinics) == 0 {
+		return domain.Clinic{}, &NotFoundError{Entity: "clinic"}
+	}
+	return clinics[0], nil
+}
+
+func (s *sqlStoreClinic) ReadAll() ([]domain.Clinic, error) {
+	return s.readClinics(selectClinic + "ORDER BY name, id")
+}
+
+func (s *sqlStoreClinic) Create(clinic domain.Clinic) (domain.Clinic, error) {
+	queryInsert := "INSERT INTO clinic (name, address, time_zone) VALUES (?, ?, ?)"
+
+	res, err := s.db.Exec(queryInsert, clinic.Name, clinic.Address, clinic.TimeZone)
+	if err != nil {
+		return domain.Clinic{}, conflict(err)
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+
+	return s.ReadById(int(lastId))
+}
+
+func (s *sqlStoreClinic) Update(id int, clinic domain.Clinic) (domain.Clinic, error) {
+	queryUpdate := "UPDATE clinic SET name = ?, address = ?, time_zone = ? WHERE id = ?"
+
+	if _, err := s.ReadById(id); err != nil {
+		return domain.Clinic{}, err
+	}
+
+	if _, err := s.db.Exec(queryUpdate, clinic.Name, clinic.Address, clinic.TimeZone, id); err != nil {
+		return domain.Clinic{}, conflict(err)
+	}
+
+	return s.ReadById(id)
+}
+
+func (s *sqlStoreClinic) Patch(id int, c domain.Clinic) (domain.Clinic, error) {
+	queryUpdate := "UPDATE clinic SET name = ?, address = ?, time_zone = ? WHERE id = ?"
+
+	clinic, err := s.ReadById(id)
+	if err != nil {
+		return domain.Clinic{}, err
+	}
+
+	if c.Name != "" {
+		clinic.Name = c.Name
+	}
+	if c.Address != "" {
+		clinic.Address = c.Address
+	}
+	if c.TimeZone != "" {
+		clinic.TimeZone = c.TimeZone
+	}
+
+	if _, err := s.db.Exec(queryUpdate, clinic.Name, clinic.Address, clinic.TimeZone, id); err != nil {
+		return domain.Clinic{}, conflict(err)
+	}
+
+	return s.ReadById(id)
+}
+
+func (s *sqlStoreClinic) Delete(id int) error {
+	queryDelete := "DELETE FROM clinic WHERE id = ?"
+
+	result, err := s.db.Exec(queryDelete, id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return &NotFoundError{Entity: "clinic"}
+	}
+
+	return nil
+}
+
+func (s *sqlStoreClinic) readClinics(query string, args ...interface{}) ([]domain.Clinic, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []domain.Clinic{}, err
+	}
+	defer rows.Close()
+
+	clinics := []domain.Clinic{}
+	for rows.Next() {
+		var clinic domain.Clinic
+		if err := rows.Scan(&clinic.Id, &clinic.Name, &clinic.Address, &clinic.TimeZone); err != nil {
+			return clinics, err
+		}
+		clinics = append(clinics, clinic)
+	}
+	return clinics, rows.Err()
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type sqlStoreClosure struct {
+	db *sql.DB
+}
+
+func NewSQLStoreClosure(db *sql.DB) ClosureStoreInterface {
+	return &sqlStoreClosure{
+		db: db,
+	}
+}
+
+const selectClosure = "SELECT id, COALESCE(clinic_id, 0), reason, from_date, to_date, recurrence FROM closure "
+
+func (s *sqlStoreClosure) ReadById(id int) (domain.Closure, error) {
+	closures, err := s.readClosures(selectClosure+"WHERE id = ?", id)
+	if err != nil {
+		return domain.Closure{}, err
+	}
+	if len(closures) == 0 {
+		return domain.Closure{}, errors.New("closure not found")
+	}
+	return closures[0], nil
+}
+
+func (s *sqlStoreClosure) ReadAll() ([]domain.Closure, error) {
+	return s.readClosures(selectClosure + "ORDER BY from_date, id")
+}
+
+// ReadBetween returns the closures applying to idClinic that may touch the
+// dates from to to: the one-off closures overlapping them and the yearly
+// closures that started by then, which still have to be expanded.
+func (s *sqlStoreClosure) ReadBetween(from time.Time, to time.Time, idClinic int) ([]domain.Closure, error) {
+	queryGetBetween := selectClosure + `WHERE (clinic_id IS NULL OR clinic_id = ?) 
+					AND from_date <= ? AND (recurrence = ? OR to_date >= ?) 
+					ORDER BY from_date, id`
+
+	return s.readClosures(queryGetBetween, idClinic, to.Format("2006-01-02"), domain.ClosureYearly, from.Format("2006-01-02"))
+}
+
+func (s *sqlStoreClosure) Create(closure domain.Closure) (domain.Closure, error) {
+	queryInsert := "INSERT INTO closure (clinic_id, reason, from_date, to_date, recurrence) VALUES (?, ?, ?, ?, ?)"
+
+	res, err := s.db.Exec(
+		queryInsert,
+		nullableId(closure.ClinicId),
+		closure.Reason,
+		closure.From.Format("2006-01-02"),
+		closure.To.Format("2006-01-02"),
+		closure.Recurrence)
+	if err != nil {
+		return domain.Closure{}, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.Closure{}, err
+	}
+
+	return s.ReadById(int(lastId))
+}
+
+func (s *sqlStoreClosure) Update(id int, closure domain.Closure) (domain.Closure, error) {
+	queryUpdate := "UPDATE closure SET clinic_id = ?, reason = ?, from_date = ?, to_date = ?, recurrence = ? WHERE id = ?"
+
+	if _, err := s.ReadById(id); err != nil {
+		return domain.Closure{}, err
+	}
+
+	if _, err := s.db.Exec(
+		queryUpdate,
+		nullableId(closure.ClinicId),
+		closure.Reason,
+		closure.From.Format("2006-01-02"),
+		closure.To.Format("2006-01-02"),
+		closure.Recurrence,
+		id,
+	); err != nil {
+		return domain.Closure{}, err
+	}
+
+	return s.ReadById(id)
+}
+
+func (s *sqlStoreClosure) Delete(id int) error {
+	queryDelete := "DELETE FROM closure WHERE id = ?"
+
+	result, err := s.db.Exec(queryDelete, id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("closure not found")
+	}
+
+	return nil
+}
+
+func (s *sqlStoreClosure) readClosures(query string, args ...interface{}) ([]domain.Closure, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []domain.Closure{}, err
+	}
+	defer rows.Close()
+
+	closures := []domain.Closure{}
+	for rows.Next() {
+		var closure domain.Closure
+
+		if err := rows.Scan(
+			&closure.Id,
+			&closure.ClinicId,
+			&closure.Reason,
+			&closure.From,
+			&closure.To,
+			&closure.Recurrence,
+		); err != nil {
+			return closures, err
+		}
+		closures = append(closures, closure)
+	}
+	return closures, rows.Err()
+}
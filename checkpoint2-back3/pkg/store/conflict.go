@@ -0,0 +1,50 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// duplicateEntry is MySQL's error number for a write violating a unique index.
+const duplicateEntry = 1062
+
+// uniqueFields names the field behind each unique index a client can run
+// into.
+var uniqueFields = map[string]string{
+	"idx_patient_rg":           "rg",
+	"idx_patient_cpf":          "cpf",
+	"idx_dentist_registration": "registration",
+	"idx_room_name":            "room name",
+	"idx_clinic_name":          "clinic name",
+}
+
+// ConflictError is returned when a write would give a record a value that
+// another record already has in a field that must be unique.
+type ConflictError struct {
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s already exists", e.Field)
+}
+
+// conflict turns MySQL's duplicate-key error into a ConflictError, leaving
+// any other error as it is. The index is read from the end of the message,
+// "Duplicate entry '...' for key '[table.]index'".
+func conflict(err error) error {
+	var mysqlError *mysql.MySQLError
+	if !errors.As(err, &mysqlError) || mysqlError.Number != duplicateEntry {
+		return err
+	}
+
+	index := mysqlError.Message[strings.LastIndex(mysqlError.Message, " ")+1:]
+	index = strings.Trim(index, "'")
+	index = index[strings.LastIndex(index, ".")+1:]
+	if field, ok := uniqueFields[index]; ok {
+		return &ConflictError{Field: field}
+	}
+	return &ConflictError{Field: index}
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"strings"
+	"time"
 )
 
 type sqlStoreDentist struct {
@@ -18,7 +20,7 @@ func NewSQLStoreDentist(db *sql.DB) DentistStoreInterface {
 }
 
 func (s *sqlStoreDentist) ReadById(id int) (domain.Dentist, error) {
-	queryGetById := "SELECT id, surname, name, registration FROM dentist where id = ?"
+	queryGetById := "SELECT id, surname, name, registration, COALESCE(time_zone, '') FROM dentist where id = ?"
 
 	row := s.db.QueryRow(queryGetById, id)
 
@@ -29,24 +31,97 @@ func (s *sqlStoreDentist) ReadById(id int) (domain.Dentist, error) {
 		&dentist.Surname,
 		&dentist.Name,
 		&dentist.Registration,
+		&dentist.TimeZone,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return dentist, errors.New("dentist not found")
+		return dentist, &NotFoundError{Entity: "dentist"}
 	}
 
 	if err != nil {
 		return dentist, err
 	}
 
+	dentist.Schedule, err = s.ReadSchedule(dentist.Id)
+	if err != nil {
+		return dentist, err
+	}
+
+	dentist.ClinicIds, err = s.ReadClinics(dentist.Id)
+	if err != nil {
+		return dentist, err
+	}
+
 	return dentist, nil
 }
 
 func (s *sqlStoreDentist) ReadAll() ([]domain.Dentist, error) {
-	queryGetAll := "SELECT id, surname, name, registration FROM dentist"
+	queryGetAll := "SELECT id, surname, name, registration, COALESCE(time_zone, '') FROM dentist"
+
+	return s.readDentists(queryGetAll)
+}
+
+var dentistSortColumns = map[string]sortColumn{
+	"id":           {column: "dentist.id", param: "?"},
+	"name":         {column: "dentist.name", param: "?"},
+	"surname":      {column: "dentist.surname", param: "?"},
+	"registration": {column: "dentist.registration", param: "?"},
+}
+
+// Search lists the dentists matching filter one page at a time, along with
+// how many match in total.
+func (s *sqlStoreDentist) Search(filter domain.DentistFilter) ([]domain.Dentist, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Name != "" {
+		conditions = append(conditions, "dentist.name LIKE ?")
+		args = append(args, escapeLike(filter.Name)+"%")
+	}
+	if filter.Registration != "" {
+		conditions = append(conditions, "dentist.registration = ?")
+		args = append(args, filter.Registration)
+	}
+	if filter.ClinicId != 0 {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM dentist_clinic 
+					WHERE dentist_clinic.dentist_id = dentist.id AND dentist_clinic.clinic_id = ?)`)
+		args = append(args, filter.ClinicId)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ") + " "
+	}
+
+	queryCount := "SELECT COUNT(*) FROM dentist " + where
+
+	var total int
+	if err := s.db.QueryRow(queryCount, args...).Scan(&total); err != nil {
+		return []domain.Dentist{}, 0, err
+	}
+
+	orderBy, after, afterArgs := keyset(dentistSortColumns, "dentist.id", filter.Sort, filter.After)
+	offset := filter.Offset
+	if after != "" {
+		conditions = append(conditions, after)
+		args = append(args, afterArgs...)
+		where = "WHERE " + strings.Join(conditions, " AND ") + " "
+		offset = 0
+	}
+
+	querySearch := `SELECT id, surname, name, registration, COALESCE(time_zone, '') 
+					FROM dentist ` + where + "ORDER BY " + orderBy + " LIMIT ? OFFSET ?"
+	dentists, err := s.readDentists(querySearch, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return []domain.Dentist{}, 0, err
+	}
+
+	return dentists, total, nil
+}
 
+func (s *sqlStoreDentist) readDentists(query string, args ...interface{}) ([]domain.Dentist, error) {
 	var dentists []domain.Dentist
-	rows, err := s.db.Query(queryGetAll)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return []domain.Dentist{}, err
 	}
@@ -61,17 +136,18 @@ func (s *sqlStoreDentist) ReadAll() ([]domain.Dentist, error) {
 			&dentist.Surname,
 			&dentist.Name,
 			&dentist.Registration,
+			&dentist.TimeZone,
 		); err != nil {
 			return dentists, err
 		}
 
 		dentists = append(dentists, dentist)
 	}
-	return dentists, nil
+	return dentists, rows.Err()
 }
 
 func (s *sqlStoreDentist) ReadByRegistration(registration string) (domain.Dentist, error) {
-	queryGetByRegistration := "SELECT id, surname, name, registration FROM dentist where registration = ?"
+	queryGetByRegistration := "SELECT id, surname, name, registration, COALESCE(time_zone, '') FROM dentist where registration = ?"
 
 	row := s.db.QueryRow(queryGetByRegistration, registration)
 
@@ -82,21 +158,32 @@ func (s *sqlStoreDentist) ReadByRegistration(registration string) (domain.Dentis
 		&dentist.Surname,
 		&dentist.Name,
 		&dentist.Registration,
+		&dentist.TimeZone,
 	)
 
 	if errors.Is(err, sql.ErrNoRows) {
-		return dentist, errors.New("dentist not found")
+		return dentist, &NotFoundError{Entity: "dentist"}
 	}
 
 	if err != nil {
 		return dentist, err
 	}
 
+	dentist.Schedule, err = s.ReadSchedule(dentist.Id)
+	if err != nil {
+		return dentist, err
+	}
+
+	dentist.ClinicIds, err = s.ReadClinics(dentist.Id)
+	if err != nil {
+		return dentist, err
+	}
+
 	return dentist, nil
 }
 
 func (s *sqlStoreDentist) Create(dentist domain.Dentist) (domain.Dentist, error) {
-	queryInsert := "INSERT INTO dentist (surname, name, registration) VALUES (?, ?, ?)"
+	queryInsert := "INSERT INTO dentist (surname, name, registration, time_zone) VALUES (?, ?, ?, ?)"
 
 	stmt, err := s.db.Prepare(queryInsert)
 
@@ -108,9 +195,10 @@ func (s *sqlStoreDentist) Create(dentist domain.Dentist) (domain.Dentist, error)
 	res, err := stmt.Exec(
 		dentist.Surname,
 		dentist.Name,
-		dentist.Registration)
+		dentist.Registration,
+		nullableString(dentist.TimeZone))
 	if err != nil {
-		return domain.Dentist{}, err
+		return domain.Dentist{}, conflict(err)
 	}
 
 	RowsAffected, _ := res.RowsAffected()
@@ -129,26 +217,28 @@ func (s *sqlStoreDentist) Create(dentist domain.Dentist) (domain.Dentist, error)
 }
 
 func (s *sqlStoreDentist) Update(id int, d domain.Dentist) (domain.Dentist, error) {
-	queryUpdate  := "UPDATE dentist SET surname = ?, name = ?, registration = ? WHERE id = ?"
+	queryUpdate := "UPDATE dentist SET surname = ?, name = ?, registration = ?, time_zone = ? WHERE id = ?"
 
 	dentist, err := s.ReadById(id)
 	if err != nil {
-		return domain.Dentist{}, errors.New("dentist not found")
+		return domain.Dentist{}, &NotFoundError{Entity: "dentist"}
 	}
 
 	dentist.Surname = d.Surname
 	dentist.Name = d.Name
 	dentist.Registration = d.Registration
+	dentist.TimeZone = d.TimeZone
 
 	result, err := s.db.Exec(
 		queryUpdate,
 		dentist.Surname,
 		dentist.Name,
 		dentist.Registration,
+		nullableString(dentist.TimeZone),
 		id,
 	)
 	if err != nil {
-		return domain.Dentist{}, err
+		return domain.Dentist{}, conflict(err)
 	}
 
 	affectedRows, err := result.RowsAffected()
@@ -161,11 +251,11 @@ func (s *sqlStoreDentist) Update(id int, d domain.Dentist) (domain.Dentist, erro
 }
 
 func (s *sqlStoreDentist) Patch(id int, d domain.Dentist) (domain.Dentist, error) {
-	queryUpdate  := "UPDATE dentist SET surname = ?, name = ?, registration = ? WHERE id = ?"
+	queryUpdate := "UPDATE dentist SET surname = ?, name = ?, registration = ?, time_zone = ? WHERE id = ?"
 
 	dentist, err := s.ReadById(id)
 	if err != nil {
-		return domain.Dentist{}, errors.New("dentist not found")
+		return domain.Dentist{}, &NotFoundError{Entity: "dentist"}
 	}
 
 	if d.Surname != "" {
@@ -175,20 +265,25 @@ func (s *sqlStoreDentist) Patch(id int, d domain.Dentist) (domain.Dentist, error
 	if d.Name != "" {
 		dentist.Name = d.Name
 	}
-	
+
 	if d.Registration != "" {
 		dentist.Registration = d.Registration
 	}
 
+	if d.TimeZone != "" {
+		dentist.TimeZone = d.TimeZone
+	}
+
 	result, err := s.db.Exec(
 		queryUpdate,
 		dentist.Surname,
 		dentist.Name,
 		dentist.Registration,
+		nullableString(dentist.TimeZone),
 		id,
 	)
 	if err != nil {
-		return domain.Dentist{}, err
+		return domain.Dentist{}, conflict(err)
 	}
 
 	affectedRows, err := result.RowsAffected()
@@ -211,12 +306,266 @@ func (s *sqlStoreDentist) Delete(id int) error {
 	affectedRows, err := result.RowsAffected()
 
 	if affectedRows == 0 {
-		return errors.New("dentist not found")
+		return &NotFoundError{Entity: "dentist"}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *sqlStoreDentist) ReadSchedule(id int) ([]domain.WorkingHours, error) {
+	queryGetSchedule := `SELECT weekday, TIME_FORMAT(start_time, '%H:%i'), TIME_FORMAT(end_time, '%H:%i') 
+					FROM dentist_working_hours 
+					WHERE dentist_id = ? 
+					ORDER BY weekday, start_time`
+
+	var schedule []domain.WorkingHours
+	rows, err := s.db.Query(queryGetSchedule, id)
+	if err != nil {
+		return []domain.WorkingHours{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var workingHours domain.WorkingHours
+
+		if err := rows.Scan(
+			&workingHours.Weekday,
+			&workingHours.Start,
+			&workingHours.End,
+		); err != nil {
+			return schedule, err
+		}
+
+		schedule = append(schedule, workingHours)
+	}
+	return schedule, rows.Err()
+}
+
+func (s *sqlStoreDentist) UpdateSchedule(id int, schedule []domain.WorkingHours) ([]domain.WorkingHours, error) {
+	queryDelete := "DELETE FROM dentist_working_hours WHERE dentist_id = ?"
+	queryInsert := "INSERT INTO dentist_working_hours (dentist_id, weekday, start_time, end_time) VALUES (?, ?, ?, ?)"
+
+	if _, err := s.ReadById(id); err != nil {
+		return []domain.WorkingHours{}, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return []domain.WorkingHours{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryDelete, id); err != nil {
+		return []domain.WorkingHours{}, err
+	}
+
+	for _, workingHours := range schedule {
+		if _, err := tx.Exec(queryInsert, id, workingHours.Weekday, workingHours.Start, workingHours.End); err != nil {
+			return []domain.WorkingHours{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return []domain.WorkingHours{}, err
+	}
+
+	return s.ReadSchedule(id)
+}
+
+func (s *sqlStoreDentist) ReadClinics(id int) ([]int, error) {
+	queryGetClinics := "SELECT clinic_id FROM dentist_clinic WHERE dentist_id = ? ORDER BY clinic_id"
+
+	rows, err := s.db.Query(queryGetClinics, id)
+	if err != nil {
+		return []int{}, err
+	}
+
+	defer rows.Close()
+
+	var clinicIds []int
+	for rows.Next() {
+		var clinicId int
+		if err := rows.Scan(&clinicId); err != nil {
+			return clinicIds, err
+		}
+		clinicIds = append(clinicIds, clinicId)
+	}
+	return clinicIds, rows.Err()
+}
+
+func (s *sqlStoreDentist) UpdateClinics(id int, clinicIds []int) ([]int, error) {
+	queryDelete := "DELETE FROM dentist_clinic WHERE dentist_id = ?"
+	queryInsert := "INSERT INTO dentist_clinic (dentist_id, clinic_id) VALUES (?, ?)"
+
+	if _, err := s.ReadById(id); err != nil {
+		return []int{}, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return []int{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(queryDelete, id); err != nil {
+		return []int{}, err
+	}
+
+	for _, clinicId := range clinicIds {
+		if _, err := tx.Exec(queryInsert, id, clinicId); err != nil {
+			return []int{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return []int{}, err
+	}
+
+	return s.ReadClinics(id)
+}
+
+func (s *sqlStoreDentist) ReadByClinic(idClinic int) ([]domain.Dentist, error) {
+	queryGetByClinic := `SELECT dentist.id, dentist.surname, dentist.name, dentist.registration, COALESCE(dentist.time_zone, '') 
+					FROM dentist 
+					INNER JOIN dentist_clinic 
+					ON dentist_clinic.dentist_id = dentist.id 
+					WHERE dentist_clinic.clinic_id = ? 
+					ORDER BY dentist.name, dentist.surname`
+
+	rows, err := s.db.Query(queryGetByClinic, idClinic)
+	if err != nil {
+		return []domain.Dentist{}, err
+	}
+
+	defer rows.Close()
+
+	dentists := []domain.Dentist{}
+	for rows.Next() {
+		var dentist domain.Dentist
+
+		if err := rows.Scan(
+			&dentist.Id,
+			&dentist.Surname,
+			&dentist.Name,
+			&dentist.Registration,
+			&dentist.TimeZone,
+		); err != nil {
+			return dentists, err
+		}
+
+		dentists = append(dentists, dentist)
+	}
+	return dentists, rows.Err()
+}
+
+func (s *sqlStoreDentist) ReadTimeOff(id int, from time.Time, to time.Time) ([]domain.TimeOff, error) {
+	queryGetTimeOff := `SELECT id, dentist_id, start_time, end_time, reason 
+					FROM dentist_time_off 
+					WHERE dentist_id = ? AND start_time < ? AND end_time > ? 
+					ORDER BY start_time`
+
+	var timeOffs []domain.TimeOff
+	rows, err := s.db.Query(queryGetTimeOff, id, to.UTC(), from.UTC())
+	if err != nil {
+		return []domain.TimeOff{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var timeOff domain.TimeOff
+
+		if err := rows.Scan(
+			&timeOff.Id,
+			&timeOff.DentistId,
+			&timeOff.Start,
+			&timeOff.End,
+			&timeOff.Reason,
+		); err != nil {
+			return timeOffs, err
+		}
+
+		timeOffs = append(timeOffs, timeOff)
+	}
+	return timeOffs, rows.Err()
+}
+
+func (s *sqlStoreDentist) CreateTimeOff(timeOff domain.TimeOff) (domain.TimeOff, error) {
+	queryInsert := "INSERT INTO dentist_time_off (dentist_id, start_time, end_time, reason) VALUES (?, ?, ?, ?)"
+
+	if _, err := s.ReadById(timeOff.DentistId); err != nil {
+		return domain.TimeOff{}, err
+	}
+
+	res, err := s.db.Exec(
+		queryInsert,
+		timeOff.DentistId,
+		timeOff.Start.UTC(),
+		timeOff.End.UTC(),
+		timeOff.Reason)
+	if err != nil {
+		return domain.TimeOff{}, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.TimeOff{}, err
+	}
+
+	timeOff.Id = int(lastId)
+
+	return timeOff, nil
+}
+
+func (s *sqlStoreDentist) DeleteTimeOff(id int, idTimeOff int) error {
+	queryDelete := "DELETE FROM dentist_time_off WHERE id = ? AND dentist_id = ?"
+
+	result, err := s.db.Exec(queryDelete, idTimeOff, id)
+	if err != nil {
+		return err
 	}
 
+	affectedRows, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
+	if affectedRows == 0 {
+		return errors.New("time off not found")
+	}
+
 	return nil
-}
\ No newline at end of file
+}
+
+func (s *sqlStoreDentist) ReadCalendarToken(id int) (string, error) {
+	queryGetToken := "SELECT COALESCE(calendar_token, '') FROM dentist WHERE id = ?"
+
+	var token string
+	err := s.db.QueryRow(queryGetToken, id).Scan(&token)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", &NotFoundError{Entity: "dentist"}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *sqlStoreDentist) UpdateCalendarToken(id int, token string) error {
+	queryUpdate := "UPDATE dentist SET calendar_token = ? WHERE id = ?"
+
+	if _, err := s.ReadById(id); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(queryUpdate, token, id)
+	return err
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"database/sql"
+)
+
+type sqlStoreImport struct {
+	db *sql.DB
+}
+
+func NewSQLStoreImport(db *sql.DB) ImportStoreInterface {
+	return &sqlStoreImport{
+		db: db,
+	}
+}
+
+// Import saves the whole batch in a single transaction: either every
+// patient, dentist and appointment is created or none is. Patients and
+// dentists are inserted first so that appointments can reference them by RG
+// and registration.
+func (s *sqlStoreImport) Import(batch domain.ImportBatch) error {
+	queryPatient := "INSERT INTO patient (surname, name, rg, cpf, registration_date, birth_date) VALUES (?, ?, ?, ?, ?, ?)"
+	queryDentist := "INSERT INTO dentist (surname, name, registration) VALUES (?, ?, ?)"
+	queryAppointment := `INSERT INTO appointment (patient_id, dentist_id, start_time, end_time, description, clinic_id, room_id)
+					VALUES ((SELECT patient.id FROM patient WHERE patient.rg = ?), 
+					(SELECT dentist.id FROM dentist WHERE dentist.registration = ?), 
+					?, ?, ?, ?, ?)`
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, row := range batch.Patients {
+		if _, err := tx.Exec(
+			queryPatient,
+			row.Patient.Surname,
+			row.Patient.Name,
+			row.Patient.RG,
+			nullableString(row.Patient.CPF),
+			row.Patient.RegistrationDate,
+			nullableString(row.Patient.BirthDate)); err != nil {
+			return conflict(err)
+		}
+	}
+
+	for _, row := range batch.Dentists {
+		if _, err := tx.Exec(
+			queryDentist,
+			row.Dentist.Surname,
+			row.Dentist.Name,
+			row.Dentist.Registration); err != nil {
+			return conflict(err)
+		}
+	}
+
+	for _, row := range batch.Appointments {
+		if _, err := tx.Exec(
+			queryAppointment,
+			row.PatientRG,
+			row.DentistRegistration,
+			row.Start.UTC(),
+			row.End.UTC(),
+			row.Description,
+			nullableId(row.ClinicId),
+			nullableId(row.RoomId)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
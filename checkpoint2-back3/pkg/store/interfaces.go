@@ -1,33 +1,144 @@
 package store
 
-import "checkpoint2/internal/domain"
+import (
+	"checkpoint2/internal/domain"
+	"time"
+)
 
 type DentistStoreInterface interface {
 	ReadById(id int) (domain.Dentist, error)
 	ReadAll() ([]domain.Dentist, error)
+	Search(filter domain.DentistFilter) ([]domain.Dentist, int, error)
 	ReadByRegistration(registration string) (domain.Dentist, error)
 	Create(dentist domain.Dentist) (domain.Dentist, error)
 	Update(id int, dentist domain.Dentist) (domain.Dentist, error)
 	Patch(id int, dentist domain.Dentist) (domain.Dentist, error)
 	Delete(id int) error
+	ReadCalendarToken(id int) (string, error)
+	UpdateCalendarToken(id int, token string) error
+	ReadSchedule(id int) ([]domain.WorkingHours, error)
+	UpdateSchedule(id int, schedule []domain.WorkingHours) ([]domain.WorkingHours, error)
+	ReadClinics(id int) ([]int, error)
+	UpdateClinics(id int, clinicIds []int) ([]int, error)
+	ReadByClinic(idClinic int) ([]domain.Dentist, error)
+	ReadTimeOff(id int, from time.Time, to time.Time) ([]domain.TimeOff, error)
+	CreateTimeOff(timeOff domain.TimeOff) (domain.TimeOff, error)
+	DeleteTimeOff(id int, idTimeOff int) error
 }
 
 type PatientStoreInterface interface {
 	ReadById(id int) (domain.Patient, error)
 	ReadAll() ([]domain.Patient, error)
+	Search(filter domain.PatientFilter) ([]domain.Patient, int, error)
 	ReadByRg(rg string) (domain.Patient, error)
+	ReadByCpf(cpf string) (domain.Patient, error)
 	Create(patient domain.Patient) (domain.Patient, error)
 	Update(id int, patient domain.Patient) (domain.Patient, error)
 	Patch(id int, patient domain.Patient) (domain.Patient, error)
 	Delete(id int) error
+	ReadCalendarToken(id int) (string, error)
+	UpdateCalendarToken(id int, token string) error
+	UpdateRestricted(id int, restricted bool) error
+	ReadContacts(id int) (domain.Contacts, error)
+	UpdateContacts(id int, contacts domain.Contacts) (domain.Contacts, error)
+	CreatePhone(id int, phone domain.Phone) (domain.Phone, error)
+	UpdatePhone(id int, phone domain.Phone) (domain.Phone, error)
+	DeletePhone(id int, idPhone int) error
+	ReadGuardians(id int) ([]domain.Guardian, error)
+	ReadWards(id int) ([]int, error)
+	CreateGuardian(id int, guardian domain.Guardian) (domain.Guardian, error)
+	DeleteGuardian(id int, idGuardian int) error
 }
 
 type AppointmentStoreInterface interface {
 	ReadById(id int) (domain.Appointment, error)
 	ReadByRg(rg string) ([]domain.Appointment, error)
+	ReadConflictsByDentist(idDentist int, start time.Time, end time.Time) ([]domain.Appointment, error)
+	ReadConflictsByPatient(idPatient int, start time.Time, end time.Time) ([]domain.Appointment, error)
+	ReadConflictsByRoom(idRoom int, start time.Time, end time.Time) ([]domain.Appointment, error)
+	ReadConflictingHolds(idDentist int, idPatient int, start time.Time, end time.Time) ([]domain.SlotHold, error)
 	CreateById(appointment domain.Appointment, idPatient int, idDentist int) (domain.Appointment, error)
 	CreateByRgAndRegistration(appointment domain.Appointment, rgPatient string, registrationDentist string) (domain.Appointment, error)
 	Update(id int, appointment domain.Appointment) (domain.Appointment, error)
 	Patch(id int, appointment domain.Appointment) (domain.Appointment, error)
+	UpdateStatus(change domain.StatusChange, strike *domain.Strike) error
+	Cancel(change domain.StatusChange, cancellation domain.Cancellation, strike *domain.Strike) error
+	ReadOverdue(after time.Time, before time.Time) ([]domain.Appointment, error)
+	ReadStatusHistory(id int) ([]domain.StatusChange, error)
+	ReadSeries(id int) (domain.AppointmentSeries, error)
+	CreateSeries(series domain.AppointmentSeries) (domain.AppointmentSeries, error)
+	PatchSeries(series domain.AppointmentSeries, history []domain.AppointmentChange) ([]domain.Appointment, error)
+	CreateHistory(change domain.AppointmentChange) error
+	ReadHistory(id int) ([]domain.AppointmentChange, error)
+	Search(filter domain.AppointmentFilter) ([]domain.Appointment, int, error)
+	ReadByDentistAndPeriod(idDentist int, from time.Time, to time.Time) ([]domain.Appointment, error)
+	ReportByClinic(from time.Time, to time.Time, idClinic int) ([]domain.ClinicReport, error)
+}
+
+type WaitlistStoreInterface interface {
+	ReadById(id int) (domain.WaitlistEntry, error)
+	ReadAll(idDentist int, idClinic int) ([]domain.WaitlistEntry, error)
+	ReadCandidates(idDentist int, idClinic int, start time.Time, end time.Time) ([]domain.WaitlistEntry, error)
+	Create(entry domain.WaitlistEntry) (domain.WaitlistEntry, error)
+	UpdateStatus(id int, status string) error
+	ReadHold(id int) (domain.SlotHold, error)
+	ReadHoldsBySlot(idDentist int, start time.Time) ([]domain.SlotHold, error)
+	ReadExpiredHolds(now time.Time) ([]domain.SlotHold, error)
+	Offer(hold domain.SlotHold) (domain.SlotHold, error)
+	UpdateHoldStatus(id int, status string) error
+	Claim(hold domain.SlotHold, appointment domain.Appointment) (int, error)
+}
+
+type ImportStoreInterface interface {
+	Import(batch domain.ImportBatch) error
+}
+
+type ReminderStoreInterface interface {
+	Claim(reminder domain.Reminder) (domain.Reminder, bool, error)
+	Reclaim(reminder domain.Reminder, claimedBefore time.Time) (domain.Reminder, bool, error)
+	UpdateStatus(id int, status string, sendError string, sentAt *time.Time) error
+	ReadByToken(token string) (domain.Reminder, error)
+	ReadByAppointment(idAppointment int) ([]domain.Reminder, error)
+}
+
+type RoomStoreInterface interface {
+	ReadById(id int) (domain.Room, error)
+	ReadAll() ([]domain.Room, error)
+	ReadByClinic(idClinic int) ([]domain.Room, error)
+	Create(room domain.Room) (domain.Room, error)
+	Update(id int, room domain.Room) (domain.Room, error)
+	Patch(id int, room domain.Room) (domain.Room, error)
+	Delete(id int) error
+}
+
+type ClinicStoreInterface interface {
+	ReadById(id int) (domain.Clinic, error)
+	ReadAll() ([]domain.Clinic, error)
+	Create(clinic domain.Clinic) (domain.Clinic, error)
+	Update(id int, clinic domain.Clinic) (domain.Clinic, error)
+	Patch(id int, clinic domain.Clinic) (domain.Clinic, error)
+	Delete(id int) error
+}
+
+type ClosureStoreInterface interface {
+	ReadById(id int) (domain.Closure, error)
+	ReadAll() ([]domain.Closure, error)
+	ReadBetween(from time.Time, to time.Time, idClinic int) ([]domain.Closure, error)
+	Create(closure domain.Closure) (domain.Closure, error)
+	Update(id int, closure domain.Closure) (domain.Closure, error)
+	Delete(id int) error
+}
+
+type AppointmentTypeStoreInterface interface {
+	ReadById(id int) (domain.AppointmentType, error)
+	ReadAll() ([]domain.AppointmentType, error)
+	Create(appointmentType domain.AppointmentType) (domain.AppointmentType, error)
+	Update(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error)
+	Patch(id int, appointmentType domain.AppointmentType) (domain.AppointmentType, error)
 	Delete(id int) error
-}
\ No newline at end of file
+}
+
+type OdontogramStoreInterface interface {
+	ReadChanges(idPatient int, tooth int, until time.Time) ([]domain.ToothChange, error)
+	CreateChanges(changes []domain.ToothChange) ([]domain.ToothChange, error)
+}
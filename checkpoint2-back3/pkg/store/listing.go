@@ -0,0 +1,45 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"fmt"
+	"strings"
+)
+
+// sortColumn is a column a listing can be ordered by. param is the SQL that
+// turns a cursor value into something comparable with column.
+type sortColumn struct {
+	column string
+	param  string
+}
+
+// keyset orders a listing by sort, a key of columns optionally prefixed with
+// "-" for descending order, breaking ties by idColumn. Unknown keys fall back
+// to the id. When after is set it also returns the condition, and its
+// arguments, that skip the rows up to and including after.
+func keyset(columns map[string]sortColumn, idColumn string, sort string, after domain.Cursor) (string, string, []interface{}) {
+	direction, operator := "ASC", ">"
+	if strings.HasPrefix(sort, "-") {
+		direction, operator = "DESC", "<"
+		sort = strings.TrimPrefix(sort, "-")
+	}
+	column, ok := columns[sort]
+	if !ok {
+		column = sortColumn{column: idColumn, param: "?"}
+	}
+
+	if column.column == idColumn {
+		if after.Id == 0 {
+			return idColumn + " " + direction, "", nil
+		}
+		return idColumn + " " + direction, fmt.Sprintf("%s %s ?", idColumn, operator), []interface{}{after.Id}
+	}
+
+	orderBy := fmt.Sprintf("%s %s, %s %s", column.column, direction, idColumn, direction)
+	if after.Id == 0 {
+		return orderBy, "", nil
+	}
+
+	condition := fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND %[4]s %[2]s ?))", column.column, operator, column.param, idColumn)
+	return orderBy, condition, []interface{}{after.Value, after.Value, after.Id}
+}
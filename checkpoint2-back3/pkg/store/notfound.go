@@ -0,0 +1,10 @@
+package store
+
+// NotFoundError is returned when a record looked up by its id doesn't exist.
+type NotFoundError struct {
+	Entity string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Entity + " not found"
+}
@@ -0,0 +1,16 @@
+package store
+
+// nullableId maps the zero id used for "none" in the domain to SQL NULL.
+func nullableId(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"database/sql"
+	"time"
+)
+
+type sqlStoreOdontogram struct {
+	db *sql.DB
+}
+
+func NewSQLStoreOdontogram(db *sql.DB) OdontogramStoreInterface {
+	return &sqlStoreOdontogram{
+		db: db,
+	}
+}
+
+// ReadChanges lists the changes made to the patient's teeth in the order they
+// were made. A non-zero tooth keeps only the changes to that tooth, and a
+// non-zero until leaves out the appointments starting after it.
+func (s *sqlStoreOdontogram) ReadChanges(idPatient int, tooth int, until time.Time) ([]domain.ToothChange, error) {
+	queryGetChanges := `SELECT c.id, c.patient_id, c.appointment_id, c.tooth, COALESCE(c.surface, ''), c.tooth_condition,
+						COALESCE(c.notes, ''), a.start_time
+						FROM odontogram_change c
+						INNER JOIN appointment a ON a.id = c.appointment_id
+						WHERE c.patient_id = ? `
+	args := []interface{}{idPatient}
+	if tooth != 0 {
+		queryGetChanges += "AND c.tooth = ? "
+		args = append(args, tooth)
+	}
+	if !until.IsZero() {
+		queryGetChanges += "AND a.start_time <= ? "
+		args = append(args, until.UTC())
+	}
+	queryGetChanges += "ORDER BY a.start_time, c.id"
+
+	changes := []domain.ToothChange{}
+	rows, err := s.db.Query(queryGetChanges, args...)
+	if err != nil {
+		return []domain.ToothChange{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var change domain.ToothChange
+
+		if err := rows.Scan(
+			&change.Id,
+			&change.PatientId,
+			&change.AppointmentId,
+			&change.Tooth,
+			&change.Surface,
+			&change.Condition,
+			&change.Notes,
+			&change.MadeAt,
+		); err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// CreateChanges records changes in a single transaction: either all of them
+// are saved or none is.
+func (s *sqlStoreOdontogram) CreateChanges(changes []domain.ToothChange) ([]domain.ToothChange, error) {
+	queryInsert := "INSERT INTO odontogram_change (patient_id, appointment_id, tooth, surface, tooth_condition, notes) VALUES (?, ?, ?, ?, ?, ?)"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return []domain.ToothChange{}, err
+	}
+	defer tx.Rollback()
+
+	created := make([]domain.ToothChange, 0, len(changes))
+	for _, change := range changes {
+		res, err := tx.Exec(
+			queryInsert,
+			change.PatientId,
+			change.AppointmentId,
+			change.Tooth,
+			nullableString(change.Surface),
+			change.Condition,
+			nullableString(change.Notes),
+		)
+		if err != nil {
+			return []domain.ToothChange{}, err
+		}
+
+		lastId, err := res.LastInsertId()
+		if err != nil {
+			return []domain.ToothChange{}, err
+		}
+		change.Id = int(lastId)
+		created = append(created, change)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return []domain.ToothChange{}, err
+	}
+	return created, nil
+}
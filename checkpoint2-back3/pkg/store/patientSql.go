@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"strings"
+	"time"
 )
 
 type sqlStorePatient struct {
@@ -18,19 +20,26 @@ func NewSQLStorePatient(db *sql.DB) PatientStoreInterface {
 }
 
 func (s *sqlStorePatient) ReadById(id int) (domain.Patient, error) {
-	queryGetById := "SELECT id, surname, name, rg, registration_date FROM patient where id = ?"
+	queryGetById := "SELECT id, surname, name, rg, COALESCE(cpf, ''), registration_date, late_cancellations, no_shows, restricted, birth_date FROM patient where id = ?"
 
 	row := s.db.QueryRow(queryGetById, id)
 
 	patient := domain.Patient{}
+	var birthDate sql.NullTime
 
 	err := row.Scan(
 		&patient.Id,
 		&patient.Surname,
 		&patient.Name,
 		&patient.RG,
+		&patient.CPF,
 		&patient.RegistrationDate,
+		&patient.LateCancellations,
+		&patient.NoShows,
+		&patient.Restricted,
+		&birthDate,
 	)
+	setBirthDate(&patient, birthDate)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return patient, errors.New("patient not found")
@@ -40,15 +49,84 @@ func (s *sqlStorePatient) ReadById(id int) (domain.Patient, error) {
 		return patient, err
 	}
 
+	contacts, err := s.ReadContacts(patient.Id)
+	if err != nil {
+		return patient, err
+	}
+	patient.Contacts = &contacts
+
+	guardians, err := s.ReadGuardians(patient.Id)
+	if err != nil {
+		return patient, err
+	}
+	patient.Guardians = guardians
+
 	return patient, nil
 }
 
 func (s *sqlStorePatient) ReadAll() ([]domain.Patient, error) {
 
-	queryGetAll := "SELECT id, surname, name, rg, registration_date FROM patient"
+	queryGetAll := "SELECT id, surname, name, rg, COALESCE(cpf, ''), registration_date, late_cancellations, no_shows, restricted, birth_date FROM patient"
+
+	return s.readPatients(queryGetAll)
+}
+
+var patientSortColumns = map[string]sortColumn{
+	"id":                {column: "patient.id", param: "?"},
+	"name":              {column: "patient.name", param: "?"},
+	"surname":           {column: "patient.surname", param: "?"},
+	"registration_date": {column: "STR_TO_DATE(patient.registration_date, '%d/%m/%Y')", param: "STR_TO_DATE(?, '%d/%m/%Y')"},
+}
+
+// Search lists the patients matching filter one page at a time, along with
+// how many match in total. Registration dates are ordered as dd/mm/yyyy.
+func (s *sqlStorePatient) Search(filter domain.PatientFilter) ([]domain.Patient, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Name != "" {
+		conditions = append(conditions, "patient.name LIKE ?")
+		args = append(args, escapeLike(filter.Name)+"%")
+	}
+	if filter.RG != "" {
+		conditions = append(conditions, "patient.rg = ?")
+		args = append(args, filter.RG)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ") + " "
+	}
+
+	queryCount := "SELECT COUNT(*) FROM patient " + where
+
+	var total int
+	if err := s.db.QueryRow(queryCount, args...).Scan(&total); err != nil {
+		return []domain.Patient{}, 0, err
+	}
+
+	orderBy, after, afterArgs := keyset(patientSortColumns, "patient.id", filter.Sort, filter.After)
+	offset := filter.Offset
+	if after != "" {
+		conditions = append(conditions, after)
+		args = append(args, afterArgs...)
+		where = "WHERE " + strings.Join(conditions, " AND ") + " "
+		offset = 0
+	}
 
+	querySearch := `SELECT id, surname, name, rg, COALESCE(cpf, ''), registration_date, late_cancellations, no_shows, restricted, birth_date 
+					FROM patient ` + where + "ORDER BY " + orderBy + " LIMIT ? OFFSET ?"
+	patients, err := s.readPatients(querySearch, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return []domain.Patient{}, 0, err
+	}
+
+	return patients, total, nil
+}
+
+func (s *sqlStorePatient) readPatients(query string, args ...interface{}) ([]domain.Patient, error) {
 	var patients []domain.Patient
-	rows, err := s.db.Query(queryGetAll)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return []domain.Patient{}, err
 	}
@@ -57,36 +135,50 @@ func (s *sqlStorePatient) ReadAll() ([]domain.Patient, error) {
 
 	for rows.Next() {
 		var patient domain.Patient
+		var birthDate sql.NullTime
 
 		if err := rows.Scan(
 			&patient.Id,
 			&patient.Surname,
 			&patient.Name,
 			&patient.RG,
+			&patient.CPF,
 			&patient.RegistrationDate,
+			&patient.LateCancellations,
+			&patient.NoShows,
+			&patient.Restricted,
+			&birthDate,
 		); err != nil {
 			return patients, err
 		}
+		setBirthDate(&patient, birthDate)
 
 		patients = append(patients, patient)
 	}
-	return patients, nil
+	return patients, rows.Err()
 }
 
 func (s *sqlStorePatient) ReadByRg(rg string) (domain.Patient, error) {
-	queryGetByRg := "SELECT id, surname, name, rg, registration_date FROM patient where rg = ?"
+	queryGetByRg := "SELECT id, surname, name, rg, COALESCE(cpf, ''), registration_date, late_cancellations, no_shows, restricted, birth_date FROM patient where rg = ?"
 
 	row := s.db.QueryRow(queryGetByRg, rg)
 
 	patient := domain.Patient{}
+	var birthDate sql.NullTime
 
 	err := row.Scan(
 		&patient.Id,
 		&patient.Surname,
 		&patient.Name,
 		&patient.RG,
+		&patient.CPF,
 		&patient.RegistrationDate,
+		&patient.LateCancellations,
+		&patient.NoShows,
+		&patient.Restricted,
+		&birthDate,
 	)
+	setBirthDate(&patient, birthDate)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return patient, errors.New("patient not found")
@@ -99,8 +191,22 @@ func (s *sqlStorePatient) ReadByRg(rg string) (domain.Patient, error) {
 	return patient, nil
 }
 
+func (s *sqlStorePatient) ReadByCpf(cpf string) (domain.Patient, error) {
+	queryGetByCpf := "SELECT id, surname, name, rg, COALESCE(cpf, ''), registration_date, late_cancellations, no_shows, restricted, birth_date FROM patient where cpf = ?"
+
+	patients, err := s.readPatients(queryGetByCpf, cpf)
+	if err != nil {
+		return domain.Patient{}, err
+	}
+	if len(patients) == 0 {
+		return domain.Patient{}, errors.New("patient not found")
+	}
+
+	return patients[0], nil
+}
+
 func (s *sqlStorePatient) Create(patient domain.Patient) (domain.Patient, error) {
-	queryInsert := "INSERT INTO patient (surname, name, rg, registration_date) VALUES (?, ?, ?, ?)"
+	queryInsert := "INSERT INTO patient (surname, name, rg, cpf, registration_date, birth_date) VALUES (?, ?, ?, ?, ?, ?)"
 
 	stmt, err := s.db.Prepare(queryInsert)
 
@@ -113,9 +219,11 @@ func (s *sqlStorePatient) Create(patient domain.Patient) (domain.Patient, error)
 		patient.Name,
 		patient.Surname,
 		patient.RG,
-		patient.RegistrationDate)
+		nullableString(patient.CPF),
+		patient.RegistrationDate,
+		nullableString(patient.BirthDate))
 	if err != nil {
-		return domain.Patient{}, err
+		return domain.Patient{}, conflict(err)
 	}
 
 	RowsAffected, _ := res.RowsAffected()
@@ -129,12 +237,13 @@ func (s *sqlStorePatient) Create(patient domain.Patient) (domain.Patient, error)
 	}
 
 	patient.Id = int(lastId)
+	setAge(&patient)
 
 	return patient, nil
 }
 
 func (s *sqlStorePatient) Update(id int, patient domain.Patient) (domain.Patient, error) {
-	queryUpdate  := "UPDATE patient SET surname = ?, name = ?, rg = ?, registration_date = ? WHERE id = ?"
+	queryUpdate := "UPDATE patient SET surname = ?, name = ?, rg = ?, cpf = ?, registration_date = ?, birth_date = ? WHERE id = ?"
 
 	persistedPatient, err := s.ReadById(id)
 	if err != nil {
@@ -144,18 +253,23 @@ func (s *sqlStorePatient) Update(id int, patient domain.Patient) (domain.Patient
 	persistedPatient.Surname = patient.Surname
 	persistedPatient.Name = patient.Name
 	persistedPatient.RG = patient.RG
+	persistedPatient.CPF = patient.CPF
 	persistedPatient.RegistrationDate = patient.RegistrationDate
+	persistedPatient.BirthDate = patient.BirthDate
+	setAge(&persistedPatient)
 
 	result, err := s.db.Exec(
 		queryUpdate,
 		persistedPatient.Surname,
 		persistedPatient.Name,
 		persistedPatient.RG,
+		nullableString(persistedPatient.CPF),
 		persistedPatient.RegistrationDate,
+		nullableString(persistedPatient.BirthDate),
 		id,
 	)
 	if err != nil {
-		return domain.Patient{}, err
+		return domain.Patient{}, conflict(err)
 	}
 
 	affectedRows, err := result.RowsAffected()
@@ -168,7 +282,7 @@ func (s *sqlStorePatient) Update(id int, patient domain.Patient) (domain.Patient
 }
 
 func (s *sqlStorePatient) Patch(id int, patient domain.Patient) (domain.Patient, error) {
-	queryUpdate  := "UPDATE patient SET surname = ?, name = ?, rg = ?, registration_date = ? WHERE id = ?"
+	queryUpdate := "UPDATE patient SET surname = ?, name = ?, rg = ?, cpf = ?, registration_date = ?, birth_date = ? WHERE id = ?"
 
 	persistedPatient, err := s.ReadById(id)
 	if err != nil {
@@ -184,20 +298,29 @@ func (s *sqlStorePatient) Patch(id int, patient domain.Patient) (domain.Patient,
 	if patient.RG != "" {
 		persistedPatient.RG = patient.RG
 	}
+	if patient.CPF != "" {
+		persistedPatient.CPF = patient.CPF
+	}
 	if patient.RegistrationDate != "" {
 		persistedPatient.RegistrationDate = patient.RegistrationDate
 	}
+	if patient.BirthDate != "" {
+		persistedPatient.BirthDate = patient.BirthDate
+		setAge(&persistedPatient)
+	}
 
 	result, err := s.db.Exec(
 		queryUpdate,
 		persistedPatient.Surname,
 		persistedPatient.Name,
 		persistedPatient.RG,
+		nullableString(persistedPatient.CPF),
 		persistedPatient.RegistrationDate,
+		nullableString(persistedPatient.BirthDate),
 		id,
 	)
 	if err != nil {
-		return domain.Patient{}, err
+		return domain.Patient{}, conflict(err)
 	}
 
 	affectedRows, err := result.RowsAffected()
@@ -228,4 +351,401 @@ func (s *sqlStorePatient) Delete(id int) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (s *sqlStorePatient) ReadCalendarToken(id int) (string, error) {
+	queryGetToken := "SELECT COALESCE(calendar_token, '') FROM patient WHERE id = ?"
+
+	var token string
+	err := s.db.QueryRow(queryGetToken, id).Scan(&token)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errors.New("patient not found")
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *sqlStorePatient) UpdateCalendarToken(id int, token string) error {
+	queryUpdate := "UPDATE patient SET calendar_token = ? WHERE id = ?"
+
+	if _, err := s.ReadById(id); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(queryUpdate, token, id)
+	return err
+}
+
+// addStrikes adds strike to the patient's late cancellation and no-show
+// counters within tx, restricting the patient once their sum reaches the
+// strike's threshold.
+func addStrikes(tx *sql.Tx, strike domain.Strike) error {
+	queryUpdate := `UPDATE patient SET late_cancellations = late_cancellations + ?, no_shows = no_shows + ?, 
+					restricted = restricted OR (? > 0 AND late_cancellations + no_shows >= ?) 
+					WHERE id = ?`
+
+	result, err := tx.Exec(queryUpdate, strike.LateCancellations, strike.NoShows, strike.Threshold, strike.Threshold, strike.PatientId)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("patient not found")
+	}
+
+	return nil
+}
+
+func (s *sqlStorePatient) UpdateRestricted(id int, restricted bool) error {
+	queryUpdate := "UPDATE patient SET restricted = ? WHERE id = ?"
+
+	if _, err := s.ReadById(id); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(queryUpdate, restricted, id)
+	return err
+}
+
+func (s *sqlStorePatient) ReadContacts(id int) (domain.Contacts, error) {
+	queryGetEmail := "SELECT email FROM patient_email WHERE patient_id = ?"
+	queryGetAddress := `SELECT street, number, COALESCE(complement, ''), district, city, state, cep 
+					FROM patient_address WHERE patient_id = ?`
+	queryGetEmergency := `SELECT name, COALESCE(relationship, ''), phone 
+					FROM patient_emergency_contact WHERE patient_id = ?`
+
+	contacts := domain.Contacts{Phones: []domain.Phone{}}
+
+	err := s.db.QueryRow(queryGetEmail, id).Scan(&contacts.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.Contacts{}, err
+	}
+
+	var address domain.Address
+	err = s.db.QueryRow(queryGetAddress, id).Scan(
+		&address.Street,
+		&address.Number,
+		&address.Complement,
+		&address.District,
+		&address.City,
+		&address.State,
+		&address.CEP,
+	)
+	switch {
+	case err == nil:
+		contacts.Address = &address
+	case !errors.Is(err, sql.ErrNoRows):
+		return domain.Contacts{}, err
+	}
+
+	var emergency domain.EmergencyContact
+	err = s.db.QueryRow(queryGetEmergency, id).Scan(
+		&emergency.Name,
+		&emergency.Relationship,
+		&emergency.Phone,
+	)
+	switch {
+	case err == nil:
+		contacts.EmergencyContact = &emergency
+	case !errors.Is(err, sql.ErrNoRows):
+		return domain.Contacts{}, err
+	}
+
+	contacts.Phones, err = s.readPhones("SELECT id, type, number FROM patient_phone WHERE patient_id = ? ORDER BY id", id)
+	if err != nil {
+		return domain.Contacts{}, err
+	}
+
+	return contacts, nil
+}
+
+// UpdateContacts replaces every contact of the patient with contacts in a
+// single transaction. Phones get new ids.
+func (s *sqlStorePatient) UpdateContacts(id int, contacts domain.Contacts) (domain.Contacts, error) {
+	queriesDelete := []string{
+		"DELETE FROM patient_email WHERE patient_id = ?",
+		"DELETE FROM patient_phone WHERE patient_id = ?",
+		"DELETE FROM patient_address WHERE patient_id = ?",
+		"DELETE FROM patient_emergency_contact WHERE patient_id = ?",
+	}
+	queryInsertEmail := "INSERT INTO patient_email (patient_id, email) VALUES (?, ?)"
+	queryInsertPhone := "INSERT INTO patient_phone (patient_id, type, number) VALUES (?, ?, ?)"
+	queryInsertAddress := `INSERT INTO patient_address (patient_id, street, number, complement, district, city, state, cep) 
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	queryInsertEmergency := "INSERT INTO patient_emergency_contact (patient_id, name, relationship, phone) VALUES (?, ?, ?, ?)"
+
+	if _, err := s.ReadById(id); err != nil {
+		return domain.Contacts{}, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return domain.Contacts{}, err
+	}
+	defer tx.Rollback()
+
+	for _, queryDelete := range queriesDelete {
+		if _, err := tx.Exec(queryDelete, id); err != nil {
+			return domain.Contacts{}, err
+		}
+	}
+
+	if contacts.Email != "" {
+		if _, err := tx.Exec(queryInsertEmail, id, contacts.Email); err != nil {
+			return domain.Contacts{}, err
+		}
+	}
+
+	for _, phone := range contacts.Phones {
+		if _, err := tx.Exec(queryInsertPhone, id, phone.Type, phone.Number); err != nil {
+			return domain.Contacts{}, err
+		}
+	}
+
+	if address := contacts.Address; address != nil {
+		if _, err := tx.Exec(
+			queryInsertAddress,
+			id,
+			address.Street,
+			address.Number,
+			nullableString(address.Complement),
+			address.District,
+			address.City,
+			address.State,
+			address.CEP); err != nil {
+			return domain.Contacts{}, err
+		}
+	}
+
+	if emergency := contacts.EmergencyContact; emergency != nil {
+		if _, err := tx.Exec(queryInsertEmergency, id, emergency.Name, nullableString(emergency.Relationship), emergency.Phone); err != nil {
+			return domain.Contacts{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Contacts{}, err
+	}
+
+	return s.ReadContacts(id)
+}
+
+func (s *sqlStorePatient) CreatePhone(id int, phone domain.Phone) (domain.Phone, error) {
+	queryInsert := "INSERT INTO patient_phone (patient_id, type, number) VALUES (?, ?, ?)"
+
+	if _, err := s.ReadById(id); err != nil {
+		return domain.Phone{}, err
+	}
+
+	res, err := s.db.Exec(queryInsert, id, phone.Type, phone.Number)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.Phone{}, err
+	}
+
+	phone.Id = int(lastId)
+
+	return phone, nil
+}
+
+func (s *sqlStorePatient) UpdatePhone(id int, phone domain.Phone) (domain.Phone, error) {
+	queryUpdate := "UPDATE patient_phone SET type = ?, number = ? WHERE id = ? AND patient_id = ?"
+
+	phones, err := s.readPhones("SELECT id, type, number FROM patient_phone WHERE id = ? AND patient_id = ?", phone.Id, id)
+	if err != nil {
+		return domain.Phone{}, err
+	}
+	if len(phones) == 0 {
+		return domain.Phone{}, errors.New("phone not found")
+	}
+
+	if _, err := s.db.Exec(queryUpdate, phone.Type, phone.Number, phone.Id, id); err != nil {
+		return domain.Phone{}, err
+	}
+
+	return phone, nil
+}
+
+func (s *sqlStorePatient) DeletePhone(id int, idPhone int) error {
+	queryDelete := "DELETE FROM patient_phone WHERE id = ? AND patient_id = ?"
+
+	result, err := s.db.Exec(queryDelete, idPhone, id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("phone not found")
+	}
+
+	return nil
+}
+
+func (s *sqlStorePatient) readPhones(query string, args ...interface{}) ([]domain.Phone, error) {
+	phones := []domain.Phone{}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []domain.Phone{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var phone domain.Phone
+
+		if err := rows.Scan(
+			&phone.Id,
+			&phone.Type,
+			&phone.Number,
+		); err != nil {
+			return phones, err
+		}
+		phones = append(phones, phone)
+	}
+
+	return phones, rows.Err()
+}
+
+// setBirthDate fills in the birth date read from the database and the age it
+// gives today.
+func setBirthDate(patient *domain.Patient, birthDate sql.NullTime) {
+	patient.BirthDate = ""
+	if birthDate.Valid {
+		patient.BirthDate = birthDate.Time.Format(domain.BirthDateLayout)
+	}
+	setAge(patient)
+}
+
+func setAge(patient *domain.Patient) {
+	patient.Age = nil
+	birth, err := time.Parse(domain.BirthDateLayout, patient.BirthDate)
+	if err != nil {
+		return
+	}
+	age := domain.Age(birth, time.Now())
+	patient.Age = &age
+}
+
+// ReadGuardians lists the guardians of a patient. Guardians that are patients
+// themselves are named after their record.
+func (s *sqlStorePatient) ReadGuardians(id int) ([]domain.Guardian, error) {
+	queryGetGuardians := `SELECT g.id, COALESCE(g.guardian_patient_id, 0), COALESCE(CONCAT(gp.name, ' ', gp.surname), g.name, ''),
+						g.relationship, COALESCE(g.phone, ''), COALESCE(g.email, '')
+						FROM patient_guardian g
+						LEFT JOIN patient gp ON gp.id = g.guardian_patient_id
+						WHERE g.patient_id = ? ORDER BY g.id`
+
+	guardians := []domain.Guardian{}
+	rows, err := s.db.Query(queryGetGuardians, id)
+	if err != nil {
+		return []domain.Guardian{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var guardian domain.Guardian
+
+		if err := rows.Scan(
+			&guardian.Id,
+			&guardian.PatientId,
+			&guardian.Name,
+			&guardian.Relationship,
+			&guardian.Phone,
+			&guardian.Email,
+		); err != nil {
+			return guardians, err
+		}
+		guardians = append(guardians, guardian)
+	}
+
+	return guardians, rows.Err()
+}
+
+// ReadWards lists the ids of the patients whose guardian is the patient id.
+func (s *sqlStorePatient) ReadWards(id int) ([]int, error) {
+	queryGetWards := "SELECT patient_id FROM patient_guardian WHERE guardian_patient_id = ? ORDER BY patient_id"
+
+	wards := []int{}
+	rows, err := s.db.Query(queryGetWards, id)
+	if err != nil {
+		return []int{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var ward int
+		if err := rows.Scan(&ward); err != nil {
+			return wards, err
+		}
+		wards = append(wards, ward)
+	}
+
+	return wards, rows.Err()
+}
+
+func (s *sqlStorePatient) CreateGuardian(id int, guardian domain.Guardian) (domain.Guardian, error) {
+	queryInsert := "INSERT INTO patient_guardian (patient_id, guardian_patient_id, name, relationship, phone, email) VALUES (?, ?, ?, ?, ?, ?)"
+
+	if _, err := s.ReadById(id); err != nil {
+		return domain.Guardian{}, err
+	}
+
+	res, err := s.db.Exec(
+		queryInsert,
+		id,
+		nullableId(guardian.PatientId),
+		nullableString(guardian.Name),
+		guardian.Relationship,
+		nullableString(guardian.Phone),
+		nullableString(guardian.Email),
+	)
+	if err != nil {
+		return domain.Guardian{}, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.Guardian{}, err
+	}
+	guardian.Id = int(lastId)
+
+	return guardian, nil
+}
+
+func (s *sqlStorePatient) DeleteGuardian(id int, idGuardian int) error {
+	queryDelete := "DELETE FROM patient_guardian WHERE id = ? AND patient_id = ?"
+
+	result, err := s.db.Exec(queryDelete, idGuardian, id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("guardian not found")
+	}
+
+	return nil
+}
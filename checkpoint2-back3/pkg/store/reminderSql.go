@@ -0,0 +1,164 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type sqlStoreReminder struct {
+	db *sql.DB
+}
+
+func NewSQLStoreReminder(db *sql.DB) ReminderStoreInterface {
+	return &sqlStoreReminder{
+		db: db,
+	}
+}
+
+const selectReminder = `SELECT id, appointment_id, appointment_start, offset_minutes, channel, token, status, COALESCE(error, ''), created_at, claimed_at, sent_at 
+					FROM appointment_reminder `
+
+// Claim saves reminder unless one already exists for the same appointment,
+// start and offset, in which case it returns false. Claiming before sending is what
+// keeps a reminder from going out twice, even across restarts.
+func (s *sqlStoreReminder) Claim(reminder domain.Reminder) (domain.Reminder, bool, error) {
+	queryInsert := `INSERT IGNORE INTO appointment_reminder (appointment_id, appointment_start, offset_minutes, channel, token, status, created_at, claimed_at) 
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := s.db.Exec(
+		queryInsert,
+		reminder.AppointmentId,
+		reminder.Start.UTC(),
+		int(reminder.Offset/time.Minute),
+		reminder.Channel,
+		reminder.Token,
+		reminder.Status,
+		reminder.CreatedAt.UTC(),
+		reminder.ClaimedAt.UTC(),
+	)
+	if err != nil {
+		return domain.Reminder{}, false, err
+	}
+
+	RowsAffected, _ := res.RowsAffected()
+	if RowsAffected == 0 {
+		return domain.Reminder{}, false, nil
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.Reminder{}, false, err
+	}
+	reminder.Id = int(lastId)
+	return reminder, true, nil
+}
+
+// Reclaim takes over the reminder claimed for the same appointment, start and
+// offset when it is still sending and was claimed before claimedBefore, as
+// when the process sending it stopped halfway. The reminder keeps its token,
+// in case its message did go out. Only one of several concurrent callers gets
+// true.
+func (s *sqlStoreReminder) Reclaim(reminder domain.Reminder, claimedBefore time.Time) (domain.Reminder, bool, error) {
+	queryUpdate := `UPDATE appointment_reminder SET channel = ?, claimed_at = ? 
+					WHERE appointment_id = ? AND appointment_start = ? AND offset_minutes = ? AND status = ? AND claimed_at < ?`
+
+	res, err := s.db.Exec(
+		queryUpdate,
+		reminder.Channel,
+		reminder.ClaimedAt.UTC(),
+		reminder.AppointmentId,
+		reminder.Start.UTC(),
+		int(reminder.Offset/time.Minute),
+		domain.ReminderSending,
+		claimedBefore.UTC(),
+	)
+	if err != nil {
+		return domain.Reminder{}, false, err
+	}
+
+	RowsAffected, _ := res.RowsAffected()
+	if RowsAffected == 0 {
+		return domain.Reminder{}, false, nil
+	}
+
+	reminders, err := s.readReminders(selectReminder+"WHERE appointment_id = ? AND appointment_start = ? AND offset_minutes = ?",
+		reminder.AppointmentId, reminder.Start.UTC(), int(reminder.Offset/time.Minute))
+	if err != nil {
+		return domain.Reminder{}, false, err
+	}
+	if len(reminders) == 0 {
+		return domain.Reminder{}, false, errors.New("reminder not found")
+	}
+	return reminders[0], true, nil
+}
+
+// UpdateStatus records how sending the reminder ended. sentAt is nil unless
+// it went out.
+func (s *sqlStoreReminder) UpdateStatus(id int, status string, sendError string, sentAt *time.Time) error {
+	queryUpdate := "UPDATE appointment_reminder SET status = ?, error = ?, sent_at = ? WHERE id = ?"
+
+	var sent interface{}
+	if sentAt != nil {
+		sent = sentAt.UTC()
+	}
+	_, err := s.db.Exec(queryUpdate, status, nullableString(sendError), sent, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *sqlStoreReminder) ReadByToken(token string) (domain.Reminder, error) {
+	reminders, err := s.readReminders(selectReminder+"WHERE token = ?", token)
+	if err != nil {
+		return domain.Reminder{}, err
+	}
+	if len(reminders) == 0 {
+		return domain.Reminder{}, errors.New("reminder not found")
+	}
+	return reminders[0], nil
+}
+
+func (s *sqlStoreReminder) ReadByAppointment(idAppointment int) ([]domain.Reminder, error) {
+	return s.readReminders(selectReminder+"WHERE appointment_id = ? ORDER BY created_at, id", idAppointment)
+}
+
+func (s *sqlStoreReminder) readReminders(query string, args ...interface{}) ([]domain.Reminder, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []domain.Reminder{}, err
+	}
+	defer rows.Close()
+
+	reminders := []domain.Reminder{}
+	for rows.Next() {
+		var reminder domain.Reminder
+		var offsetMinutes int
+		var sentAt sql.NullTime
+
+		if err := rows.Scan(
+			&reminder.Id,
+			&reminder.AppointmentId,
+			&reminder.Start,
+			&offsetMinutes,
+			&reminder.Channel,
+			&reminder.Token,
+			&reminder.Status,
+			&reminder.Error,
+			&reminder.CreatedAt,
+			&reminder.ClaimedAt,
+			&sentAt,
+		); err != nil {
+			return reminders, err
+		}
+
+		reminder.Offset = time.Duration(offsetMinutes) * time.Minute
+		if sentAt.Valid {
+			reminder.SentAt = &sentAt.Time
+		}
+		reminders = append(reminders, reminder)
+	}
+	return reminders, rows.Err()
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"database/sql"
+	"strings"
+)
+
+type sqlStoreRoom struct {
+	db *sql.DB
+}
+
+func NewSQLStoreRoom(db *sql.DB) RoomStoreInterface {
+	return &sqlStoreRoom{
+		db: db,
+	}
+}
+
+const selectRoom = "SELECT id, name, equipment, COALESCE(clinic_id, 0) FROM room "
+
+func (s *sqlStoreRoom) ReadById(id int) (domain.Room, error) {
+	rooms, err := s.readRooms(selectRoom+"WHERE id = ?", id)
+	if err != nil {
+		return domain.Room{}, err
+	}
+	if len(rooms) == 0 {
+		return domain.Room{}, &NotFoundError{Entity: "room"}
+	}
+	return rooms[0], nil
+}
+
+func (s *sqlStoreRoom) ReadAll() ([]domain.Room, error) {
+	return s.readRooms(selectRoom + "ORDER BY name, id")
+}
+
+func (s *sqlStoreRoom) ReadByClinic(idClinic int) ([]domain.Room, error) {
+	return s.readRooms(selectRoom+"WHERE clinic_id = ? ORDER BY name, id", idClinic)
+}
+
+func (s *sqlStoreRoom) Create(room domain.Room) (domain.Room, error) {
+	queryInsert := "INSERT INTO room (name, equipment, clinic_id) VALUES (?, ?, ?)"
+
+	res, err := s.db.Exec(queryInsert, room.Name, joinEquipment(room.Equipment), nullableId(room.ClinicId))
+	if err != nil {
+		return domain.Room{}, conflict(err)
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.Room{}, err
+	}
+
+	return s.ReadById(int(lastId))
+}
+
+func (s *sqlStoreRoom) Update(id int, room domain.Room) (domain.Room, error) {
+	queryUpdate := "UPDATE room SET name = ?, equipment = ?, clinic_id = ? WHERE id = ?"
+
+	if _, err := s.ReadById(id); err != nil {
+		return domain.Room{}, err
+	}
+
+	if _, err := s.db.Exec(queryUpdate, room.Name, joinEquipment(room.Equipment), nullableId(room.ClinicId), id); err != nil {
+		return domain.Room{}, conflict(err)
+	}
+
+	return s.ReadById(id)
+}
+
+func (s *sqlStoreRoom) Patch(id int, r domain.Room) (domain.Room, error) {
+	queryUpdate := "UPDATE room SET name = ?, equipment = ?, clinic_id = ? WHERE id = ?"
+
+	room, err := s.ReadById(id)
+	if err != nil {
+		return domain.Room{}, err
+	}
+
+	if r.Name != "" {
+		room.Name = r.Name
+	}
+	if r.Equipment != nil {
+		room.Equipment = r.Equipment
+	}
+	if r.ClinicId != 0 {
+		room.ClinicId = r.ClinicId
+	}
+
+	if _, err := s.db.Exec(queryUpdate, room.Name, joinEquipment(room.Equipment), nullableId(room.ClinicId), id); err != nil {
+		return domain.Room{}, conflict(err)
+	}
+
+	return s.ReadById(id)
+}
+
+func (s *sqlStoreRoom) Delete(id int) error {
+	queryDelete := "DELETE FROM room WHERE id = ?"
+
+	result, err := s.db.Exec(queryDelete, id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return &NotFoundError{Entity: "room"}
+	}
+
+	return nil
+}
+
+func (s *sqlStoreRoom) readRooms(query string, args ...interface{}) ([]domain.Room, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []domain.Room{}, err
+	}
+	defer rows.Close()
+
+	rooms := []domain.Room{}
+	for rows.Next() {
+		var room domain.Room
+		var equipment string
+
+		if err := rows.Scan(&room.Id, &room.Name, &equipment, &room.ClinicId); err != nil {
+			return rooms, err
+		}
+
+		room.Equipment = splitEquipment(equipment)
+		rooms = append(rooms, room)
+	}
+	return rooms, rows.Err()
+}
+
+// Equipment is kept as a comma separated list, which is enough for the few
+// items a room is fitted with.
+func joinEquipment(equipment []string) string {
+	return strings.Join(equipment, ",")
+}
+
+func splitEquipment(equipment string) []string {
+	if equipment == "" {
+		return []string{}
+	}
+	return strings.Split(equipment, ",")
+}
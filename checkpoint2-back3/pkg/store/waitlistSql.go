@@ -0,0 +1,324 @@
+package store
+
+import (
+	"checkpoint2/internal/domain"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type sqlStoreWaitlist struct {
+	db *sql.DB
+}
+
+func NewSQLStoreWaitlist(db *sql.DB) WaitlistStoreInterface {
+	return &sqlStoreWaitlist{
+		db: db,
+	}
+}
+
+const selectWaitlistEntry = `SELECT waitlist_entry.id, patient.id, patient.surname, patient.name, patient.rg, patient.registration_date, 
+					COALESCE(waitlist_entry.dentist_id, 0), COALESCE(waitlist_entry.clinic_id, 0), waitlist_entry.from_date, waitlist_entry.to_date, 
+					COALESCE(TIME_FORMAT(waitlist_entry.earliest_time, '%H:%i'), ''), 
+					COALESCE(TIME_FORMAT(waitlist_entry.latest_time, '%H:%i'), ''), 
+					waitlist_entry.status, waitlist_entry.created_at 
+					FROM waitlist_entry 
+					INNER JOIN patient 
+					ON patient.id = waitlist_entry.patient_id `
+
+const selectSlotHold = `SELECT id, entry_id, dentist_id, COALESCE(clinic_id, 0), start_time, end_time, expires_at, status 
+					FROM waitlist_hold `
+
+func (s *sqlStoreWaitlist) ReadById(id int) (domain.WaitlistEntry, error) {
+	queryGetHolds := selectSlotHold + "WHERE entry_id = ? ORDER BY expires_at"
+
+	entries, err := s.readEntries(selectWaitlistEntry+"WHERE waitlist_entry.id = ?", id)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+	if len(entries) == 0 {
+		return domain.WaitlistEntry{}, errors.New("waitlist entry not found")
+	}
+
+	entry := entries[0]
+	entry.Holds, err = s.readHolds(queryGetHolds, id)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (s *sqlStoreWaitlist) ReadAll(idDentist int, idClinic int) ([]domain.WaitlistEntry, error) {
+	queryGetAll := selectWaitlistEntry + `WHERE waitlist_entry.status IN ('waiting', 'offered') 
+					AND (? = 0 OR waitlist_entry.dentist_id = ? OR waitlist_entry.dentist_id IS NULL) 
+					AND (? = 0 OR waitlist_entry.clinic_id = ? OR waitlist_entry.clinic_id IS NULL) 
+					ORDER BY waitlist_entry.created_at, waitlist_entry.id`
+
+	return s.readEntries(queryGetAll, idDentist, idDentist, idClinic, idClinic)
+}
+
+func (s *sqlStoreWaitlist) ReadCandidates(idDentist int, idClinic int, start time.Time, end time.Time) ([]domain.WaitlistEntry, error) {
+	queryGetCandidates := selectWaitlistEntry + `WHERE waitlist_entry.status = 'waiting' 
+					AND (waitlist_entry.dentist_id = ? OR waitlist_entry.dentist_id IS NULL) 
+					AND (? = 0 OR waitlist_entry.clinic_id = ? OR waitlist_entry.clinic_id IS NULL) 
+					AND waitlist_entry.from_date <= ? AND waitlist_entry.to_date >= ? 
+					ORDER BY waitlist_entry.created_at, waitlist_entry.id`
+
+	return s.readEntries(queryGetCandidates, idDentist, idClinic, idClinic, start.Format("2006-01-02"), end.Format("2006-01-02"))
+}
+
+func (s *sqlStoreWaitlist) Create(entry domain.WaitlistEntry) (domain.WaitlistEntry, error) {
+	queryInsert := `INSERT INTO waitlist_entry (patient_id, dentist_id, clinic_id, from_date, to_date, earliest_time, latest_time, status, created_at) 
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := s.db.Exec(
+		queryInsert,
+		entry.Patient.Id,
+		nullableId(entry.DentistId),
+		nullableId(entry.ClinicId),
+		entry.From.Format("2006-01-02"),
+		entry.To.Format("2006-01-02"),
+		nullableString(entry.EarliestTime),
+		nullableString(entry.LatestTime),
+		domain.WaitlistWaiting,
+		time.Now().UTC())
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+
+	return s.ReadById(int(lastId))
+}
+
+func (s *sqlStoreWaitlist) UpdateStatus(id int, status string) error {
+	queryUpdate := "UPDATE waitlist_entry SET status = ? WHERE id = ?"
+
+	result, err := s.db.Exec(queryUpdate, status, id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("waitlist entry not found")
+	}
+
+	return nil
+}
+
+func (s *sqlStoreWaitlist) ReadHold(id int) (domain.SlotHold, error) {
+	holds, err := s.readHolds(selectSlotHold+"WHERE id = ?", id)
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+	if len(holds) == 0 {
+		return domain.SlotHold{}, errors.New("hold not found")
+	}
+	return holds[0], nil
+}
+
+func (s *sqlStoreWaitlist) ReadHoldsBySlot(idDentist int, start time.Time) ([]domain.SlotHold, error) {
+	return s.readHolds(selectSlotHold+"WHERE dentist_id = ? AND start_time = ?", idDentist, start.UTC())
+}
+
+func (s *sqlStoreWaitlist) ReadExpiredHolds(now time.Time) ([]domain.SlotHold, error) {
+	return s.readHolds(selectSlotHold+"WHERE status = 'pending' AND expires_at <= ?", now.UTC())
+}
+
+// Offer saves hold and marks its entry as offered in the same transaction. It
+// fails when the entry stopped waiting meanwhile.
+func (s *sqlStoreWaitlist) Offer(hold domain.SlotHold) (domain.SlotHold, error) {
+	queryInsert := `INSERT INTO waitlist_hold (entry_id, dentist_id, clinic_id, start_time, end_time, expires_at, status) 
+					VALUES (?, ?, ?, ?, ?, ?, ?)`
+	queryEntry := "UPDATE waitlist_entry SET status = ? WHERE id = ? AND status = ?"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		queryInsert,
+		hold.EntryId,
+		hold.DentistId,
+		nullableId(hold.ClinicId),
+		hold.Start.UTC(),
+		hold.End.UTC(),
+		hold.ExpiresAt.UTC(),
+		hold.Status)
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+
+	result, err := tx.Exec(queryEntry, domain.WaitlistOffered, hold.EntryId, domain.WaitlistWaiting)
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return domain.SlotHold{}, err
+	}
+	if affectedRows == 0 {
+		return domain.SlotHold{}, errors.New("waitlist entry is no longer waiting")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.SlotHold{}, err
+	}
+	hold.Id = int(lastId)
+	return hold, nil
+}
+
+func (s *sqlStoreWaitlist) UpdateHoldStatus(id int, status string) error {
+	queryUpdate := "UPDATE waitlist_hold SET status = ? WHERE id = ?"
+
+	result, err := s.db.Exec(queryUpdate, status, id)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affectedRows == 0 {
+		return errors.New("hold not found")
+	}
+
+	return nil
+}
+
+// Claim books appointment for the hold's entry, closing the hold and the
+// entry in the same transaction. It fails when the hold was claimed, passed
+// on or expired meanwhile. It returns the id of the new appointment.
+func (s *sqlStoreWaitlist) Claim(hold domain.SlotHold, appointment domain.Appointment) (int, error) {
+	queryHold := "UPDATE waitlist_hold SET status = ? WHERE id = ? AND status = ? AND expires_at > ?"
+	queryInsert := "INSERT INTO appointment (patient_id, dentist_id, start_time, end_time, description, room_id, clinic_id, type_id, override_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	queryEntry := "UPDATE waitlist_entry SET status = ? WHERE id = ?"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(queryHold, domain.HoldClaimed, hold.Id, domain.HoldPending, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affectedRows == 0 {
+		return 0, errors.New("hold is no longer available")
+	}
+
+	res, err := tx.Exec(
+		queryInsert,
+		appointment.Patient.Id,
+		appointment.Dentist.Id,
+		appointment.Start.UTC(),
+		appointment.End.UTC(),
+		appointment.Description,
+		nullableId(appointment.RoomId),
+		nullableId(appointment.ClinicId),
+		nullableId(appointment.TypeId),
+		nullableString(appointment.OverrideBy))
+	if err != nil {
+		return 0, err
+	}
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(queryEntry, domain.WaitlistBooked, hold.EntryId); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(lastId), nil
+}
+
+func (s *sqlStoreWaitlist) readEntries(query string, args ...interface{}) ([]domain.WaitlistEntry, error) {
+	var entries []domain.WaitlistEntry
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []domain.WaitlistEntry{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry domain.WaitlistEntry
+
+		if err := rows.Scan(
+			&entry.Id,
+			&entry.Patient.Id,
+			&entry.Patient.Surname,
+			&entry.Patient.Name,
+			&entry.Patient.RG,
+			&entry.Patient.RegistrationDate,
+			&entry.DentistId,
+			&entry.ClinicId,
+			&entry.From,
+			&entry.To,
+			&entry.EarliestTime,
+			&entry.LatestTime,
+			&entry.Status,
+			&entry.CreatedAt,
+		); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (s *sqlStoreWaitlist) readHolds(query string, args ...interface{}) ([]domain.SlotHold, error) {
+	var holds []domain.SlotHold
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return []domain.SlotHold{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var hold domain.SlotHold
+
+		if err := rows.Scan(
+			&hold.Id,
+			&hold.EntryId,
+			&hold.DentistId,
+			&hold.ClinicId,
+			&hold.Start,
+			&hold.End,
+			&hold.ExpiresAt,
+			&hold.Status,
+		); err != nil {
+			return holds, err
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
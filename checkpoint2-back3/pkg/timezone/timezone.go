@@ -0,0 +1,69 @@
+// Package timezone loads IANA time zones and places local times, given
+// without an offset, in the zone they refer to.
+package timezone
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	// The zone database is embedded so that historical rules, such as
+	// Brazil's former daylight saving time, don't depend on the host.
+	_ "time/tzdata"
+)
+
+// Default is the zone of clinics and dentists that don't set their own.
+const Default = "America/Sao_Paulo"
+
+// Floating is the location of times parsed without an offset. Their clock
+// is only meaningful once Localize places it in a real zone.
+var Floating = time.FixedZone("floating", 0)
+
+var locations sync.Map
+
+// Load returns the location named name, or the Default one when name is
+// empty.
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		name = Default
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// Validate reports whether name is a known IANA zone. Empty is valid and
+// stands for Default.
+func Validate(name string) error {
+	_, err := Load(name)
+	return err
+}
+
+// Localize returns t in loc. A floating t keeps its clock, which is read as
+// a wall time in loc; any other t keeps its instant. Times that fall in a
+// DST gap are resolved as time.Date does.
+func Localize(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	if t.Location() == Floating {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+	return t.In(loc)
+}
+
+// Parse parses value with layout. Layouts without an offset give floating
+// times, to be placed with Localize once their zone is known.
+func Parse(layout string, value string) (time.Time, error) {
+	if strings.Contains(layout, "Z07") || strings.Contains(layout, "-07") || strings.Contains(layout, "MST") {
+		return time.Parse(layout, value)
+	}
+	return time.ParseInLocation(layout, value, Floating)
+}
@@ -0,0 +1,121 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"", Default, false},
+		{"America/Manaus", "America/Manaus", false},
+		{"Europe/Lisbon", "Europe/Lisbon", false},
+		{"Local", "", true},
+		{"America/Nowhere", "", true},
+	}
+	for _, tt := range tests {
+		loc, err := Load(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Load(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && loc.String() != tt.want {
+			t.Errorf("Load(%q) = %s, want %s", tt.name, loc, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		layout   string
+		value    string
+		floating bool
+		want     string
+	}{
+		{"2006-01-02T15:04", "2024-05-10T09:00", true, "2024-05-10T09:00:00Z"},
+		{"2006-01-02 15:04", "2024-05-10 09:00", true, "2024-05-10T09:00:00Z"},
+		{time.RFC3339, "2024-05-10T09:00:00-03:00", false, "2024-05-10T12:00:00Z"},
+		{time.RFC3339, "2024-05-10T09:00:00Z", false, "2024-05-10T09:00:00Z"},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.layout, tt.value)
+		if err != nil {
+			t.Errorf("Parse(%q, %q): %v", tt.layout, tt.value, err)
+			continue
+		}
+		if (got.Location() == Floating) != tt.floating {
+			t.Errorf("Parse(%q, %q) in %s, want floating %v", tt.layout, tt.value, got.Location(), tt.floating)
+		}
+		if instant := got.UTC().Format(time.RFC3339); instant != tt.want {
+			t.Errorf("Parse(%q, %q) = %s, want %s", tt.layout, tt.value, instant, tt.want)
+		}
+	}
+}
+
+// TestLocalizeAcrossDST books appointments whose start and end sit on both
+// sides of a daylight saving change: the wall times are kept, so the
+// appointment lasts an hour less or more than its clock suggests.
+func TestLocalizeAcrossDST(t *testing.T) {
+	tests := []struct {
+		name       string
+		zone       string
+		start, end string
+		wantStart  string
+		wantEnd    string
+		wantLength time.Duration
+	}{
+		{"no change", "America/Sao_Paulo", "2024-11-03T09:00", "2024-11-03T10:00", "2024-11-03T12:00:00Z", "2024-11-03T13:00:00Z", time.Hour},
+		{"sao paulo 2018 spring forward", "America/Sao_Paulo", "2018-11-03T23:30", "2018-11-04T01:30", "2018-11-04T02:30:00Z", "2018-11-04T03:30:00Z", time.Hour},
+		{"sao paulo 2019 fall back", "America/Sao_Paulo", "2019-02-16T22:30", "2019-02-17T00:30", "2019-02-17T00:30:00Z", "2019-02-17T03:30:00Z", 3 * time.Hour},
+		{"new york spring forward", "America/New_York", "2024-03-10T01:30", "2024-03-10T03:30", "2024-03-10T06:30:00Z", "2024-03-10T07:30:00Z", time.Hour},
+		{"new york fall back", "America/New_York", "2024-11-03T00:30", "2024-11-03T03:30", "2024-11-03T04:30:00Z", "2024-11-03T08:30:00Z", 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := Load(tt.zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start, err := Parse("2006-01-02T15:04", tt.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			end, err := Parse("2006-01-02T15:04", tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start, end = Localize(start, loc), Localize(end, loc)
+			if got := start.UTC().Format(time.RFC3339); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+			if got := end.UTC().Format(time.RFC3339); got != tt.wantEnd {
+				t.Errorf("end = %s, want %s", got, tt.wantEnd)
+			}
+			if got := end.Sub(start); got != tt.wantLength {
+				t.Errorf("length = %s, want %s", got, tt.wantLength)
+			}
+			if start.Location() != loc || end.Location() != loc {
+				t.Errorf("localized in %s and %s, want %s", start.Location(), end.Location(), loc)
+			}
+		})
+	}
+}
+
+func TestLocalizeKeepsInstants(t *testing.T) {
+	loc, err := Load("America/Manaus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instant := time.Date(2024, time.May, 10, 12, 0, 0, 0, time.UTC)
+	if got := Localize(instant, loc); !got.Equal(instant) || got.Location() != loc {
+		t.Errorf("Localize(%s) = %s, want the same instant in %s", instant, got, loc)
+	}
+	if got := Localize(time.Time{}, loc); !got.IsZero() {
+		t.Errorf("Localize(zero) = %s, want zero", got)
+	}
+}
@@ -14,6 +14,16 @@ type errorResponse struct {
 
 type response struct {
 	Data interface{} `json:"data"`
+	Meta *Meta       `json:"meta,omitempty"`
+}
+
+// Meta describes the page of a listing sent in data. NextCursor, when set,
+// fetches the page after it.
+type Meta struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func Success(ctx *gin.Context, status int, data interface{}) {
@@ -22,10 +32,17 @@ func Success(ctx *gin.Context, status int, data interface{}) {
 	})
 }
 
+func SuccessWithMeta(ctx *gin.Context, status int, data interface{}, meta Meta) {
+	ctx.JSON(status, response{
+		Data: data,
+		Meta: &meta,
+	})
+}
+
 func Failure(ctx *gin.Context, status int, err error) {
 	ctx.JSON(status, errorResponse{
 		Message: err.Error(),
 		Status:  status,
 		Code:    http.StatusText(status),
 	})
-}
\ No newline at end of file
+}